@@ -0,0 +1,353 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package ast provides Go types for zsx nodes, as an alternative to the
+// positional Get functions of package zsx.
+//
+// [FromSx] converts a zsx tree into a tree of typed nodes, the method Sx of
+// each node converts it back. Both conversions are lossless for all trees
+// built by the parsers and by the Make functions of package zsx. Attribute
+// lists are kept as they are, because they may contain symbol and string keys
+// in any order; use [zsx.GetAttributes] to work with them.
+package ast
+
+import (
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Node is a typed zsx node.
+type Node interface {
+	// Sx returns the sx representation of the node.
+	Sx() *sx.Pair
+}
+
+// Reference is the target of a link, an embedding, or a transclusion.
+type Reference struct {
+	State *sx.Symbol // One of zsx.SymRefState*, or an application specific state
+	Value string
+}
+
+// Sx returns the sx representation of the reference.
+func (ref Reference) Sx() *sx.Pair { return zsx.MakeReference(ref.State, ref.Value) }
+
+// Block is a BLOCK node.
+type Block struct {
+	Blocks []Node
+}
+
+// Inline is an INLINE node.
+type Inline struct {
+	Inlines []Node
+}
+
+// Para is a PARA node.
+type Para struct {
+	Inlines []Node
+}
+
+// Heading is a HEADING node.
+type Heading struct {
+	Attrs   *sx.Pair
+	Level   int
+	Inlines []Node
+}
+
+// Thematic is a THEMATIC node.
+type Thematic struct {
+	Attrs *sx.Pair
+}
+
+// List is an ORDERED, UNORDERED, or QUOTATION node.
+type List struct {
+	Kind  *sx.Symbol // zsx.SymListOrdered, zsx.SymListUnordered, or zsx.SymListQuote
+	Attrs *sx.Pair
+	Items []Node
+}
+
+// Item is an ITEM node of a list.
+type Item struct {
+	Attrs  *sx.Pair
+	Blocks []Node
+}
+
+// Description is a DESCRIPTION node. Its elements are terms and details.
+type Description struct {
+	Attrs    *sx.Pair
+	Elements []Node
+}
+
+// Term is a TERM node of a description list.
+type Term struct {
+	Attrs   *sx.Pair
+	Inlines []Node
+}
+
+// Detail is a DETAIL node of a description list.
+type Detail struct {
+	Entries []Node
+}
+
+// Entry is an ENTRY node of a detail.
+type Entry struct {
+	Attrs  *sx.Pair
+	Blocks []Node
+}
+
+// Table is a TABLE node.
+type Table struct {
+	Attrs  *sx.Pair
+	Header Node // nil, if the table has no header row
+	Rows   []Node
+}
+
+// Row is a ROW node of a table.
+type Row struct {
+	Attrs *sx.Pair
+	Cells []Node
+}
+
+// Cell is a CELL node of a row.
+type Cell struct {
+	Attrs   *sx.Pair
+	Inlines []Node
+}
+
+// Region is a REGION-BLOCK, REGION-QUOTE, or REGION-VERSE node.
+type Region struct {
+	Kind    *sx.Symbol
+	Attrs   *sx.Pair
+	Blocks  []Node
+	Inlines []Node
+}
+
+// Verbatim is one of the VERBATIM-* nodes.
+type Verbatim struct {
+	Kind    *sx.Symbol
+	Attrs   *sx.Pair
+	Content string
+}
+
+// Transclusion is a TRANSCLUDE node.
+type Transclusion struct {
+	Attrs   *sx.Pair
+	Ref     Reference
+	Inlines []Node
+}
+
+// BLOB is a BLOB node. Its data is kept encoded, as with
+// [zsx.GetBLOBuncode].
+type BLOB struct {
+	Attrs   *sx.Pair
+	Syntax  string
+	Data    string
+	Inlines []Node
+}
+
+// Text is a TEXT node.
+type Text struct {
+	Text string
+}
+
+// Soft is a SOFT line break.
+type Soft struct{}
+
+// Hard is a HARD line break.
+type Hard struct{}
+
+// Link is a LINK node.
+type Link struct {
+	Attrs   *sx.Pair
+	Ref     Reference
+	Inlines []Node
+}
+
+// Embed is an EMBED node.
+type Embed struct {
+	Attrs   *sx.Pair
+	Ref     Reference
+	Syntax  string
+	Inlines []Node
+}
+
+// EmbedBLOB is an EMBED-BLOB node. Its data is kept encoded, as with
+// [zsx.GetEmbedBLOBuncode].
+type EmbedBLOB struct {
+	Attrs   *sx.Pair
+	Syntax  string
+	Data    string
+	Inlines []Node
+}
+
+// Cite is a CITE node.
+type Cite struct {
+	Attrs   *sx.Pair
+	Key     string
+	Inlines []Node
+}
+
+// Endnote is an ENDNOTE node.
+type Endnote struct {
+	Attrs   *sx.Pair
+	Inlines []Node
+}
+
+// Mark is a MARK node.
+type Mark struct {
+	Attrs   *sx.Pair
+	Mark    string
+	Inlines []Node
+}
+
+// Format is one of the FORMAT-* nodes.
+type Format struct {
+	Kind    *sx.Symbol
+	Attrs   *sx.Pair
+	Inlines []Node
+}
+
+// Literal is one of the LITERAL-* nodes.
+type Literal struct {
+	Kind    *sx.Symbol
+	Attrs   *sx.Pair
+	Content string
+}
+
+// Custom is a node that was registered with [zsx.RegisterLayout]. It is
+// kept in its sx representation, i.e. its child nodes are not converted.
+type Custom struct {
+	Node *sx.Pair
+}
+
+// Splice is a node, whose elements will be spliced into the enclosing list
+// when the tree is walked. See [zsx.SymSpecialSplice].
+type Splice struct {
+	Nodes []Node
+}
+
+// Sx returns the sx representation of the node.
+func (n *Block) Sx() *sx.Pair { return zsx.MakeBlockList(makeList(n.Blocks)) }
+
+// Sx returns the sx representation of the node.
+func (n *Inline) Sx() *sx.Pair { return zsx.MakeInlineList(makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Para) Sx() *sx.Pair { return zsx.MakeParaList(makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Heading) Sx() *sx.Pair { return zsx.MakeHeading(n.Attrs, n.Level, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Thematic) Sx() *sx.Pair { return zsx.MakeThematic(n.Attrs) }
+
+// Sx returns the sx representation of the node.
+func (n *List) Sx() *sx.Pair { return zsx.MakeList(n.Kind, n.Attrs, makeList(n.Items)) }
+
+// Sx returns the sx representation of the node.
+func (n *Item) Sx() *sx.Pair { return zsx.MakeListItem(n.Attrs, makeList(n.Blocks)) }
+
+// Sx returns the sx representation of the node.
+func (n *Description) Sx() *sx.Pair { return zsx.MakeDescription(n.Attrs, makeList(n.Elements)) }
+
+// Sx returns the sx representation of the node.
+func (n *Term) Sx() *sx.Pair { return zsx.MakeTerm(n.Attrs, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Detail) Sx() *sx.Pair { return zsx.MakeDetail(makeList(n.Entries)) }
+
+// Sx returns the sx representation of the node.
+func (n *Entry) Sx() *sx.Pair { return zsx.MakeEntry(n.Attrs, makeList(n.Blocks)) }
+
+// Sx returns the sx representation of the node.
+func (n *Table) Sx() *sx.Pair {
+	var header *sx.Pair
+	if n.Header != nil {
+		header = n.Header.Sx()
+	}
+	return zsx.MakeTable(n.Attrs, header, makeList(n.Rows))
+}
+
+// Sx returns the sx representation of the node.
+func (n *Row) Sx() *sx.Pair { return zsx.MakeRow(n.Attrs, makeList(n.Cells)) }
+
+// Sx returns the sx representation of the node.
+func (n *Cell) Sx() *sx.Pair { return zsx.MakeCell(n.Attrs, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Region) Sx() *sx.Pair {
+	return zsx.MakeRegion(n.Kind, n.Attrs, makeList(n.Blocks), makeList(n.Inlines))
+}
+
+// Sx returns the sx representation of the node.
+func (n *Verbatim) Sx() *sx.Pair { return zsx.MakeVerbatim(n.Kind, n.Attrs, n.Content) }
+
+// Sx returns the sx representation of the node.
+func (n *Transclusion) Sx() *sx.Pair {
+	return zsx.MakeTransclusion(n.Attrs, n.Ref.Sx(), makeList(n.Inlines))
+}
+
+// Sx returns the sx representation of the node.
+func (n *BLOB) Sx() *sx.Pair {
+	return zsx.MakeBLOBuncode(n.Attrs, n.Syntax, n.Data, makeList(n.Inlines))
+}
+
+// Sx returns the sx representation of the node.
+func (n *Text) Sx() *sx.Pair { return zsx.MakeText(n.Text) }
+
+// Sx returns the sx representation of the node.
+func (*Soft) Sx() *sx.Pair { return zsx.MakeSoft() }
+
+// Sx returns the sx representation of the node.
+func (*Hard) Sx() *sx.Pair { return zsx.MakeHard() }
+
+// Sx returns the sx representation of the node.
+func (n *Link) Sx() *sx.Pair { return zsx.MakeLink(n.Attrs, n.Ref.Sx(), makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Embed) Sx() *sx.Pair {
+	return zsx.MakeEmbed(n.Attrs, n.Ref.Sx(), n.Syntax, makeList(n.Inlines))
+}
+
+// Sx returns the sx representation of the node.
+func (n *EmbedBLOB) Sx() *sx.Pair {
+	return zsx.MakeEmbedBLOBuncode(n.Attrs, n.Syntax, n.Data, makeList(n.Inlines))
+}
+
+// Sx returns the sx representation of the node.
+func (n *Cite) Sx() *sx.Pair { return zsx.MakeCite(n.Attrs, n.Key, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Endnote) Sx() *sx.Pair { return zsx.MakeEndnote(n.Attrs, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Mark) Sx() *sx.Pair { return zsx.MakeMark(n.Attrs, n.Mark, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Format) Sx() *sx.Pair { return zsx.MakeFormat(n.Kind, n.Attrs, makeList(n.Inlines)) }
+
+// Sx returns the sx representation of the node.
+func (n *Literal) Sx() *sx.Pair { return zsx.MakeLiteral(n.Kind, n.Attrs, n.Content) }
+
+// Sx returns the sx representation of the node.
+func (n *Custom) Sx() *sx.Pair { return n.Node }
+
+// Sx returns the sx representation of the node.
+func (n *Splice) Sx() *sx.Pair { return makeList(n.Nodes).Cons(zsx.SymSpecialSplice) }
+
+func makeList(nodes []Node) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, n := range nodes {
+		lb.Add(n.Sx())
+	}
+	return lb.List()
+}
@@ -0,0 +1,139 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package ast_test
+
+import (
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/ast"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/markdown"
+	"t73f.de/r/zsx/zmk"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name   string
+		src    string
+		parser func(*input.Input) *sx.Pair
+	}{
+		{"para", "a\nb\\\nc\n\nd", zmk.ParseBlocks},
+		{"heading", "=== A {id=x}\n====== B\n\n---", zmk.ParseBlocks},
+		{"lists", "* a\n** b\n# c\n> d", zmk.ParseBlocks},
+		{"description", "; a\n: b\n: c\n; d", zmk.ParseBlocks},
+		{"table", "|=a|=b>\n|c|:d\n|e", zmk.ParseBlocks},
+		{"regions", "<<<\na\n<<< b\n\n\"\"\"\nc\n\"\"\"\n\n:::{.x}\nd\n:::", zmk.ParseBlocks},
+		{"verbatim", "```go\nx\n```\n\n$$$\nx\n$$$\n\n%%%\nc\n%%%\n\n@@@\nz\n@@@", zmk.ParseBlocks},
+		{"formats", "__a__{lang=de} **b** >>c>> ~~d~~ ##e## ,,f,, ^^g^^ \"\"h\"\" ::i::", zmk.ParseBlocks},
+		{"literals", "``a`` ''b'' ==c== $$d$$ %% e", zmk.ParseBlocks},
+		{"references", "[[a|https://t73f.de]] {{f|img.png}} {{{g}}}", zmk.ParseBlocks},
+		{"inlines", "[@key p. 7] [^a] [!m|b]", zmk.ParseBlocks},
+		{"markdown", "# A\n\n- a\n\n  b\n\n1. `c` <d>\n\n> q", markdown.ParseBlocks},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := tc.parser(input.NewInput([]byte(tc.src)))
+			typed, err := ast.FromSx(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp, got := node.String(), typed.Sx().String(); exp != got {
+				t.Errorf("\nexp: %s\ngot: %s", exp, got)
+			}
+		})
+	}
+}
+
+func TestRoundTripBuild(t *testing.T) {
+	t.Parallel()
+	attrs := sx.MakeList(sx.Cons(zsx.SymAttrAlign, zsx.AttrAlignRight), sx.Cons(sx.MakeString("a"), sx.MakeString("b")))
+	node := zsx.MakeBlock(
+		zsx.MakeBLOB(attrs, "png", []byte{0, 1, 2, 255}, sx.MakeList(zsx.MakeText("a"))),
+		zsx.MakeTable(nil, nil, sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(attrs, nil))))),
+		zsx.MakePara(
+			zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), nil),
+			zsx.MakeEmbed(nil, zsx.MakeReference(zsx.SymRefStateHosted, "x.png"), "", nil),
+			zsx.MakeInline(zsx.MakeSoft(), zsx.MakeHard()),
+		),
+		zsx.MakeTransclusion(nil, zsx.MakeReference(sx.MakeSymbol("QUERY"), "a"), nil),
+		sx.MakeList(zsx.SymSpecialSplice, zsx.MakeThematic(attrs)),
+	)
+	typed, err := ast.FromSx(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := node.String(), typed.Sx().String(); exp != got {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	t.Parallel()
+	node := zmk.ParseBlocks(input.NewInput([]byte("=== A [[b|https://t73f.de]]")))
+	typed, err := ast.FromSx(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heading := typed.(*ast.Block).Blocks[0].(*ast.Heading)
+	if heading.Level != 1 {
+		t.Errorf("level: exp=1, got=%d", heading.Level)
+	}
+	link := heading.Inlines[1].(*ast.Link)
+	if exp := (ast.Reference{State: zsx.SymRefStateExternal, Value: "https://t73f.de"}); link.Ref != exp {
+		t.Errorf("ref: exp=%v, got=%v", exp, link.Ref)
+	}
+
+	heading.Level = 2
+	link.Inlines = append(link.Inlines, &ast.Format{Kind: zsx.SymFormatEmph, Inlines: []ast.Node{&ast.Text{Text: "c"}}})
+	exp := `(BLOCK (HEADING () 2 (TEXT "A ") (LINK () (EXTERNAL "https://t73f.de") (TEXT "b") (FORMAT-EMPH () (TEXT "c")))))`
+	if got := typed.Sx().String(); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+}
+
+func TestCustom(t *testing.T) {
+	t.Parallel()
+	sym := sx.MakeSymbol("AST-CUSTOM")
+	if err := zsx.RegisterLayout(sym, zsx.Layout{Context: zsx.ContextInline}); err != nil {
+		t.Fatal(err)
+	}
+	node := zsx.MakePara(sx.MakeList(sym))
+	typed, err := ast.FromSx(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if custom, isCustom := typed.(*ast.Para).Inlines[0].(*ast.Custom); !isCustom || custom.Node.Car() != sym {
+		t.Errorf("custom node expected, but got %v", typed.(*ast.Para).Inlines[0])
+	}
+	if exp, got := node.String(), typed.Sx().String(); exp != got {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+}
+
+func TestFromSxError(t *testing.T) {
+	t.Parallel()
+	nodes := []*sx.Pair{
+		sx.MakeList(sx.MakeSymbol("UNKNOWN")),
+		zsx.MakePara(sx.MakeList(zsx.SymText)),
+		zsx.MakePara(zsx.MakePara()),
+	}
+	for _, node := range nodes {
+		if typed, err := ast.FromSx(node); err == nil {
+			t.Errorf("error expected for %v, but got %v", node, typed)
+		}
+	}
+}
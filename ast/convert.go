@@ -0,0 +1,239 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package ast
+
+import (
+	"fmt"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// FromSx converts a zsx tree into typed nodes. The tree must be valid, as
+// checked by [zsx.Validate]; otherwise an error describing the first
+// violation is returned.
+func FromSx(node *sx.Pair) (Node, error) {
+	if violations := zsx.Validate(node); len(violations) > 0 {
+		if len(violations) == 1 {
+			return nil, fmt.Errorf("invalid zsx tree: %v", violations[0])
+		}
+		return nil, fmt.Errorf("invalid zsx tree: %v (and %d more)", violations[0], len(violations)-1)
+	}
+	return fromSx(node), nil
+}
+
+// fromSx converts a valid node.
+func fromSx(node *sx.Pair) Node {
+	sym := zsx.NodeSymbol(node)
+	if fn, found := converters[sym]; found {
+		return fn(node)
+	}
+	if zsx.SymSpecialSplice.IsEqualSymbol(sym) {
+		return &Splice{Nodes: fromList(node.Tail())}
+	}
+	if _, found := zsx.GetLayout(sym); found {
+		return &Custom{Node: node}
+	}
+	panic(fmt.Sprintf("no converter for valid node %v", node))
+}
+
+var converters map[*sx.Symbol]func(*sx.Pair) Node
+
+func init() {
+	converters = map[*sx.Symbol]func(*sx.Pair) Node{
+		zsx.SymBlock:           convertBlock,
+		zsx.SymInline:          convertInline,
+		zsx.SymPara:            convertPara,
+		zsx.SymHeading:         convertHeading,
+		zsx.SymThematic:        convertThematic,
+		zsx.SymListOrdered:     convertList,
+		zsx.SymListUnordered:   convertList,
+		zsx.SymListQuote:       convertList,
+		zsx.SymListItem:        convertItem,
+		zsx.SymDescription:     convertDescription,
+		zsx.SymTerm:            convertTerm,
+		zsx.SymDetail:          convertDetail,
+		zsx.SymEntry:           convertEntry,
+		zsx.SymTable:           convertTable,
+		zsx.SymRow:             convertRow,
+		zsx.SymCell:            convertCell,
+		zsx.SymRegionBlock:     convertRegion,
+		zsx.SymRegionQuote:     convertRegion,
+		zsx.SymRegionVerse:     convertRegion,
+		zsx.SymVerbatimCode:    convertVerbatim,
+		zsx.SymVerbatimComment: convertVerbatim,
+		zsx.SymVerbatimEval:    convertVerbatim,
+		zsx.SymVerbatimHTML:    convertVerbatim,
+		zsx.SymVerbatimMath:    convertVerbatim,
+		zsx.SymVerbatimZettel:  convertVerbatim,
+		zsx.SymTransclude:      convertTransclusion,
+		zsx.SymBLOB:            convertBLOB,
+		zsx.SymText:            convertText,
+		zsx.SymSoft:            func(*sx.Pair) Node { return &Soft{} },
+		zsx.SymHard:            func(*sx.Pair) Node { return &Hard{} },
+		zsx.SymLink:            convertLink,
+		zsx.SymEmbed:           convertEmbed,
+		zsx.SymEmbedBLOB:       convertEmbedBLOB,
+		zsx.SymCite:            convertCite,
+		zsx.SymEndnote:         convertEndnote,
+		zsx.SymMark:            convertMark,
+		zsx.SymFormatDelete:    convertFormat,
+		zsx.SymFormatEmph:      convertFormat,
+		zsx.SymFormatInsert:    convertFormat,
+		zsx.SymFormatMark:      convertFormat,
+		zsx.SymFormatQuote:     convertFormat,
+		zsx.SymFormatSpan:      convertFormat,
+		zsx.SymFormatStrong:    convertFormat,
+		zsx.SymFormatSub:       convertFormat,
+		zsx.SymFormatSuper:     convertFormat,
+		zsx.SymLiteralCode:     convertLiteral,
+		zsx.SymLiteralComment:  convertLiteral,
+		zsx.SymLiteralInput:    convertLiteral,
+		zsx.SymLiteralMath:     convertLiteral,
+		zsx.SymLiteralOutput:   convertLiteral,
+	}
+}
+
+func fromList(lst *sx.Pair) []Node {
+	var result []Node
+	for obj := range lst.Values() {
+		node, _ := sx.GetPair(obj)
+		result = append(result, fromSx(node))
+	}
+	return result
+}
+
+func fromRef(ref *sx.Pair) Reference {
+	state, val := zsx.GetReference(ref)
+	return Reference{State: state, Value: val}
+}
+
+func convertBlock(node *sx.Pair) Node  { return &Block{Blocks: fromList(zsx.GetBlock(node))} }
+func convertInline(node *sx.Pair) Node { return &Inline{Inlines: fromList(zsx.GetInline(node))} }
+func convertPara(node *sx.Pair) Node   { return &Para{Inlines: fromList(zsx.GetPara(node))} }
+
+func convertHeading(node *sx.Pair) Node {
+	attrs, level, inlines := zsx.GetHeading(node)
+	return &Heading{Attrs: attrs, Level: level, Inlines: fromList(inlines)}
+}
+
+func convertThematic(node *sx.Pair) Node { return &Thematic{Attrs: zsx.GetThematic(node)} }
+
+func convertList(node *sx.Pair) Node {
+	sym, attrs, items := zsx.GetList(node)
+	return &List{Kind: sym, Attrs: attrs, Items: fromList(items)}
+}
+
+func convertItem(node *sx.Pair) Node {
+	attrs, blocks := zsx.GetListItem(node)
+	return &Item{Attrs: attrs, Blocks: fromList(blocks)}
+}
+
+func convertDescription(node *sx.Pair) Node {
+	attrs, elems := zsx.GetDescription(node)
+	return &Description{Attrs: attrs, Elements: fromList(elems)}
+}
+
+func convertTerm(node *sx.Pair) Node {
+	attrs, inlines := zsx.GetTerm(node)
+	return &Term{Attrs: attrs, Inlines: fromList(inlines)}
+}
+
+func convertDetail(node *sx.Pair) Node { return &Detail{Entries: fromList(zsx.GetDetail(node))} }
+
+func convertEntry(node *sx.Pair) Node {
+	attrs, blocks := zsx.GetEntry(node)
+	return &Entry{Attrs: attrs, Blocks: fromList(blocks)}
+}
+
+func convertTable(node *sx.Pair) Node {
+	attrs, header, rows := zsx.GetTable(node)
+	table := &Table{Attrs: attrs, Rows: fromList(rows)}
+	if header != nil {
+		table.Header = fromSx(header)
+	}
+	return table
+}
+
+func convertRow(node *sx.Pair) Node {
+	attrs, cells := zsx.GetRow(node)
+	return &Row{Attrs: attrs, Cells: fromList(cells)}
+}
+
+func convertCell(node *sx.Pair) Node {
+	attrs, inlines := zsx.GetCell(node)
+	return &Cell{Attrs: attrs, Inlines: fromList(inlines)}
+}
+
+func convertRegion(node *sx.Pair) Node {
+	sym, attrs, blocks, inlines := zsx.GetRegion(node)
+	return &Region{Kind: sym, Attrs: attrs, Blocks: fromList(blocks), Inlines: fromList(inlines)}
+}
+
+func convertVerbatim(node *sx.Pair) Node {
+	sym, attrs, content := zsx.GetVerbatim(node)
+	return &Verbatim{Kind: sym, Attrs: attrs, Content: content}
+}
+
+func convertTransclusion(node *sx.Pair) Node {
+	attrs, ref, inlines := zsx.GetTransclusion(node)
+	return &Transclusion{Attrs: attrs, Ref: fromRef(ref), Inlines: fromList(inlines)}
+}
+
+func convertBLOB(node *sx.Pair) Node {
+	attrs, syntax, data, inlines := zsx.GetBLOBuncode(node)
+	return &BLOB{Attrs: attrs, Syntax: syntax, Data: data, Inlines: fromList(inlines)}
+}
+
+func convertText(node *sx.Pair) Node { return &Text{Text: zsx.GetText(node)} }
+
+func convertLink(node *sx.Pair) Node {
+	attrs, ref, inlines := zsx.GetLink(node)
+	return &Link{Attrs: attrs, Ref: fromRef(ref), Inlines: fromList(inlines)}
+}
+
+func convertEmbed(node *sx.Pair) Node {
+	attrs, ref, syntax, inlines := zsx.GetEmbed(node)
+	return &Embed{Attrs: attrs, Ref: fromRef(ref), Syntax: syntax, Inlines: fromList(inlines)}
+}
+
+func convertEmbedBLOB(node *sx.Pair) Node {
+	attrs, syntax, data, inlines := zsx.GetEmbedBLOBuncode(node)
+	return &EmbedBLOB{Attrs: attrs, Syntax: syntax, Data: data, Inlines: fromList(inlines)}
+}
+
+func convertCite(node *sx.Pair) Node {
+	attrs, key, inlines := zsx.GetCite(node)
+	return &Cite{Attrs: attrs, Key: key, Inlines: fromList(inlines)}
+}
+
+func convertEndnote(node *sx.Pair) Node {
+	attrs, inlines := zsx.GetEndnote(node)
+	return &Endnote{Attrs: attrs, Inlines: fromList(inlines)}
+}
+
+func convertMark(node *sx.Pair) Node {
+	attrs, mark, inlines := zsx.GetMark(node)
+	return &Mark{Attrs: attrs, Mark: mark, Inlines: fromList(inlines)}
+}
+
+func convertFormat(node *sx.Pair) Node {
+	sym, attrs, inlines := zsx.GetFormat(node)
+	return &Format{Kind: sym, Attrs: attrs, Inlines: fromList(inlines)}
+}
+
+func convertLiteral(node *sx.Pair) Node {
+	sym, attrs, content := zsx.GetLiteral(node)
+	return &Literal{Kind: sym, Attrs: attrs, Content: content}
+}
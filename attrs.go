@@ -116,14 +116,14 @@ func (a Attributes) GetClasses() []string { return a.Values("class") }
 // HasClass returns true, if attributes contains the given class.
 func (a Attributes) HasClass(s string) bool { return a.Has("class", s) }
 
-// AsAssoc returns the attributes as an assoc list.
+// AsAssoc returns the attributes as an assoc list, sorted by key.
 //
 // It is partly the reverse operation of [GetAttributes]:
 // `maps.Equal(GetAttributes(a.AsAssoc()), a)`.
 func (a Attributes) AsAssoc() *sx.Pair {
 	var lb sx.ListBuilder
-	for k, v := range a {
-		lb.Add(sx.Cons(sx.MakeString(k), sx.MakeString(v)))
+	for _, k := range a.Keys() {
+		lb.Add(sx.Cons(sx.MakeString(k), sx.MakeString(a[k])))
 	}
 	return lb.List()
 }
@@ -157,8 +157,21 @@ func GetAttributes(seq *sx.Pair) (result Attributes) {
 // CleanSpecial removes all values which have keys with a special / internal meaning.
 func (a Attributes) CleanSpecial() {
 	for k := range a {
-		if len(k) > 6 && k[:5] == "*ZSX-" && k[len(k)-1] == '*' {
+		if isSpecialKey(k) {
 			delete(a, k)
 		}
 	}
 }
+
+func isSpecialKey(k string) bool { return len(k) > 6 && k[:5] == "*ZSX-" && k[len(k)-1] == '*' }
+
+// HasOtherKeys returns true, if there is a key that is not one of the given
+// keys. Keys with a special / internal meaning are ignored.
+func (a Attributes) HasOtherKeys(keys ...string) bool {
+	for k := range a {
+		if !isSpecialKey(k) && !slices.Contains(keys, k) {
+			return true
+		}
+	}
+	return false
+}
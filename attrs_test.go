@@ -93,6 +93,14 @@ func TestAssoc(t *testing.T) {
 	}
 }
 
+func TestAssocSorted(t *testing.T) {
+	t.Parallel()
+	a := zsx.Attributes{"c": "3", "a": "1", "b": "2", "": "0"}
+	if got, exp := a.AsAssoc().String(), `(("" . "0") ("a" . "1") ("b" . "2") ("c" . "3"))`; got != exp {
+		t.Errorf("expected %s, but got %s", exp, got)
+	}
+}
+
 func TestCleanSpecial(t *testing.T) {
 	t.Parallel()
 	orig := zsx.Attributes{"id": "123"}
@@ -114,3 +122,17 @@ func TestCleanSpecial(t *testing.T) {
 		t.Errorf("a should be empty, but: %v", a)
 	}
 }
+
+func TestHasOtherKeys(t *testing.T) {
+	t.Parallel()
+	a := zsx.Attributes{"title": "t", zsx.SymSpecialID.GetValue(): "17"}
+	if a.HasOtherKeys("title") {
+		t.Errorf("no other keys expected: %v", a)
+	}
+	if !a.HasOtherKeys() {
+		t.Errorf("other keys expected: %v", a)
+	}
+	if zsx.Attributes(nil).HasOtherKeys() {
+		t.Error("nil attributes have no other keys")
+	}
+}
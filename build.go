@@ -157,6 +157,12 @@ func MakeThematic(attrs *sx.Pair) *sx.Pair {
 // GetThematic returns the elements of a thematic break node.
 func GetThematic(node *sx.Pair) *sx.Pair { return node.Tail().Head() }
 
+// MakeDescription builds a description list node. Its elements are
+// alternating term and detail nodes.
+func MakeDescription(attrs *sx.Pair, elements *sx.Pair) *sx.Pair {
+	return elements.Cons(attrs).Cons(SymDescription)
+}
+
 // GetDescription returns the elements of a description list node.
 func GetDescription(node *sx.Pair) (*sx.Pair, *sx.Pair) {
 	attrsNode := node.Tail()
@@ -175,6 +181,9 @@ func GetTerm(node *sx.Pair) (*sx.Pair, *sx.Pair) {
 	return attrsNode.Head(), attrsNode.Tail()
 }
 
+// MakeDetail builds a detail node, which contains entry nodes.
+func MakeDetail(entries *sx.Pair) *sx.Pair { return entries.Cons(SymDetail) }
+
 // GetDetail returns all elements of a detail node.
 func GetDetail(node *sx.Pair) *sx.Pair { return node.Tail() }
 
@@ -189,6 +198,11 @@ func GetEntry(node *sx.Pair) (*sx.Pair, *sx.Pair) {
 	return attrsNode.Head(), attrsNode.Tail()
 }
 
+// MakeTable builds a table node. The header row may be nil.
+func MakeTable(attrs *sx.Pair, header *sx.Pair, rows *sx.Pair) *sx.Pair {
+	return rows.Cons(header).Cons(attrs).Cons(SymTable)
+}
+
 // GetTable returns the elements of a table.
 func GetTable(node *sx.Pair) (*sx.Pair, *sx.Pair, *sx.Pair) {
 	attrsNode := node.Tail()
@@ -279,6 +293,20 @@ func GetBLOBuncode(node *sx.Pair) (*sx.Pair, string, string, *sx.Pair) {
 	return nil, "", "", nil
 }
 
+// DataURL returns the data URL of an image with the given syntax. The data
+// is the undecoded content of a BLOB node, as returned by [GetBLOBuncode] or
+// [GetEmbedBLOBuncode]: it is base64 encoded, except for SyntaxSVG.
+func DataURL(syntax, data string) string {
+	mime := syntax
+	switch syntax {
+	case SyntaxSVG:
+		mime, data = "svg+xml", base64.StdEncoding.EncodeToString([]byte(data))
+	case "jpg":
+		mime = "jpeg"
+	}
+	return "data:image/" + mime + ";base64," + data
+}
+
 // MakeText builds a text node.
 func MakeText(text string) *sx.Pair {
 	return sx.Cons(SymText, sx.Cons(sx.MakeString(text), sx.Nil()))
@@ -14,6 +14,7 @@
 package zsx_test
 
 import (
+	"strings"
 	"testing"
 
 	"t73f.de/r/sx"
@@ -54,6 +55,142 @@ func TestBuildMark(t *testing.T) {
 	}
 }
 
+// TestBuildRoundTrip checks for every node that its Get function returns the
+// values given to its Make function.
+func TestBuildRoundTrip(t *testing.T) {
+	t.Parallel()
+	attrs := makeSimpleAttrs()
+	ins := sx.MakeList(zsx.MakeText("a"))
+	blocks := sx.MakeList(zsx.MakePara(zsx.MakeText("b")))
+	ref := zsx.MakeReference(zsx.SymRefStateExternal, "https://t73f.de")
+	items := sx.MakeList(zsx.MakeListItem(nil, blocks))
+	cells := sx.MakeList(zsx.MakeCell(nil, ins))
+	row := zsx.MakeRow(attrs, cells)
+	rows := sx.MakeList(row)
+	elems := sx.MakeList(zsx.MakeTerm(nil, ins), zsx.MakeDetail(nil))
+	entries := sx.MakeList(zsx.MakeEntry(nil, blocks))
+
+	getList := func(n *sx.Pair) []any { sym, a, items := zsx.GetList(n); return []any{sym, a, items} }
+	getRegion := func(n *sx.Pair) []any { sym, a, bs, is := zsx.GetRegion(n); return []any{sym, a, bs, is} }
+	getVerbatim := func(n *sx.Pair) []any { sym, a, c := zsx.GetVerbatim(n); return []any{sym, a, c} }
+	getFormat := func(n *sx.Pair) []any { sym, a, is := zsx.GetFormat(n); return []any{sym, a, is} }
+	getLiteral := func(n *sx.Pair) []any { sym, a, c := zsx.GetLiteral(n); return []any{sym, a, c} }
+
+	testcases := []struct {
+		name string
+		node *sx.Pair
+		exp  []any
+		get  func(*sx.Pair) []any
+	}{
+		{"BLOCK", zsx.MakeBlockList(blocks), []any{blocks}, func(n *sx.Pair) []any { return []any{zsx.GetBlock(n)} }},
+		{"INLINE", zsx.MakeInlineList(ins), []any{ins}, func(n *sx.Pair) []any { return []any{zsx.GetInline(n)} }},
+		{"PARA", zsx.MakeParaList(ins), []any{ins}, func(n *sx.Pair) []any { return []any{zsx.GetPara(n)} }},
+		{"HEADING", zsx.MakeHeading(attrs, 3, ins), []any{attrs, 3, ins},
+			func(n *sx.Pair) []any { a, l, is := zsx.GetHeading(n); return []any{a, l, is} }},
+		{"THEMATIC", zsx.MakeThematic(attrs), []any{attrs}, func(n *sx.Pair) []any { return []any{zsx.GetThematic(n)} }},
+		{"ORDERED", zsx.MakeList(zsx.SymListOrdered, attrs, items), []any{zsx.SymListOrdered, attrs, items}, getList},
+		{"UNORDERED", zsx.MakeList(zsx.SymListUnordered, attrs, items), []any{zsx.SymListUnordered, attrs, items}, getList},
+		{"QUOTATION", zsx.MakeList(zsx.SymListQuote, attrs, items), []any{zsx.SymListQuote, attrs, items}, getList},
+		{"ITEM", zsx.MakeListItem(attrs, blocks), []any{attrs, blocks},
+			func(n *sx.Pair) []any { a, bs := zsx.GetListItem(n); return []any{a, bs} }},
+		{"DESCRIPTION", zsx.MakeDescription(attrs, elems), []any{attrs, elems},
+			func(n *sx.Pair) []any { a, es := zsx.GetDescription(n); return []any{a, es} }},
+		{"TERM", zsx.MakeTerm(attrs, ins), []any{attrs, ins},
+			func(n *sx.Pair) []any { a, is := zsx.GetTerm(n); return []any{a, is} }},
+		{"DETAIL", zsx.MakeDetail(entries), []any{entries}, func(n *sx.Pair) []any { return []any{zsx.GetDetail(n)} }},
+		{"ENTRY", zsx.MakeEntry(attrs, blocks), []any{attrs, blocks},
+			func(n *sx.Pair) []any { a, bs := zsx.GetEntry(n); return []any{a, bs} }},
+		{"TABLE", zsx.MakeTable(attrs, row, rows), []any{attrs, row, rows},
+			func(n *sx.Pair) []any { a, h, rs := zsx.GetTable(n); return []any{a, h, rs} }},
+		{"TABLE-NO-HEADER", zsx.MakeTable(attrs, nil, rows), []any{attrs, (*sx.Pair)(nil), rows},
+			func(n *sx.Pair) []any { a, h, rs := zsx.GetTable(n); return []any{a, h, rs} }},
+		{"ROW", zsx.MakeRow(attrs, cells), []any{attrs, cells},
+			func(n *sx.Pair) []any { a, cs := zsx.GetRow(n); return []any{a, cs} }},
+		{"CELL", zsx.MakeCell(attrs, ins), []any{attrs, ins},
+			func(n *sx.Pair) []any { a, is := zsx.GetCell(n); return []any{a, is} }},
+		{"REGION-BLOCK", zsx.MakeRegion(zsx.SymRegionBlock, attrs, blocks, ins), []any{zsx.SymRegionBlock, attrs, blocks, ins}, getRegion},
+		{"REGION-QUOTE", zsx.MakeRegion(zsx.SymRegionQuote, attrs, blocks, ins), []any{zsx.SymRegionQuote, attrs, blocks, ins}, getRegion},
+		{"REGION-VERSE", zsx.MakeRegion(zsx.SymRegionVerse, attrs, blocks, ins), []any{zsx.SymRegionVerse, attrs, blocks, ins}, getRegion},
+		{"VERBATIM-CODE", zsx.MakeVerbatim(zsx.SymVerbatimCode, attrs, "c"), []any{zsx.SymVerbatimCode, attrs, "c"}, getVerbatim},
+		{"VERBATIM-COMMENT", zsx.MakeVerbatim(zsx.SymVerbatimComment, attrs, "c"), []any{zsx.SymVerbatimComment, attrs, "c"}, getVerbatim},
+		{"VERBATIM-EVAL", zsx.MakeVerbatim(zsx.SymVerbatimEval, attrs, "c"), []any{zsx.SymVerbatimEval, attrs, "c"}, getVerbatim},
+		{"VERBATIM-HTML", zsx.MakeVerbatim(zsx.SymVerbatimHTML, attrs, "c"), []any{zsx.SymVerbatimHTML, attrs, "c"}, getVerbatim},
+		{"VERBATIM-MATH", zsx.MakeVerbatim(zsx.SymVerbatimMath, attrs, "c"), []any{zsx.SymVerbatimMath, attrs, "c"}, getVerbatim},
+		{"VERBATIM-ZETTEL", zsx.MakeVerbatim(zsx.SymVerbatimZettel, attrs, "c"), []any{zsx.SymVerbatimZettel, attrs, "c"}, getVerbatim},
+		{"TRANSCLUDE", zsx.MakeTransclusion(attrs, ref, ins), []any{attrs, ref, ins},
+			func(n *sx.Pair) []any { a, r, is := zsx.GetTransclusion(n); return []any{a, r, is} }},
+		{"BLOB", zsx.MakeBLOB(attrs, "png", []byte{0, 255}, ins), []any{attrs, "png", "\x00\xff", ins},
+			func(n *sx.Pair) []any { a, s, d, is := zsx.GetBLOB(n); return []any{a, s, string(d), is} }},
+		{"BLOB-SVG", zsx.MakeBLOBuncode(attrs, zsx.SyntaxSVG, "<svg/>", ins), []any{attrs, zsx.SyntaxSVG, "<svg/>", ins},
+			func(n *sx.Pair) []any { a, s, d, is := zsx.GetBLOBuncode(n); return []any{a, s, d, is} }},
+		{"TEXT", zsx.MakeText("t"), []any{"t"}, func(n *sx.Pair) []any { return []any{zsx.GetText(n)} }},
+		{"SOFT", zsx.MakeSoft(), []any{}, func(*sx.Pair) []any { return []any{} }},
+		{"HARD", zsx.MakeHard(), []any{}, func(*sx.Pair) []any { return []any{} }},
+		{"LINK", zsx.MakeLink(attrs, ref, ins), []any{attrs, ref, ins},
+			func(n *sx.Pair) []any { a, r, is := zsx.GetLink(n); return []any{a, r, is} }},
+		{"EMBED", zsx.MakeEmbed(attrs, ref, "png", ins), []any{attrs, ref, "png", ins},
+			func(n *sx.Pair) []any { a, r, s, is := zsx.GetEmbed(n); return []any{a, r, s, is} }},
+		{"EMBED-BLOB", zsx.MakeEmbedBLOB(attrs, "png", []byte{1, 2}, ins), []any{attrs, "png", "\x01\x02", ins},
+			func(n *sx.Pair) []any { a, s, d, is := zsx.GetEmbedBLOB(n); return []any{a, s, string(d), is} }},
+		{"EMBED-BLOB-SVG", zsx.MakeEmbedBLOBuncode(attrs, zsx.SyntaxSVG, "<svg/>", ins), []any{attrs, zsx.SyntaxSVG, "<svg/>", ins},
+			func(n *sx.Pair) []any { a, s, d, is := zsx.GetEmbedBLOBuncode(n); return []any{a, s, d, is} }},
+		{"CITE", zsx.MakeCite(attrs, "key", ins), []any{attrs, "key", ins},
+			func(n *sx.Pair) []any { a, k, is := zsx.GetCite(n); return []any{a, k, is} }},
+		{"ENDNOTE", zsx.MakeEndnote(attrs, ins), []any{attrs, ins},
+			func(n *sx.Pair) []any { a, is := zsx.GetEndnote(n); return []any{a, is} }},
+		{"MARK", zsx.MakeMark(attrs, "m", ins), []any{attrs, "m", ins},
+			func(n *sx.Pair) []any { a, m, is := zsx.GetMark(n); return []any{a, m, is} }},
+		{"FORMAT-DELETE", zsx.MakeFormat(zsx.SymFormatDelete, attrs, ins), []any{zsx.SymFormatDelete, attrs, ins}, getFormat},
+		{"FORMAT-EMPH", zsx.MakeFormat(zsx.SymFormatEmph, attrs, ins), []any{zsx.SymFormatEmph, attrs, ins}, getFormat},
+		{"FORMAT-INSERT", zsx.MakeFormat(zsx.SymFormatInsert, attrs, ins), []any{zsx.SymFormatInsert, attrs, ins}, getFormat},
+		{"FORMAT-MARK", zsx.MakeFormat(zsx.SymFormatMark, attrs, ins), []any{zsx.SymFormatMark, attrs, ins}, getFormat},
+		{"FORMAT-QUOTE", zsx.MakeFormat(zsx.SymFormatQuote, attrs, ins), []any{zsx.SymFormatQuote, attrs, ins}, getFormat},
+		{"FORMAT-SPAN", zsx.MakeFormat(zsx.SymFormatSpan, attrs, ins), []any{zsx.SymFormatSpan, attrs, ins}, getFormat},
+		{"FORMAT-STRONG", zsx.MakeFormat(zsx.SymFormatStrong, attrs, ins), []any{zsx.SymFormatStrong, attrs, ins}, getFormat},
+		{"FORMAT-SUB", zsx.MakeFormat(zsx.SymFormatSub, attrs, ins), []any{zsx.SymFormatSub, attrs, ins}, getFormat},
+		{"FORMAT-SUPER", zsx.MakeFormat(zsx.SymFormatSuper, attrs, ins), []any{zsx.SymFormatSuper, attrs, ins}, getFormat},
+		{"LITERAL-CODE", zsx.MakeLiteral(zsx.SymLiteralCode, attrs, "l"), []any{zsx.SymLiteralCode, attrs, "l"}, getLiteral},
+		{"LITERAL-COMMENT", zsx.MakeLiteral(zsx.SymLiteralComment, attrs, "l"), []any{zsx.SymLiteralComment, attrs, "l"}, getLiteral},
+		{"LITERAL-INPUT", zsx.MakeLiteral(zsx.SymLiteralInput, attrs, "l"), []any{zsx.SymLiteralInput, attrs, "l"}, getLiteral},
+		{"LITERAL-MATH", zsx.MakeLiteral(zsx.SymLiteralMath, attrs, "l"), []any{zsx.SymLiteralMath, attrs, "l"}, getLiteral},
+		{"LITERAL-OUTPUT", zsx.MakeLiteral(zsx.SymLiteralOutput, attrs, "l"), []any{zsx.SymLiteralOutput, attrs, "l"}, getLiteral},
+		{"REFERENCE", ref, []any{zsx.SymRefStateExternal, "https://t73f.de"},
+			func(n *sx.Pair) []any { sym, val := zsx.GetReference(n); return []any{sym, val} }},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if sym := zsx.NodeSymbol(tc.node); sym == nil {
+				t.Errorf("no node symbol: %v", tc.node)
+			} else if exp, _, _ := strings.Cut(tc.name, "-NO-"); tc.name != "REFERENCE" && strings.TrimSuffix(exp, "-SVG") != sym.GetValue() {
+				t.Errorf("symbol: exp=%v, got=%v", exp, sym)
+			}
+			got := tc.get(tc.node)
+			if len(got) != len(tc.exp) {
+				t.Fatalf("exp %d values, got %d: %v", len(tc.exp), len(got), got)
+			}
+			for i, exp := range tc.exp {
+				if got[i] != exp {
+					t.Errorf("value %d: exp=%v, got=%v", i, exp, got[i])
+				}
+			}
+		})
+	}
+}
+
 func makeSimpleAttrs() *sx.Pair {
 	return sx.MakeList(sx.Cons(sx.MakeSymbol("attr-key"), sx.MakeString("attrs-val")))
 }
+
+func TestDataURL(t *testing.T) {
+	t.Parallel()
+	testcases := []struct{ syntax, data, exp string }{
+		{"png", "AwQ=", "data:image/png;base64,AwQ="},
+		{"jpg", "AwQ=", "data:image/jpeg;base64,AwQ="},
+		{zsx.SyntaxSVG, "<svg/>", "data:image/svg+xml;base64,PHN2Zy8+"},
+	}
+	for _, tc := range testcases {
+		if got := zsx.DataURL(tc.syntax, tc.data); got != tc.exp {
+			t.Errorf("DataURL(%q, %q): expected %q, but got %q", tc.syntax, tc.data, tc.exp, got)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package csv provides a parser for comma separated values and for similar
+// formats, like tab separated values. The parser produces a BLOCK node that
+// contains one TABLE node.
+//
+// All rows of the table have the same number of cells. A column that
+// contains only numbers is aligned to the right.
+package csv
+
+import (
+	"regexp"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// Options specify the format of the source.
+type Options struct {
+	// Delimiter separates the fields of a row. If not set, it is ','.
+	Delimiter rune
+
+	// Quote encloses fields that contain delimiters, line endings, or
+	// quote characters. Within a quoted field, a quote character must be
+	// doubled. If not set, fields cannot be quoted.
+	Quote rune
+
+	// Header states that the first row is a header row.
+	Header bool
+}
+
+// Predefined options.
+var (
+	// CSV is the format of comma separated values, with a header row.
+	CSV = Options{Delimiter: ',', Quote: '"', Header: true}
+
+	// TSV is the format of tab separated values, with a header row.
+	TSV = Options{Delimiter: '\t', Header: true}
+)
+
+// ParseBlocks parses the remaining input and returns a BLOCK node. If there
+// are no rows, the BLOCK node is empty.
+func ParseBlocks(inp *input.Input, opts Options) *sx.Pair {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	var rows [][]string
+	for inp.Ch != input.EOS {
+		if input.IsEOLEOS(inp.Ch) {
+			inp.EatEOL()
+			continue
+		}
+		rows = append(rows, parseRow(inp, &opts))
+	}
+	if len(rows) == 0 {
+		return zsx.MakeBlock()
+	}
+	return zsx.MakeBlock(buildTable(rows, opts.Header))
+}
+
+// parseRow parses all fields of a row.
+func parseRow(inp *input.Input, opts *Options) []string {
+	var fields []string
+	for {
+		fields = append(fields, parseField(inp, opts))
+		if inp.Ch != opts.Delimiter {
+			break
+		}
+		inp.Next()
+	}
+	inp.EatEOL()
+	return fields
+}
+
+// parseField parses a field, which ends with a delimiter or at the end of
+// the line. Whitespace around an unquoted field is ignored.
+func parseField(inp *input.Input, opts *Options) string {
+	for inp.IsSpace() && inp.Ch != opts.Delimiter {
+		inp.Next()
+	}
+	if opts.Quote != 0 && inp.Ch == opts.Quote {
+		return parseQuotedField(inp, opts)
+	}
+	start := inp.Pos
+	for inp.Ch != opts.Delimiter && !input.IsEOLEOS(inp.Ch) {
+		inp.Next()
+	}
+	return strings.TrimRightFunc(string(inp.Src[start:inp.Pos]), input.IsSpace)
+}
+
+// parseQuotedField parses a quoted field. Line endings are part of the field.
+// Characters after the closing quote are appended to the field.
+func parseQuotedField(inp *input.Input, opts *Options) string {
+	var sb strings.Builder
+	inp.Next()
+	for {
+		switch inp.Ch {
+		case input.EOS:
+			return sb.String()
+		case '\n', '\r':
+			inp.EatEOL()
+			sb.WriteByte('\n')
+		case opts.Quote:
+			if inp.Next() == opts.Quote {
+				sb.WriteRune(opts.Quote)
+				inp.Next()
+				continue
+			}
+			start := inp.Pos
+			for inp.Ch != opts.Delimiter && !input.IsEOLEOS(inp.Ch) {
+				inp.Next()
+			}
+			sb.WriteString(strings.TrimRightFunc(string(inp.Src[start:inp.Pos]), input.IsSpace))
+			return sb.String()
+		default:
+			sb.WriteRune(inp.Ch)
+			inp.Next()
+		}
+	}
+}
+
+// buildTable builds the table node. All rows are padded to the same width.
+func buildTable(rows [][]string, hasHeader bool) *sx.Pair {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var header []string
+	if hasHeader {
+		header, rows = rows[0], rows[1:]
+	}
+	aligns := numericAligns(rows, width)
+
+	var lb sx.ListBuilder
+	for _, row := range rows {
+		lb.Add(buildRow(row, width, aligns))
+	}
+	var headerRow *sx.Pair
+	if header != nil {
+		headerRow = buildRow(header, width, aligns)
+	}
+	return zsx.MakeTable(nil, headerRow, lb.List())
+}
+
+var reNumber = regexp.MustCompile(`^[+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][+-]?[0-9]+)?$`)
+
+// numericAligns returns the alignment of all columns. A column is aligned to
+// the right, if all of its non-empty cells are numbers.
+func numericAligns(rows [][]string, width int) []sx.Object {
+	aligns := make([]sx.Object, width)
+	for col := range width {
+		numeric := false
+		for _, row := range rows {
+			if col >= len(row) || row[col] == "" {
+				continue
+			}
+			if !reNumber.MatchString(row[col]) {
+				numeric = false
+				break
+			}
+			numeric = true
+		}
+		if numeric {
+			aligns[col] = zsx.AttrAlignRight
+		}
+	}
+	return aligns
+}
+
+// buildRow builds a row node with exactly width cells.
+func buildRow(row []string, width int, aligns []sx.Object) *sx.Pair {
+	var lb sx.ListBuilder
+	for i := range width {
+		var attrs *sx.Pair
+		if align := aligns[i]; align != nil {
+			attrs = sx.MakeList(sx.Cons(zsx.SymAttrAlign, align))
+		}
+		var inlines *sx.Pair
+		if i < len(row) {
+			inlines = buildInlines(row[i])
+		}
+		lb.Add(zsx.MakeCell(attrs, inlines))
+	}
+	return zsx.MakeRow(nil, lb.List())
+}
+
+// buildInlines builds text nodes for the field content. Line endings within
+// a field are represented as SOFT nodes.
+func buildInlines(field string) *sx.Pair {
+	if field == "" {
+		return nil
+	}
+	var lb sx.ListBuilder
+	for i, line := range strings.Split(field, "\n") {
+		if i > 0 {
+			lb.Add(zsx.MakeSoft())
+		}
+		if line != "" {
+			lb.Add(zsx.MakeText(line))
+		}
+	}
+	return lb.List()
+}
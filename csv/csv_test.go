@@ -0,0 +1,59 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package csv_test
+
+import (
+	"testing"
+
+	"t73f.de/r/zsx/csv"
+	"t73f.de/r/zsx/input"
+)
+
+func TestParseBlocks(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+		opts csv.Options
+		exp  string
+	}{
+		{"empty", "\n\n", csv.CSV, "(BLOCK)"},
+		{"header", "a,b\nc,d\n", csv.CSV,
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b"))) (ROW () (CELL () (TEXT "c")) (CELL () (TEXT "d")))))`},
+		{"no-header", "a,b", csv.Options{},
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b")))))`},
+		{"numeric", "n,v\na,1.5\nb,\nc,-2e3", csv.CSV,
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "n")) (CELL ((align . "right")) (TEXT "v"))) (ROW () (CELL () (TEXT "a")) (CELL ((align . "right")) (TEXT "1.5"))) (ROW () (CELL () (TEXT "b")) (CELL ((align . "right")))) (ROW () (CELL () (TEXT "c")) (CELL ((align . "right")) (TEXT "-2e3")))))`},
+		{"not-numeric", "1\nx", csv.Options{},
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "1"))) (ROW () (CELL () (TEXT "x")))))`},
+		{"padding", "a\nb,c", csv.Options{},
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a")) (CELL ())) (ROW () (CELL () (TEXT "b")) (CELL () (TEXT "c")))))`},
+		{"quoted", "\" a,\"\"b\"\" \" , c\r\n\"x\ny\"", csv.Options{Quote: '"'},
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT " a,\"b\" ")) (CELL () (TEXT "c"))) (ROW () (CELL () (TEXT "x") (SOFT) (TEXT "y")) (CELL ()))))`},
+		{"no-quoting", `"a,b"`, csv.Options{},
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "\"a")) (CELL () (TEXT "b\"")))))`},
+		{"tsv", "a b\t\tc\n", csv.TSV,
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "a b")) (CELL ()) (CELL () (TEXT "c")))))`},
+		{"delimiter", "a;b", csv.Options{Delimiter: ';'},
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b")))))`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := csv.ParseBlocks(input.NewInput([]byte(tc.src)), tc.opts).String()
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %s\ngot: %s", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package diff
+
+import (
+	"slices"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Class names to mark changed block nodes.
+const (
+	ClassInsert = "zsx-insert"
+	ClassDelete = "zsx-delete"
+)
+
+// Changes returns the new node, where all changes against the old node are
+// marked, e.g. to render them visually. Deleted and inserted inline nodes are
+// placed within a FORMAT-DELETE or a FORMAT-INSERT node. Deleted and inserted
+// block nodes are placed within a REGION-BLOCK node with the class
+// [ClassDelete] or [ClassInsert]. Changed text is marked as deleted old text
+// and inserted new text, moved nodes are marked as deleted and inserted.
+// Changes of attributes are not marked. Inserted and deleted nodes that are
+// neither inline nor block nodes, e.g. list items or table rows, are kept, but
+// their content is marked.
+func Changes(oldNode, newNode *sx.Pair) *sx.Pair {
+	if result, ok := mergeNode(oldNode, newNode); ok {
+		return result
+	}
+	return newNode
+}
+
+// mergeNode returns the new node with marked changes of its child nodes, if
+// the node itself is not replaced.
+func mergeNode(oldNode, newNode *sx.Pair) (*sx.Pair, bool) {
+	if oldNode.IsEqual(newNode) {
+		return newNode, true
+	}
+	layout, ok := commonLayout(oldNode, newNode)
+	if !ok {
+		return nil, false
+	}
+	var lb sx.ListBuilder
+	lb.Add(newNode.Car())
+	oldElems, newElems := oldNode.Tail(), newNode.Tail()
+	for _, elem := range layout.Elements {
+		switch elem.Kind {
+		case zsx.ElementRest:
+			for obj := range mergeList(oldElems, newElems, elem.Context).Values() {
+				lb.Add(obj)
+			}
+			return lb.List(), true
+		case zsx.ElementList:
+			oldLst, _ := sx.GetPair(oldElems.Car())
+			newLst, _ := sx.GetPair(newElems.Car())
+			lb.Add(mergeList(oldLst, newLst, elem.Context))
+		case zsx.ElementNode:
+			oldChild, _ := sx.GetPair(oldElems.Car())
+			newChild, _ := sx.GetPair(newElems.Car())
+			if merged, ok := mergeNode(oldChild, newChild); ok {
+				lb.Add(merged)
+			} else {
+				lb.Add(newElems.Car())
+			}
+		case zsx.ElementAttrs:
+			lb.Add(newElems.Car())
+		default:
+			if !oldElems.Car().IsEqual(newElems.Car()) {
+				return nil, false
+			}
+			lb.Add(newElems.Car())
+		}
+		oldElems, newElems = oldElems.Tail(), newElems.Tail()
+	}
+	return lb.List(), true
+}
+
+// mergeList returns the new list, where inserted and deleted nodes are marked.
+func mergeList(oldLst, newLst *sx.Pair, ctx zsx.Context) *sx.Pair {
+	oldObjs, newObjs := slices.Collect(oldLst.Values()), slices.Collect(newLst.Values())
+	match, moved := align(oldObjs, newObjs)
+	kept := make([]bool, len(oldObjs))
+	for j, i := range match {
+		if i >= 0 && !moved[j] {
+			kept[i] = true
+		}
+	}
+
+	var lb sx.ListBuilder
+	pos := 0 // all deleted old objects before pos are already added
+	addDeleted := func(end int) {
+		for ; pos < end; pos++ {
+			if !kept[pos] {
+				addMarked(&lb, oldObjs[pos], ctx, false)
+			}
+		}
+	}
+	for j, newObj := range newObjs {
+		i := match[j]
+		if i < 0 || moved[j] {
+			addMarked(&lb, newObj, ctx, true)
+			continue
+		}
+		addDeleted(i)
+		pos = i + 1
+		oldNode, _ := sx.GetPair(oldObjs[i])
+		newNode, _ := sx.GetPair(newObj)
+		if merged, ok := mergeNode(oldNode, newNode); ok {
+			lb.Add(merged)
+		} else {
+			addMarked(&lb, oldObjs[i], ctx, false)
+			addMarked(&lb, newObj, ctx, true)
+		}
+	}
+	addDeleted(len(oldObjs))
+	return lb.List()
+}
+
+// addMarked adds an inserted or deleted node to the list, if possible in the
+// given context.
+func addMarked(lb *sx.ListBuilder, obj sx.Object, ctx zsx.Context, insert bool) {
+	node, isPair := sx.GetPair(obj)
+	if !isPair {
+		return
+	}
+	switch ctx {
+	case zsx.ContextInline:
+		sym := zsx.SymFormatDelete
+		if insert {
+			sym = zsx.SymFormatInsert
+		}
+		lb.Add(zsx.MakeFormat(sym, nil, sx.MakeList(node)))
+	case zsx.ContextBlock:
+		class := ClassDelete
+		if insert {
+			class = ClassInsert
+		}
+		attrs := zsx.Attributes{}.AddClass(class).AsAssoc()
+		lb.Add(zsx.MakeRegion(zsx.SymRegionBlock, attrs, sx.MakeList(node), nil))
+	default:
+		lb.Add(markContent(node, insert))
+	}
+}
+
+// markContent returns a copy of the node, where all its child nodes are
+// marked as inserted or deleted.
+func markContent(node *sx.Pair, insert bool) *sx.Pair {
+	layout, found := zsx.GetLayout(zsx.NodeSymbol(node))
+	if !found {
+		return node
+	}
+	var lb sx.ListBuilder
+	lb.Add(node.Car())
+	elems := node.Tail()
+	for _, elem := range layout.Elements {
+		switch elem.Kind {
+		case zsx.ElementRest:
+			for obj := range elems.Values() {
+				addMarked(&lb, obj, elem.Context, insert)
+			}
+			return lb.List()
+		case zsx.ElementList:
+			lst, _ := sx.GetPair(elems.Car())
+			var marked sx.ListBuilder
+			for obj := range lst.Values() {
+				addMarked(&marked, obj, elem.Context, insert)
+			}
+			lb.Add(marked.List())
+		default:
+			lb.Add(elems.Car())
+		}
+		elems = elems.Tail()
+	}
+	return lb.List()
+}
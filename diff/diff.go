@@ -0,0 +1,322 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package diff computes the structural difference between two zsx trees, and
+// applies it as a patch.
+//
+// A [Diff] is a sequence of operations that transform the old tree into the
+// new tree. Nodes are compared according to their layout (see
+// [zsx.GetLayout]), so that changes of attributes, changes of content (like
+// the text of a TEXT node, or the level of a heading), and changes of child
+// nodes are reported separately. Child nodes are inserted, deleted, or moved
+// within their list.
+//
+// A diff can be serialized as an sx list, see [Diff.Sx] and [Parse].
+package diff
+
+import (
+	"slices"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Kind specifies the operation of a diff.
+type Kind uint8
+
+// Constants for Kind.
+const (
+	KindInsert  Kind = iota // Insert Value at Path
+	KindDelete              // Delete the object at Path
+	KindMove                // Move the object at Path to To
+	KindReplace             // Replace the node at Path by Value
+	KindAttrs               // Replace the attributes at Path by Value
+	KindUpdate              // Replace the content at Path by Value
+)
+
+var kindNames = [...]string{
+	KindInsert:  "INSERT",
+	KindDelete:  "DELETE",
+	KindMove:    "MOVE",
+	KindReplace: "REPLACE",
+	KindAttrs:   "ATTRS",
+	KindUpdate:  "UPDATE",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "UNKNOWN"
+}
+
+// Op is an operation of a diff.
+//
+// A path is a sequence of list indices that address an object within the
+// tree, starting at the root node. Index 0 addresses the symbol of a node,
+// index 1 its first element, and so on. An empty path addresses the root node.
+// Paths are relative to the tree that results from all previous operations.
+type Op struct {
+	Kind  Kind
+	Path  []int
+	To    []int     // Target path of KindMove, relative to the tree after removing the object
+	Value sx.Object // New object of KindInsert, KindReplace, KindAttrs, and KindUpdate
+}
+
+// Diff is a sequence of operations.
+type Diff []Op
+
+// Compute returns the operations that transform the old node into the new
+// node. If both are equal, the diff is empty.
+func Compute(oldNode, newNode *sx.Pair) Diff {
+	var d differ
+	d.diffNode(oldNode, newNode, nil)
+	return d.ops
+}
+
+type differ struct {
+	ops Diff
+}
+
+func (d *differ) add(kind Kind, path []int, value sx.Object) {
+	d.ops = append(d.ops, Op{Kind: kind, Path: path, Value: value})
+}
+
+func (d *differ) diffObject(oldObj, newObj sx.Object, path []int) {
+	if oldObj.IsEqual(newObj) {
+		return
+	}
+	oldNode, isOldPair := sx.GetPair(oldObj)
+	newNode, isNewPair := sx.GetPair(newObj)
+	if isOldPair && isNewPair {
+		d.diffNode(oldNode, newNode, path)
+	} else {
+		d.add(KindReplace, path, newObj)
+	}
+}
+
+func (d *differ) diffNode(oldNode, newNode *sx.Pair, path []int) {
+	if oldNode.IsEqual(newNode) {
+		return
+	}
+	layout, ok := commonLayout(oldNode, newNode)
+	if !ok {
+		d.add(KindReplace, path, newNode)
+		return
+	}
+	oldElems, newElems := oldNode.Tail(), newNode.Tail()
+	for i, elem := range layout.Elements {
+		elemPath := subPath(path, i+1)
+		switch elem.Kind {
+		case zsx.ElementRest:
+			d.diffList(oldElems, newElems, path, i+1)
+			return
+		case zsx.ElementList:
+			oldLst, _ := sx.GetPair(oldElems.Car())
+			newLst, _ := sx.GetPair(newElems.Car())
+			d.diffList(oldLst, newLst, elemPath, 0)
+		case zsx.ElementNode:
+			d.diffObject(oldElems.Car(), newElems.Car(), elemPath)
+		case zsx.ElementAttrs:
+			if !oldElems.Car().IsEqual(newElems.Car()) {
+				d.add(KindAttrs, elemPath, newElems.Car())
+			}
+		default:
+			if !oldElems.Car().IsEqual(newElems.Car()) {
+				d.add(KindUpdate, elemPath, newElems.Car())
+			}
+		}
+		oldElems, newElems = oldElems.Tail(), newElems.Tail()
+	}
+}
+
+// commonLayout returns the layout of both nodes, if they have the same symbol
+// and if they conform to the layout of the symbol.
+func commonLayout(oldNode, newNode *sx.Pair) (zsx.Layout, bool) {
+	if oldNode == nil || newNode == nil {
+		return zsx.Layout{}, false
+	}
+	sym := zsx.NodeSymbol(oldNode)
+	if sym == nil || !sym.IsEqualSymbol(zsx.NodeSymbol(newNode)) {
+		return zsx.Layout{}, false
+	}
+	layout, found := zsx.GetLayout(sym)
+	if !found || !hasLayout(oldNode, layout) || !hasLayout(newNode, layout) {
+		return zsx.Layout{}, false
+	}
+	return layout, true
+}
+
+// hasLayout returns true, if the node has enough elements for the layout.
+func hasLayout(node *sx.Pair, layout zsx.Layout) bool {
+	length, numElems := node.Length()-1, len(layout.Elements)
+	if numElems > 0 && layout.Elements[numElems-1].Kind == zsx.ElementRest {
+		return length >= numElems-1
+	}
+	return length == numElems
+}
+
+// diffList computes the operations to transform the elements of the old list
+// into the elements of the new list. The elements start at index offset
+// within the list addressed by path.
+func (d *differ) diffList(oldLst, newLst *sx.Pair, path []int, offset int) {
+	oldObjs, newObjs := slices.Collect(oldLst.Values()), slices.Collect(newLst.Values())
+	match, moved := align(oldObjs, newObjs)
+
+	// cur simulates the list, while applying the operations. It contains
+	// the indices of the old objects, or -1 for an inserted object.
+	cur := make([]int, len(oldObjs))
+	used := make([]bool, len(oldObjs))
+	for i := range cur {
+		cur[i] = i
+	}
+	for _, i := range match {
+		if i >= 0 {
+			used[i] = true
+		}
+	}
+
+	// Delete from the end, so that the index of an old object is its position.
+	for i := len(oldObjs) - 1; i >= 0; i-- {
+		if !used[i] {
+			d.add(KindDelete, subPath(path, offset+i), nil)
+			cur = slices.Delete(cur, i, i+1)
+		}
+	}
+
+	// Move every moved object directly after its predecessor.
+	prev := -1
+	for j, i := range match {
+		if i < 0 {
+			continue
+		}
+		if moved[j] {
+			pos := slices.Index(cur, i)
+			cur = slices.Delete(cur, pos, pos+1)
+			target := 0
+			if prev >= 0 {
+				target = slices.Index(cur, prev) + 1
+			}
+			cur = slices.Insert(cur, target, i)
+			if pos != target {
+				d.ops = append(d.ops, Op{Kind: KindMove, Path: subPath(path, offset+pos), To: subPath(path, offset+target)})
+			}
+		}
+		prev = i
+	}
+
+	// Now all remaining objects are in the right order.
+	for j, i := range match {
+		if i < 0 {
+			d.add(KindInsert, subPath(path, offset+j), newObjs[j])
+		}
+	}
+	for j, i := range match {
+		if i >= 0 && !moved[j] {
+			d.diffObject(oldObjs[i], newObjs[j], subPath(path, offset+j))
+		}
+	}
+}
+
+func subPath(path []int, index int) []int { return slices.Concat(path, []int{index}) }
+
+// align matches the objects of the new list with the objects of the old list.
+// For every new object, it returns the index of the matching old object, or
+// -1, and whether the object was moved.
+//
+// Equal objects that keep their order are matched first. Then, equal objects
+// that changed their order are matched as moved objects. Last, nodes with the
+// same symbol are matched, if they keep their order.
+func align(oldObjs, newObjs []sx.Object) ([]int, []bool) {
+	match, moved := make([]int, len(newObjs)), make([]bool, len(newObjs))
+	used := make([]bool, len(oldObjs))
+
+	// Longest common subsequence of equal objects.
+	lcs := make([][]int, len(oldObjs)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newObjs)+1)
+	}
+	for i := len(oldObjs) - 1; i >= 0; i-- {
+		for j := len(newObjs) - 1; j >= 0; j-- {
+			if oldObjs[i].IsEqual(newObjs[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	for j := range match {
+		match[j] = -1
+	}
+	for i, j := 0, 0; i < len(oldObjs) && j < len(newObjs); {
+		switch {
+		case oldObjs[i].IsEqual(newObjs[j]):
+			match[j], used[i] = i, true
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	// Moved objects.
+	for j, newObj := range newObjs {
+		if match[j] >= 0 {
+			continue
+		}
+		for i, oldObj := range oldObjs {
+			if !used[i] && oldObj.IsEqual(newObj) {
+				match[j], moved[j], used[i] = i, true, true
+				break
+			}
+		}
+	}
+
+	// Changed nodes within the gaps between the unchanged objects.
+	next := len(oldObjs)
+	nextAnchor := make([]int, len(newObjs))
+	for j := len(newObjs) - 1; j >= 0; j-- {
+		nextAnchor[j] = next
+		if match[j] >= 0 && !moved[j] {
+			next = match[j]
+		}
+	}
+	last := -1
+	for j, newObj := range newObjs {
+		if match[j] >= 0 {
+			if !moved[j] {
+				last = match[j]
+			}
+			continue
+		}
+		for i := last + 1; i < nextAnchor[j]; i++ {
+			if !used[i] && sameSymbol(oldObjs[i], newObj) {
+				match[j], used[i], last = i, true, i
+				break
+			}
+		}
+	}
+	return match, moved
+}
+
+// sameSymbol returns true, if both objects are nodes with the same symbol.
+func sameSymbol(oldObj, newObj sx.Object) bool {
+	oldNode, isOldPair := sx.GetPair(oldObj)
+	newNode, isNewPair := sx.GetPair(newObj)
+	if !isOldPair || !isNewPair || oldNode == nil || newNode == nil {
+		return false
+	}
+	sym := zsx.NodeSymbol(oldNode)
+	return sym != nil && sym.IsEqualSymbol(zsx.NodeSymbol(newNode))
+}
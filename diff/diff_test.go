@@ -0,0 +1,205 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package diff_test
+
+import (
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/diff"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/zmk"
+)
+
+func parse(src string) *sx.Pair { return zmk.ParseBlocks(input.NewInput([]byte(src))) }
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name     string
+		old, new string
+		exp      string
+	}{
+		{"equal", "a", "a", "()"},
+		{"text", "a", "b", `((UPDATE (1 1 1) "b"))`},
+		{"level", "=== A", "==== A", `((UPDATE (1 2) 2))`},
+		{"attrs", "=== A", "=== A {.x}", `((ATTRS (1 1) (("class" . "x"))))`},
+		{"delete", "a\n\nb", "b", `((DELETE (1)))`},
+		{"insert", "a", "a\n\nb", `((INSERT (2) (PARA (TEXT "b"))))`},
+		{"move", "a\n\nb\n\nc", "b\n\nc\n\na", `((MOVE (1) (3)))`},
+		{"move-front", "a\n\nb\n\nc", "c\n\na\n\nb", `((MOVE (3) (1)))`},
+		{"other", "a", "---", `((DELETE (1)) (INSERT (1) (THEMATIC ())))`},
+		{"inline", "a **b** c", "a **d** c", `((UPDATE (1 2 2 1) "d"))`},
+		{"item", "* a\n* b", "* a\n* c\n* d",
+			`((INSERT (1 4) (ITEM () (PARA (TEXT "d")))) (UPDATE (1 3 2 1 1) "c"))`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			oldNode, newNode := parse(tc.old), parse(tc.new)
+			d := diff.Compute(oldNode, newNode)
+			if got := d.Sx().String(); got != tc.exp {
+				t.Errorf("expected:\n%s\nbut got:\n%s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name     string
+		old, new string
+	}{
+		{"paras", "a\n\nb\n\nc\n\nd", "d\n\nb\n\ne\n\na\n\nc"},
+		{"inlines", "a **b** __c__ d", "__c__ a, ''e'' **b** f"},
+		{"lists", "* a\n* b\n** c\n* d", "# a\n* d\n* b\n** c\n** e"},
+		{"table", "|a|b\n|c|d", "|=a|b\n|d|c\n|e"},
+		{"region", ":::\na\n\nb\n:::", "::: {.x}\nb\n\nc\n:::"},
+		{"description", "; a\n: b\n; c\n: d", "; c\n: d\n; a\n: b\n: e"},
+		{"mixed", "=== A\nb **c**\n---\n* d", "---\n=== B\nb **e**\n* d\n* f"},
+		{"empty", "", "a\n\nb"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			oldNode, newNode := parse(tc.old), parse(tc.new)
+			oldString := oldNode.String()
+			d := diff.Compute(oldNode, newNode)
+			got, err := diff.Apply(oldNode, d)
+			if err != nil {
+				t.Fatalf("diff %v: %v", d.Sx(), err)
+			}
+			if !got.IsEqual(newNode) {
+				t.Errorf("diff %v\nexpected:\n%v\nbut got:\n%v", d.Sx(), newNode, got)
+			}
+			if s := oldNode.String(); s != oldString {
+				t.Errorf("old node was modified: %v, but was %v", s, oldString)
+			}
+
+			parsed, err := diff.Parse(d.Sx())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err = diff.Apply(oldNode, parsed); err != nil || !got.IsEqual(newNode) {
+				t.Errorf("parsed diff %v: error %v, got:\n%v", parsed.Sx(), err, got)
+			}
+
+			reverse := diff.Compute(newNode, oldNode)
+			if got, err = diff.Apply(newNode, reverse); err != nil || !got.IsEqual(oldNode) {
+				t.Errorf("reverse diff %v: error %v, got:\n%v", reverse.Sx(), err, got)
+			}
+		})
+	}
+}
+
+func TestApplyError(t *testing.T) {
+	t.Parallel()
+	node := parse("a")
+	testcases := []struct {
+		name string
+		op   diff.Op
+	}{
+		{"delete-range", diff.Op{Kind: diff.KindDelete, Path: []int{3}}},
+		{"delete-deep", diff.Op{Kind: diff.KindDelete, Path: []int{1, 1, 1, 1}}},
+		{"insert-range", diff.Op{Kind: diff.KindInsert, Path: []int{4}, Value: sx.Nil()}},
+		{"update-range", diff.Op{Kind: diff.KindUpdate, Path: []int{1, 5}, Value: sx.Nil()}},
+		{"move-target", diff.Op{Kind: diff.KindMove, Path: []int{1}, To: []int{3}}},
+		{"root", diff.Op{Kind: diff.KindReplace, Value: sx.MakeString("a")}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got, err := diff.Apply(node, diff.Diff{tc.op}); err == nil {
+				t.Errorf("error expected, but got %v", got)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+	sym := sx.MakeSymbol
+	path := func(indices ...int) *sx.Pair {
+		var lb sx.ListBuilder
+		for _, index := range indices {
+			lb.Add(sx.Int64(index))
+		}
+		return lb.List()
+	}
+	testcases := []struct {
+		name string
+		op   sx.Object
+	}{
+		{"atom", sx.MakeString("a")},
+		{"no-symbol", sx.MakeList(sx.MakeString("DELETE"), path(1))},
+		{"unknown", sx.MakeList(sym("COPY"), path(1), path(2))},
+		{"delete-args", sx.MakeList(sym("DELETE"), path(1), path(2))},
+		{"insert-args", sx.MakeList(sym("INSERT"), path(1))},
+		{"path", sx.MakeList(sym("DELETE"), sx.MakeString("1"))},
+		{"path-negative", sx.MakeList(sym("DELETE"), path(-1))},
+		{"path-string", sx.MakeList(sym("DELETE"), sx.MakeList(sx.MakeString("1")))},
+		{"path-empty", sx.MakeList(sym("DELETE"), sx.Nil())},
+		{"move-empty", sx.MakeList(sym("MOVE"), path(1), sx.Nil())},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d, err := diff.Parse(sx.MakeList(tc.op)); err == nil {
+				t.Errorf("error expected, but got %v", d.Sx())
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+	del := func(s string) string { return `(REGION-BLOCK (("class" . "zsx-delete")) (` + s + `))` }
+	ins := func(s string) string { return `(REGION-BLOCK (("class" . "zsx-insert")) (` + s + `))` }
+	testcases := []struct {
+		name     string
+		old, new string
+		exp      string
+	}{
+		{"equal", "a", "a", `(BLOCK (PARA (TEXT "a")))`},
+		{"text", "a", "b",
+			`(BLOCK (PARA (FORMAT-DELETE () (TEXT "a")) (FORMAT-INSERT () (TEXT "b"))))`},
+		{"inline", "a **b**", "a **c**",
+			`(BLOCK (PARA (TEXT "a ") (FORMAT-STRONG () (FORMAT-DELETE () (TEXT "b")) (FORMAT-INSERT () (TEXT "c")))))`},
+		{"blocks", "a\n\nb", "b\n\nc",
+			`(BLOCK ` + del(`(PARA (TEXT "a"))`) + ` (PARA (TEXT "b")) ` + ins(`(PARA (TEXT "c"))`) + `)`},
+		{"items", "* a\n* b", "* b\n* c",
+			`(BLOCK (UNORDERED () (ITEM () ` + del(`(PARA (TEXT "a"))`) + `) (ITEM () (PARA (TEXT "b"))) (ITEM () ` +
+				ins(`(PARA (TEXT "c"))`) + `)))`},
+		{"rows", "|a|b\n|c", "|a|b",
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b"))) ` +
+				`(ROW () (CELL () (FORMAT-DELETE () (TEXT "c"))) (CELL ()))))`},
+		{"attrs", "=== A", "=== A {.x}", `(BLOCK (HEADING (("class" . "x")) 1 (TEXT "A")))`},
+		{"level", "=== A", "==== A",
+			`(BLOCK ` + del(`(HEADING () 1 (TEXT "A"))`) + ` ` + ins(`(HEADING () 2 (TEXT "A"))`) + `)`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := diff.Changes(parse(tc.old), parse(tc.new)).String()
+			if got != tc.exp {
+				t.Errorf("expected:\n%s\nbut got:\n%s", tc.exp, got)
+			}
+		})
+	}
+	if got := diff.Changes(parse("a"), zsx.MakeInline()); !got.IsEqual(zsx.MakeInline()) {
+		t.Errorf("new node expected, but got %v", got)
+	}
+}
@@ -0,0 +1,287 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package diff
+
+import (
+	"fmt"
+
+	"t73f.de/r/sx"
+)
+
+// Apply the diff to the node and return the resulting node. The given node is
+// not modified.
+func Apply(node *sx.Pair, d Diff) (*sx.Pair, error) {
+	var root sx.Object = copyObject(node)
+	for _, op := range d {
+		var err error
+		if root, err = op.apply(root); err != nil {
+			return nil, fmt.Errorf("%v: %w", op, err)
+		}
+	}
+	result, isPair := sx.GetPair(root)
+	if !isPair {
+		return nil, fmt.Errorf("result is not a node: %v", root)
+	}
+	return result, nil
+}
+
+func (op Op) apply(root sx.Object) (sx.Object, error) {
+	switch op.Kind {
+	case KindInsert:
+		return insertAt(root, op.Path, copyObject(op.Value))
+	case KindDelete:
+		root, _, err := deleteAt(root, op.Path)
+		return root, err
+	case KindMove:
+		root, obj, err := deleteAt(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return insertAt(root, op.To, obj)
+	case KindReplace, KindAttrs, KindUpdate:
+		return setAt(root, op.Path, copyObject(op.Value))
+	default:
+		return nil, fmt.Errorf("unknown operation %v", op.Kind)
+	}
+}
+
+// locate returns the list cell that contains the object at the path.
+func locate(root sx.Object, path []int) (*sx.Pair, error) {
+	obj := root
+	var cell *sx.Pair
+	for _, index := range path {
+		lst, isPair := sx.GetPair(obj)
+		if !isPair {
+			return nil, fmt.Errorf("path %v: not a list: %v", path, obj)
+		}
+		if cell = nthCell(lst, index); cell == nil {
+			return nil, fmt.Errorf("path %v: index %d out of range", path, index)
+		}
+		obj = cell.Car()
+	}
+	return cell, nil
+}
+
+func nthCell(lst *sx.Pair, index int) *sx.Pair {
+	for range index {
+		if lst == nil {
+			return nil
+		}
+		lst = lst.Tail()
+	}
+	return lst
+}
+
+// setAt replaces the object at the path and returns the new root.
+func setAt(root sx.Object, path []int, obj sx.Object) (sx.Object, error) {
+	if len(path) == 0 {
+		return obj, nil
+	}
+	cell, err := locate(root, path)
+	if err != nil {
+		return nil, err
+	}
+	cell.SetCar(obj)
+	return root, nil
+}
+
+// listAt returns the list that contains the object at the path.
+func listAt(root sx.Object, path []int) (*sx.Pair, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	obj := root
+	if len(path) > 1 {
+		cell, err := locate(root, path[:len(path)-1])
+		if err != nil {
+			return nil, err
+		}
+		obj = cell.Car()
+	}
+	lst, isPair := sx.GetPair(obj)
+	if !isPair {
+		return nil, fmt.Errorf("path %v: not a list: %v", path, obj)
+	}
+	return lst, nil
+}
+
+// insertAt inserts the object into a list, so that it is at the path, and
+// returns the new root.
+func insertAt(root sx.Object, path []int, obj sx.Object) (sx.Object, error) {
+	lst, err := listAt(root, path)
+	if err != nil {
+		return nil, err
+	}
+	index := path[len(path)-1]
+	if index == 0 {
+		return setAt(root, path[:len(path)-1], lst.Cons(obj))
+	}
+	cell := nthCell(lst, index-1)
+	if cell == nil {
+		return nil, fmt.Errorf("path %v: index %d out of range", path, index)
+	}
+	cell.SetCdr(cell.Tail().Cons(obj))
+	return root, nil
+}
+
+// deleteAt removes the object at the path from its list, and returns the new
+// root and the removed object.
+func deleteAt(root sx.Object, path []int) (sx.Object, sx.Object, error) {
+	lst, err := listAt(root, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := path[len(path)-1]
+	if index == 0 {
+		if lst == nil {
+			return nil, nil, fmt.Errorf("path %v: index 0 out of range", path)
+		}
+		root, err = setAt(root, path[:len(path)-1], lst.Tail())
+		return root, lst.Car(), err
+	}
+	cell := nthCell(lst, index-1)
+	if cell == nil || cell.Tail() == nil {
+		return nil, nil, fmt.Errorf("path %v: index %d out of range", path, index)
+	}
+	obj := cell.Tail().Car()
+	cell.SetCdr(cell.Tail().Cdr())
+	return root, obj, nil
+}
+
+// copyObject returns a copy of all lists of the object, so that it can be
+// modified without changing the original.
+func copyObject(obj sx.Object) sx.Object {
+	lst, isPair := sx.GetPair(obj)
+	if !isPair || lst == nil {
+		return obj
+	}
+	return sx.Cons(copyObject(lst.Car()), copyObject(lst.Cdr()))
+}
+
+// Sx returns the diff as an sx list of operations. Every operation is a list
+// that starts with the name of its kind, followed by the path. KindMove is
+// followed by the target path, and all other kinds, except KindDelete, are
+// followed by the new value.
+func (d Diff) Sx() *sx.Pair {
+	var lb sx.ListBuilder
+	for _, op := range d {
+		lb.Add(op.Sx())
+	}
+	return lb.List()
+}
+
+// Sx returns the operation as an sx list, see [Diff.Sx].
+func (op Op) Sx() *sx.Pair {
+	lst := sx.MakeList(sx.MakeSymbol(op.Kind.String()), pathSx(op.Path))
+	switch op.Kind {
+	case KindDelete:
+	case KindMove:
+		lst.Tail().SetCdr(sx.Cons(pathSx(op.To), sx.Nil()))
+	default:
+		lst.Tail().SetCdr(sx.Cons(op.Value, sx.Nil()))
+	}
+	return lst
+}
+
+func (op Op) String() string { return op.Sx().String() }
+
+func pathSx(path []int) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, index := range path {
+		lb.Add(sx.Int64(index))
+	}
+	return lb.List()
+}
+
+// Parse returns the diff of the given sx list, as produced by [Diff.Sx].
+func Parse(lst *sx.Pair) (Diff, error) {
+	var d Diff
+	for obj := range lst.Values() {
+		op, err := parseOp(obj)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, op)
+	}
+	return d, nil
+}
+
+func parseOp(obj sx.Object) (Op, error) {
+	lst, isPair := sx.GetPair(obj)
+	if !isPair || lst == nil {
+		return Op{}, fmt.Errorf("operation must be a list: %v", obj)
+	}
+	sym, isSymbol := sx.GetSymbol(lst.Car())
+	if !isSymbol {
+		return Op{}, fmt.Errorf("operation must start with a symbol: %v", lst)
+	}
+	kind, found := parseKind(sym.GetValue())
+	if !found {
+		return Op{}, fmt.Errorf("unknown operation: %v", lst)
+	}
+	numArgs := 2
+	if kind == KindDelete {
+		numArgs = 1
+	}
+	if lst.Length() != numArgs+1 {
+		return Op{}, fmt.Errorf("%v needs %d arguments: %v", kind, numArgs, lst)
+	}
+	path, err := parsePath(lst.Tail().Car())
+	if err != nil {
+		return Op{}, fmt.Errorf("%v: %w", lst, err)
+	}
+	op := Op{Kind: kind, Path: path}
+	switch kind {
+	case KindDelete:
+	case KindMove:
+		if op.To, err = parsePath(lst.Tail().Tail().Car()); err != nil {
+			return Op{}, fmt.Errorf("%v: %w", lst, err)
+		}
+		if len(op.To) == 0 {
+			return Op{}, fmt.Errorf("%v: empty target path", lst)
+		}
+	default:
+		op.Value = lst.Tail().Tail().Car()
+	}
+	if len(op.Path) == 0 && kind != KindReplace {
+		return Op{}, fmt.Errorf("%v: empty path", lst)
+	}
+	return op, nil
+}
+
+func parseKind(s string) (Kind, bool) {
+	for k, name := range kindNames {
+		if name == s {
+			return Kind(k), true
+		}
+	}
+	return 0, false
+}
+
+func parsePath(obj sx.Object) ([]int, error) {
+	lst, isPair := sx.GetPair(obj)
+	if !isPair {
+		return nil, fmt.Errorf("path must be a list: %v", obj)
+	}
+	var path []int
+	for elem := range lst.Values() {
+		num, isNumber := sx.GetNumber(elem)
+		index, isInt := num.(sx.Int64)
+		if !isNumber || !isInt || index < 0 {
+			return nil, fmt.Errorf("path must contain non-negative integers: %v", lst)
+		}
+		path = append(path, int(index))
+	}
+	return path, nil
+}
@@ -0,0 +1,357 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx
+
+import "t73f.de/r/sx"
+
+// Dispatch returns a [Visitor] that calls a node specific method of the
+// given handler before the child nodes of a node are traversed.
+//
+// For example, if the handler implements [HeadingVisitor], its method
+// VisitHeading is called for every HEADING node, with the elements of the
+// node as retrieved by [GetHeading]. The node specific method has the same
+// semantic as VisitBefore: if it returns a true value, the returned object is
+// the result and the child nodes are not traversed. For all other nodes, the
+// VisitBefore method of the handler is called. VisitAfter of the handler is
+// called as usual.
+func Dispatch(h Visitor) Visitor {
+	d := dispatcher{h: h, funcs: map[*sx.Symbol]dispatchFunc{}}
+	if v, ok := h.(BlockVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) { return v.VisitBlock(GetBlock(n), a) }, SymBlock)
+	}
+	if v, ok := h.(InlineVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) { return v.VisitInline(GetInline(n), a) }, SymInline)
+	}
+	if v, ok := h.(ParaVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) { return v.VisitPara(GetPara(n), a) }, SymPara)
+	}
+	if v, ok := h.(HeadingVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, level, inlines := GetHeading(n)
+			return v.VisitHeading(attrs, level, inlines, a)
+		}, SymHeading)
+	}
+	if v, ok := h.(ThematicVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) { return v.VisitThematic(GetThematic(n), a) }, SymThematic)
+	}
+	if v, ok := h.(ListVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			sym, attrs, items := GetList(n)
+			return v.VisitList(sym, attrs, items, a)
+		}, SymListOrdered, SymListUnordered, SymListQuote)
+	}
+	if v, ok := h.(ListItemVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, blocks := GetListItem(n)
+			return v.VisitListItem(attrs, blocks, a)
+		}, SymListItem)
+	}
+	if v, ok := h.(DescriptionVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, elems := GetDescription(n)
+			return v.VisitDescription(attrs, elems, a)
+		}, SymDescription)
+	}
+	if v, ok := h.(TermVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, inlines := GetTerm(n)
+			return v.VisitTerm(attrs, inlines, a)
+		}, SymTerm)
+	}
+	if v, ok := h.(DetailVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) { return v.VisitDetail(GetDetail(n), a) }, SymDetail)
+	}
+	if v, ok := h.(EntryVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, blocks := GetEntry(n)
+			return v.VisitEntry(attrs, blocks, a)
+		}, SymEntry)
+	}
+	if v, ok := h.(TableVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, header, rows := GetTable(n)
+			return v.VisitTable(attrs, header, rows, a)
+		}, SymTable)
+	}
+	if v, ok := h.(RowVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, cells := GetRow(n)
+			return v.VisitRow(attrs, cells, a)
+		}, SymRow)
+	}
+	if v, ok := h.(CellVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, inlines := GetCell(n)
+			return v.VisitCell(attrs, inlines, a)
+		}, SymCell)
+	}
+	if v, ok := h.(RegionVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			sym, attrs, blocks, inlines := GetRegion(n)
+			return v.VisitRegion(sym, attrs, blocks, inlines, a)
+		}, SymRegionBlock, SymRegionQuote, SymRegionVerse)
+	}
+	if v, ok := h.(VerbatimVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			sym, attrs, content := GetVerbatim(n)
+			return v.VisitVerbatim(sym, attrs, content, a)
+		}, SymVerbatimCode, SymVerbatimComment, SymVerbatimEval, SymVerbatimHTML, SymVerbatimMath, SymVerbatimZettel)
+	}
+	if v, ok := h.(TransclusionVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, ref, inlines := GetTransclusion(n)
+			return v.VisitTransclusion(attrs, ref, inlines, a)
+		}, SymTransclude)
+	}
+	if v, ok := h.(BLOBVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, syntax, data, inlines := GetBLOB(n)
+			return v.VisitBLOB(attrs, syntax, data, inlines, a)
+		}, SymBLOB)
+	}
+	if v, ok := h.(TextVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) { return v.VisitText(GetText(n), a) }, SymText)
+	}
+	if v, ok := h.(SoftVisitor); ok {
+		d.add(func(_, a *sx.Pair) (sx.Object, bool) { return v.VisitSoft(a) }, SymSoft)
+	}
+	if v, ok := h.(HardVisitor); ok {
+		d.add(func(_, a *sx.Pair) (sx.Object, bool) { return v.VisitHard(a) }, SymHard)
+	}
+	if v, ok := h.(LinkVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, ref, inlines := GetLink(n)
+			return v.VisitLink(attrs, ref, inlines, a)
+		}, SymLink)
+	}
+	if v, ok := h.(EmbedVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, ref, syntax, inlines := GetEmbed(n)
+			return v.VisitEmbed(attrs, ref, syntax, inlines, a)
+		}, SymEmbed)
+	}
+	if v, ok := h.(EmbedBLOBVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, syntax, data, inlines := GetEmbedBLOB(n)
+			return v.VisitEmbedBLOB(attrs, syntax, data, inlines, a)
+		}, SymEmbedBLOB)
+	}
+	if v, ok := h.(CiteVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, key, inlines := GetCite(n)
+			return v.VisitCite(attrs, key, inlines, a)
+		}, SymCite)
+	}
+	if v, ok := h.(EndnoteVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, inlines := GetEndnote(n)
+			return v.VisitEndnote(attrs, inlines, a)
+		}, SymEndnote)
+	}
+	if v, ok := h.(MarkVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			attrs, mark, inlines := GetMark(n)
+			return v.VisitMark(attrs, mark, inlines, a)
+		}, SymMark)
+	}
+	if v, ok := h.(FormatVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			sym, attrs, inlines := GetFormat(n)
+			return v.VisitFormat(sym, attrs, inlines, a)
+		}, SymFormatDelete, SymFormatEmph, SymFormatInsert, SymFormatMark, SymFormatQuote,
+			SymFormatSpan, SymFormatStrong, SymFormatSub, SymFormatSuper)
+	}
+	if v, ok := h.(LiteralVisitor); ok {
+		d.add(func(n, a *sx.Pair) (sx.Object, bool) {
+			sym, attrs, content := GetLiteral(n)
+			return v.VisitLiteral(sym, attrs, content, a)
+		}, SymLiteralCode, SymLiteralComment, SymLiteralInput, SymLiteralMath, SymLiteralOutput)
+	}
+	return &d
+}
+
+type dispatchFunc func(node *sx.Pair, alst *sx.Pair) (sx.Object, bool)
+
+type dispatcher struct {
+	h     Visitor
+	funcs map[*sx.Symbol]dispatchFunc
+}
+
+func (d *dispatcher) add(fn dispatchFunc, syms ...*sx.Symbol) {
+	for _, sym := range syms {
+		d.funcs[sym] = fn
+	}
+}
+
+func (d *dispatcher) VisitBefore(node *sx.Pair, alst *sx.Pair) (sx.Object, bool) {
+	if fn, found := d.funcs[NodeSymbol(node)]; found {
+		return fn(node, alst)
+	}
+	return d.h.VisitBefore(node, alst)
+}
+
+func (d *dispatcher) VisitAfter(node *sx.Pair, alst *sx.Pair) sx.Object {
+	return d.h.VisitAfter(node, alst)
+}
+
+// The following interfaces specify the node specific methods that are called
+// by the visitor returned by [Dispatch]. Besides the elements of the node,
+// each method receives the association list of the walking process.
+type (
+	// BlockVisitor is called for BLOCK nodes.
+	BlockVisitor interface {
+		VisitBlock(blocks, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// InlineVisitor is called for INLINE nodes.
+	InlineVisitor interface {
+		VisitInline(inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// ParaVisitor is called for PARA nodes.
+	ParaVisitor interface {
+		VisitPara(inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// HeadingVisitor is called for HEADING nodes.
+	HeadingVisitor interface {
+		VisitHeading(attrs *sx.Pair, level int, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// ThematicVisitor is called for THEMATIC nodes.
+	ThematicVisitor interface {
+		VisitThematic(attrs, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// ListVisitor is called for ORDERED, UNORDERED, and QUOTATION nodes.
+	ListVisitor interface {
+		VisitList(sym *sx.Symbol, attrs, items, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// ListItemVisitor is called for ITEM nodes.
+	ListItemVisitor interface {
+		VisitListItem(attrs, blocks, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// DescriptionVisitor is called for DESCRIPTION nodes.
+	DescriptionVisitor interface {
+		VisitDescription(attrs, elems, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// TermVisitor is called for TERM nodes.
+	TermVisitor interface {
+		VisitTerm(attrs, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// DetailVisitor is called for DETAIL nodes.
+	DetailVisitor interface {
+		VisitDetail(entries, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// EntryVisitor is called for ENTRY nodes.
+	EntryVisitor interface {
+		VisitEntry(attrs, blocks, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// TableVisitor is called for TABLE nodes.
+	TableVisitor interface {
+		VisitTable(attrs, header, rows, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// RowVisitor is called for ROW nodes.
+	RowVisitor interface {
+		VisitRow(attrs, cells, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// CellVisitor is called for CELL nodes.
+	CellVisitor interface {
+		VisitCell(attrs, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// RegionVisitor is called for REGION-BLOCK, REGION-QUOTE, and
+	// REGION-VERSE nodes.
+	RegionVisitor interface {
+		VisitRegion(sym *sx.Symbol, attrs, blocks, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// VerbatimVisitor is called for all VERBATIM-* nodes.
+	VerbatimVisitor interface {
+		VisitVerbatim(sym *sx.Symbol, attrs *sx.Pair, content string, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// TransclusionVisitor is called for TRANSCLUDE nodes.
+	TransclusionVisitor interface {
+		VisitTransclusion(attrs, ref, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// BLOBVisitor is called for BLOB nodes.
+	BLOBVisitor interface {
+		VisitBLOB(attrs *sx.Pair, syntax string, data []byte, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// TextVisitor is called for TEXT nodes.
+	TextVisitor interface {
+		VisitText(text string, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// SoftVisitor is called for SOFT nodes.
+	SoftVisitor interface {
+		VisitSoft(alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// HardVisitor is called for HARD nodes.
+	HardVisitor interface {
+		VisitHard(alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// LinkVisitor is called for LINK nodes.
+	LinkVisitor interface {
+		VisitLink(attrs, ref, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// EmbedVisitor is called for EMBED nodes.
+	EmbedVisitor interface {
+		VisitEmbed(attrs, ref *sx.Pair, syntax string, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// EmbedBLOBVisitor is called for EMBED-BLOB nodes.
+	EmbedBLOBVisitor interface {
+		VisitEmbedBLOB(attrs *sx.Pair, syntax string, data []byte, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// CiteVisitor is called for CITE nodes.
+	CiteVisitor interface {
+		VisitCite(attrs *sx.Pair, key string, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// EndnoteVisitor is called for ENDNOTE nodes.
+	EndnoteVisitor interface {
+		VisitEndnote(attrs, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// MarkVisitor is called for MARK nodes.
+	MarkVisitor interface {
+		VisitMark(attrs *sx.Pair, mark string, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// FormatVisitor is called for all FORMAT-* nodes.
+	FormatVisitor interface {
+		VisitFormat(sym *sx.Symbol, attrs, inlines, alst *sx.Pair) (sx.Object, bool)
+	}
+
+	// LiteralVisitor is called for all LITERAL-* nodes.
+	LiteralVisitor interface {
+		VisitLiteral(sym *sx.Symbol, attrs *sx.Pair, content string, alst *sx.Pair) (sx.Object, bool)
+	}
+)
@@ -0,0 +1,79 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// dispatchTestHandler increments the level of all headings, replaces the
+// content of all links by their reference value, and records all other
+// nodes.
+type dispatchTestHandler struct {
+	visited []string
+}
+
+func (h *dispatchTestHandler) VisitBefore(node *sx.Pair, _ *sx.Pair) (sx.Object, bool) {
+	h.visited = append(h.visited, zsx.NodeSymbol(node).GetValue())
+	return sx.Nil(), false
+}
+func (*dispatchTestHandler) VisitAfter(node *sx.Pair, _ *sx.Pair) sx.Object { return node }
+
+func (h *dispatchTestHandler) VisitHeading(attrs *sx.Pair, level int, inlines, alst *sx.Pair) (sx.Object, bool) {
+	h.visited = append(h.visited, fmt.Sprintf("HEADING-%d", level))
+	return zsx.MakeHeading(attrs, level+1, inlines), true
+}
+
+func (h *dispatchTestHandler) VisitLink(attrs, ref, _, alst *sx.Pair) (sx.Object, bool) {
+	_, val := zsx.GetReference(ref)
+	h.visited = append(h.visited, "LINK-"+val)
+	return zsx.MakeLink(attrs, ref, sx.MakeList(zsx.MakeText(val))), true
+}
+
+func (h *dispatchTestHandler) VisitText(text string, _ *sx.Pair) (sx.Object, bool) {
+	h.visited = append(h.visited, "TEXT-"+text)
+	return zsx.MakeText(strings.ToUpper(text)), true
+}
+
+func (h *dispatchTestHandler) VisitFormat(sym *sx.Symbol, _, _, _ *sx.Pair) (sx.Object, bool) {
+	h.visited = append(h.visited, sym.GetValue())
+	return sx.Nil(), false
+}
+
+func TestDispatch(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeBlock(
+		zsx.MakeHeading(nil, 1, sx.MakeList(zsx.MakeText("a"))),
+		zsx.MakePara(
+			zsx.MakeLink(nil, zsx.ParseReference("b"), sx.MakeList(zsx.MakeText("c"))),
+			zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(zsx.MakeText("d"))),
+			zsx.MakeSoft(),
+		),
+	)
+	var h dispatchTestHandler
+	obj := zsx.Walk(zsx.Dispatch(&h), node, nil)
+	exp := `(BLOCK (HEADING () 2 (TEXT "a")) (PARA (LINK () (HOSTED "b") (TEXT "b")) (FORMAT-EMPH () (TEXT "D")) (SOFT)))`
+	if got := obj.String(); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+	expVisited := "BLOCK HEADING-1 PARA LINK-b FORMAT-EMPH TEXT-d SOFT"
+	if got := strings.Join(h.visited, " "); got != expVisited {
+		t.Errorf("\nexp: %s\ngot: %s", expVisited, got)
+	}
+}
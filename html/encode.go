@@ -0,0 +1,563 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package html
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// Encode writes the given node as HTML5 to the writer. Typically, the node
+// is a BLOCK or an INLINE node. All endnotes are collected and written as an
+// ordered list after the encoded node.
+//
+// Attributes are written as HTML attributes, with the exception of event
+// handlers. The default attribute "" is written as a class, the "align"
+// attribute is written as a style. BLOBs, including SVG data, are written as
+// data URLs of an image element, so that scripts within SVG data are not
+// executed. Verbatim HTML is not written as is, but is encoded
+// after parsing it as a safe subset of HTML. For custom nodes, registered with
+// [zsx.RegisterLayout], only their child nodes are written.
+func Encode(w io.Writer, node *sx.Pair) error {
+	enc := encoder{w: w}
+	zsx.WalkIt(&enc, node, nil)
+	enc.writeEndnotes()
+	return enc.err
+}
+
+type encoder struct {
+	w        io.Writer
+	err      error
+	endnotes []*sx.Pair // Collected endnotes
+	inVerse  bool       // Soft line breaks are written as hard line breaks
+}
+
+// simpleTags maps symbols of nodes with attributes, which are just a HTML
+// element around the child nodes. An empty tag denotes a node without
+// attributes, where only the child nodes are written.
+var simpleTags map[*sx.Symbol]string
+
+// encodeFuncs maps symbols of nodes that need a special encoding. Child nodes
+// are not walked automatically.
+var encodeFuncs map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair)
+
+func init() {
+	simpleTags = map[*sx.Symbol]string{
+		zsx.SymBlock:         "",
+		zsx.SymInline:        "",
+		zsx.SymDetail:        "",
+		zsx.SymListOrdered:   "ol",
+		zsx.SymListUnordered: "ul",
+		zsx.SymListItem:      "li",
+		zsx.SymDescription:   "dl",
+		zsx.SymTerm:          "dt",
+		zsx.SymEntry:         "dd",
+		zsx.SymFormatDelete:  "del",
+		zsx.SymFormatEmph:    "em",
+		zsx.SymFormatInsert:  "ins",
+		zsx.SymFormatMark:    "mark",
+		zsx.SymFormatQuote:   "q",
+		zsx.SymFormatSpan:    "span",
+		zsx.SymFormatStrong:  "strong",
+		zsx.SymFormatSub:     "sub",
+		zsx.SymFormatSuper:   "sup",
+	}
+	encodeFuncs = map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair){
+		zsx.SymPara:            (*encoder).encodePara,
+		zsx.SymHeading:         (*encoder).encodeHeading,
+		zsx.SymThematic:        (*encoder).encodeThematic,
+		zsx.SymListQuote:       (*encoder).encodeQuotation,
+		zsx.SymRegionBlock:     (*encoder).encodeRegion,
+		zsx.SymRegionQuote:     (*encoder).encodeRegion,
+		zsx.SymRegionVerse:     (*encoder).encodeRegion,
+		zsx.SymVerbatimCode:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimComment: (*encoder).encodeVerbatim,
+		zsx.SymVerbatimEval:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimHTML:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimMath:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimZettel:  (*encoder).encodeVerbatim,
+		zsx.SymTable:           (*encoder).encodeTable,
+		zsx.SymTransclude:      (*encoder).encodeTransclusion,
+		zsx.SymBLOB:            (*encoder).encodeBLOB,
+		zsx.SymText:            (*encoder).encodeText,
+		zsx.SymSoft:            (*encoder).encodeSoft,
+		zsx.SymHard:            (*encoder).encodeHard,
+		zsx.SymLink:            (*encoder).encodeLink,
+		zsx.SymEmbed:           (*encoder).encodeEmbed,
+		zsx.SymEmbedBLOB:       (*encoder).encodeEmbedBLOB,
+		zsx.SymCite:            (*encoder).encodeCite,
+		zsx.SymEndnote:         (*encoder).encodeEndnote,
+		zsx.SymMark:            (*encoder).encodeMark,
+		zsx.SymLiteralCode:     (*encoder).encodeLiteral,
+		zsx.SymLiteralComment:  (*encoder).encodeLiteral,
+		zsx.SymLiteralInput:    (*encoder).encodeLiteral,
+		zsx.SymLiteralMath:     (*encoder).encodeLiteral,
+		zsx.SymLiteralOutput:   (*encoder).encodeLiteral,
+		zsx.SymSpecialSplice:   (*encoder).encodeSplice,
+	}
+}
+
+func (enc *encoder) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	sym := zsx.NodeSymbol(node)
+	if tag, found := simpleTags[sym]; found {
+		if tag != "" {
+			enc.writeStartTag(tag, getAttributes(node.Tail().Head()))
+		}
+		return false
+	}
+	if fn, found := encodeFuncs[sym]; found {
+		fn(enc, node, alst)
+		return true
+	}
+	// Only the child nodes of a custom node are written.
+	_, found := zsx.GetLayout(sym)
+	return !found
+}
+
+func (enc *encoder) VisitItAfter(node *sx.Pair, _ *sx.Pair) {
+	if tag := simpleTags[zsx.NodeSymbol(node)]; tag != "" {
+		enc.writeEndTag(tag)
+	}
+}
+
+// encodeSplice writes all spliced nodes, as if they were not spliced.
+func (enc *encoder) encodeSplice(node *sx.Pair, alst *sx.Pair) { zsx.WalkItList(enc, node, 1, alst) }
+
+func (enc *encoder) write(s string) {
+	if enc.err == nil {
+		_, enc.err = io.WriteString(enc.w, s)
+	}
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+func (enc *encoder) writeEscaped(s string) { enc.write(textEscaper.Replace(s)) }
+
+// writeStartTag writes the start tag of an element, together with its
+// attributes.
+func (enc *encoder) writeStartTag(tag string, a zsx.Attributes) {
+	enc.write("<")
+	enc.write(tag)
+	enc.writeAttributes(a)
+	enc.write(">")
+}
+
+func (enc *encoder) writeEndTag(tag string) {
+	enc.write("</")
+	enc.write(tag)
+	enc.write(">")
+}
+
+// getAttributes returns the attributes of a node that are allowed in the
+// output. The default attribute and the alignment are kept, because they are
+// translated by writeAttributes. All other attributes the encoder writes, like
+// URLs, are added explicitly.
+func getAttributes(attrs *sx.Pair) zsx.Attributes {
+	var a zsx.Attributes
+	alignKey := zsx.SymAttrAlign.GetValue()
+	for key, val := range zsx.GetAttributes(attrs) {
+		if key == "" || key == alignKey || isAllowedAttribute(key) {
+			a = a.Set(key, val)
+		}
+	}
+	return a
+}
+
+// writeAttributes writes the attributes in the order of their keys.
+func (enc *encoder) writeAttributes(a zsx.Attributes) {
+	if a.IsEmpty() {
+		return
+	}
+	a = a.Clone()
+	a.CleanSpecial()
+	a.RemoveDefault()
+	if class, found := a.Get(""); found {
+		a.Remove("")
+		if class != "" {
+			a = a.AddClass(class)
+		}
+	}
+	alignKey := zsx.SymAttrAlign.GetValue()
+	if align, found := a.Get(alignKey); found {
+		a.Remove(alignKey)
+		switch align {
+		case zsx.AttrAlignLeft.GetValue(), zsx.AttrAlignCenter.GetValue(), zsx.AttrAlignRight.GetValue():
+			a = a.Set("style", "text-align:"+align)
+		}
+	}
+	for _, key := range a.Keys() {
+		if !isAttributeName(key) {
+			continue
+		}
+		enc.write(" ")
+		enc.write(key)
+		enc.write(`="`)
+		enc.write(attrEscaper.Replace(a[key]))
+		enc.write(`"`)
+	}
+}
+
+// isAttributeName returns true, if the key is a valid name of an attribute,
+// which is not an event handler.
+func isAttributeName(key string) bool {
+	if key == "" || strings.HasPrefix(strings.ToLower(key), "on") {
+		return false
+	}
+	for _, ch := range key {
+		if !isNameChar(ch) && ch != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func (enc *encoder) walkInlines(ins *sx.Pair, alst *sx.Pair) { zsx.WalkItList(enc, ins, 0, alst) }
+
+func (enc *encoder) encodePara(node *sx.Pair, alst *sx.Pair) {
+	enc.write("<p>")
+	enc.walkInlines(zsx.GetPara(node), alst)
+	enc.write("</p>")
+}
+
+func (enc *encoder) encodeHeading(node *sx.Pair, alst *sx.Pair) {
+	attrs, level, ins := zsx.GetHeading(node)
+	tag := "h" + strconv.Itoa(min(max(level, 1), 6))
+	enc.writeStartTag(tag, getAttributes(attrs))
+	enc.walkInlines(ins, alst)
+	enc.writeEndTag(tag)
+}
+
+func (enc *encoder) encodeThematic(node *sx.Pair, _ *sx.Pair) {
+	enc.writeStartTag("hr", getAttributes(zsx.GetThematic(node)))
+}
+
+// encodeQuotation writes a quotation list as a block quote. The items of the
+// list are not marked.
+func (enc *encoder) encodeQuotation(node *sx.Pair, alst *sx.Pair) {
+	_, attrs, items := zsx.GetList(node)
+	enc.writeStartTag("blockquote", getAttributes(attrs))
+	for item := range items.Values() {
+		if itemNode, isPair := sx.GetPair(item); isPair {
+			_, elems := zsx.GetListItem(itemNode)
+			zsx.WalkItList(enc, elems, 0, alst)
+		}
+	}
+	enc.writeEndTag("blockquote")
+}
+
+func (enc *encoder) encodeRegion(node *sx.Pair, alst *sx.Pair) {
+	sym, attrs, blocks, ins := zsx.GetRegion(node)
+	a := getAttributes(attrs)
+	tag := "div"
+	switch sym {
+	case zsx.SymRegionQuote:
+		tag = "blockquote"
+	case zsx.SymRegionVerse:
+		a = a.AddClass("zs-verse")
+	}
+	enc.writeStartTag(tag, a)
+	inVerse := enc.inVerse
+	enc.inVerse = inVerse || sym == zsx.SymRegionVerse
+	zsx.WalkItList(enc, blocks, 0, alst)
+	enc.inVerse = inVerse
+	if ins != nil {
+		enc.write("<p><cite>")
+		enc.walkInlines(ins, alst)
+		enc.write("</cite></p>")
+	}
+	enc.writeEndTag(tag)
+}
+
+// verbatimClasses contains the additional classes of verbatim nodes.
+var verbatimClasses = map[*sx.Symbol]string{
+	zsx.SymVerbatimEval:   "zs-eval",
+	zsx.SymVerbatimMath:   "zs-math",
+	zsx.SymVerbatimZettel: "zs-zettel",
+}
+
+func (enc *encoder) encodeVerbatim(node *sx.Pair, _ *sx.Pair) {
+	sym, attrs, content := zsx.GetVerbatim(node)
+	switch sym {
+	case zsx.SymVerbatimComment:
+		enc.writeComment(content)
+		return
+	case zsx.SymVerbatimHTML:
+		zsx.WalkIt(enc, ParseBlocks(input.NewInput([]byte(content))), nil)
+		return
+	}
+	a, lang := splitLanguage(getAttributes(attrs))
+	if class := verbatimClasses[sym]; class != "" {
+		a = a.AddClass(class)
+	}
+	enc.writeStartTag("pre", a)
+	enc.writeStartTag("code", lang)
+	enc.writeEscaped(content)
+	enc.write("</code></pre>")
+}
+
+// splitLanguage removes the default attribute "", which specifies the
+// language of some code, and returns it as a class attribute.
+func splitLanguage(a zsx.Attributes) (zsx.Attributes, zsx.Attributes) {
+	lang, found := a.Get("")
+	if !found {
+		return a, nil
+	}
+	a = a.Clone().Remove("")
+	if lang == "" {
+		return a, nil
+	}
+	return a, zsx.Attributes{"class": "language-" + lang}
+}
+
+// writeComment writes the content as a HTML comment. Double hyphens are
+// separated, because they would end the comment.
+func (enc *encoder) writeComment(content string) {
+	for strings.Contains(content, "--") {
+		content = strings.ReplaceAll(content, "--", "- -")
+	}
+	enc.write("<!-- ")
+	enc.write(content)
+	enc.write(" -->")
+}
+
+func (enc *encoder) encodeTable(node *sx.Pair, alst *sx.Pair) {
+	attrs, header, rows := zsx.GetTable(node)
+	enc.writeStartTag("table", getAttributes(attrs))
+	if header != nil {
+		enc.write("<thead>")
+		enc.encodeRow(header, "th", alst)
+		enc.write("</thead>")
+	}
+	if rows != nil {
+		enc.write("<tbody>")
+		for row := range rows.Values() {
+			if rowNode, isPair := sx.GetPair(row); isPair {
+				enc.encodeRow(rowNode, "td", alst)
+			}
+		}
+		enc.write("</tbody>")
+	}
+	enc.write("</table>")
+}
+
+func (enc *encoder) encodeRow(row *sx.Pair, tag string, alst *sx.Pair) {
+	attrs, cells := zsx.GetRow(row)
+	enc.writeStartTag("tr", getAttributes(attrs))
+	for cell := range cells.Values() {
+		if cellNode, isPair := sx.GetPair(cell); isPair {
+			cellAttrs, ins := zsx.GetCell(cellNode)
+			enc.writeStartTag(tag, getAttributes(cellAttrs))
+			enc.walkInlines(ins, alst)
+			enc.writeEndTag(tag)
+		}
+	}
+	enc.write("</tr>")
+}
+
+// encodeTransclusion writes a link to the referenced material, because it
+// cannot be retrieved by the encoder.
+func (enc *encoder) encodeTransclusion(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, ins := zsx.GetTransclusion(node)
+	enc.write("<p>")
+	enc.writeLink(getAttributes(attrs), ref, ins, alst)
+	enc.write("</p>")
+}
+
+func (enc *encoder) encodeBLOB(node *sx.Pair, _ *sx.Pair) {
+	attrs, syntax, data, description := zsx.GetBLOBuncode(node)
+	enc.writeStartTag("figure", getAttributes(attrs))
+	enc.writeImage(nil, zsx.DataURL(syntax, data), description)
+	if description != nil {
+		enc.write("<figcaption>")
+		enc.walkInlines(description, nil)
+		enc.write("</figcaption>")
+	}
+	enc.write("</figure>")
+}
+
+// writeImage writes an image element. The plain text of the inline nodes is
+// used as the alternative text.
+func (enc *encoder) writeImage(a zsx.Attributes, src string, ins *sx.Pair) {
+	a = a.Clone().Set("src", src).Set("alt", plainText(ins))
+	enc.writeStartTag("img", a)
+}
+
+func (enc *encoder) encodeText(node *sx.Pair, _ *sx.Pair) { enc.writeEscaped(zsx.GetText(node)) }
+
+func (enc *encoder) encodeSoft(*sx.Pair, *sx.Pair) {
+	if enc.inVerse {
+		enc.write("<br>")
+	} else {
+		enc.write("\n")
+	}
+}
+
+func (enc *encoder) encodeHard(*sx.Pair, *sx.Pair) { enc.write("<br>") }
+
+func (enc *encoder) encodeLink(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, ins := zsx.GetLink(node)
+	enc.writeLink(getAttributes(attrs), ref, ins, alst)
+}
+
+// writeLink writes a link to the reference. If there are no inline nodes,
+// the reference value is used as text. Invalid and unsafe references are
+// written as a span element.
+func (enc *encoder) writeLink(a zsx.Attributes, ref *sx.Pair, ins *sx.Pair, alst *sx.Pair) {
+	sym, val := zsx.GetReference(ref)
+	tag := "a"
+	if sym == nil || sym == zsx.SymRefStateInvalid || !isSafeURL(val, false) {
+		tag = "span"
+	} else {
+		a = a.Clone().Set("href", val)
+	}
+	enc.writeStartTag(tag, a)
+	if ins == nil {
+		enc.writeEscaped(val)
+	} else {
+		enc.walkInlines(ins, alst)
+	}
+	enc.writeEndTag(tag)
+}
+
+func (enc *encoder) encodeEmbed(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, _, ins := zsx.GetEmbed(node)
+	a := getAttributes(attrs)
+	sym, val := zsx.GetReference(ref)
+	if sym == nil || sym == zsx.SymRefStateInvalid || !isSafeURL(val, true) {
+		enc.writeStartTag("span", a)
+		enc.walkInlines(ins, alst)
+		enc.write("</span>")
+		return
+	}
+	enc.writeImage(a, val, ins)
+}
+
+func (enc *encoder) encodeEmbedBLOB(node *sx.Pair, _ *sx.Pair) {
+	attrs, syntax, data, ins := zsx.GetEmbedBLOBuncode(node)
+	enc.writeImage(getAttributes(attrs), zsx.DataURL(syntax, data), ins)
+}
+
+func (enc *encoder) encodeCite(node *sx.Pair, alst *sx.Pair) {
+	attrs, key, ins := zsx.GetCite(node)
+	enc.writeStartTag("cite", getAttributes(attrs))
+	enc.writeEscaped(key)
+	if ins != nil {
+		enc.write(", ")
+		enc.walkInlines(ins, alst)
+	}
+	enc.write("</cite>")
+}
+
+// encodeEndnote writes a reference to the endnote. The endnote itself is
+// written after the encoded node.
+func (enc *encoder) encodeEndnote(node *sx.Pair, _ *sx.Pair) {
+	enc.endnotes = append(enc.endnotes, node)
+	num := strconv.Itoa(len(enc.endnotes))
+	enc.write(`<sup id="fnref-` + num + `"><a class="zs-noteref" href="#fn-` + num + `" role="doc-noteref">` + num + "</a></sup>")
+}
+
+// writeEndnotes writes all collected endnotes, including those that are
+// referenced within an endnote.
+func (enc *encoder) writeEndnotes() {
+	if len(enc.endnotes) == 0 {
+		return
+	}
+	enc.write(`<ol class="zs-endnotes">`)
+	for i := 0; i < len(enc.endnotes); i++ {
+		attrs, ins := zsx.GetEndnote(enc.endnotes[i])
+		num := strconv.Itoa(i + 1)
+		a := getAttributes(attrs).Clone().Set("id", "fn-"+num).Set("role", "doc-endnote").Set("value", num)
+		enc.writeStartTag("li", a)
+		enc.walkInlines(ins, nil)
+		enc.write(` <a class="zs-endnote-backref" href="#fnref-` + num + `" role="doc-backlink">&#x21a9;&#xfe0e;</a></li>`)
+	}
+	enc.write("</ol>")
+	enc.endnotes = nil
+}
+
+func (enc *encoder) encodeMark(node *sx.Pair, alst *sx.Pair) {
+	attrs, mark, ins := zsx.GetMark(node)
+	a := getAttributes(attrs)
+	if mark != "" {
+		a = a.Clone().Set("id", mark)
+	}
+	enc.writeStartTag("a", a)
+	enc.walkInlines(ins, alst)
+	enc.write("</a>")
+}
+
+// literalTags maps the symbols of literal nodes to HTML elements.
+var literalTags = map[*sx.Symbol]string{
+	zsx.SymLiteralCode:   "code",
+	zsx.SymLiteralInput:  "kbd",
+	zsx.SymLiteralMath:   "code",
+	zsx.SymLiteralOutput: "samp",
+}
+
+func (enc *encoder) encodeLiteral(node *sx.Pair, _ *sx.Pair) {
+	sym, attrs, text := zsx.GetLiteral(node)
+	if sym == zsx.SymLiteralComment {
+		enc.writeComment(text)
+		return
+	}
+	a := getAttributes(attrs)
+	if sym == zsx.SymLiteralCode {
+		var lang zsx.Attributes
+		a, lang = splitLanguage(a)
+		if class, found := lang.Get("class"); found {
+			a = a.Clone().AddClass(class)
+		}
+	} else if sym == zsx.SymLiteralMath {
+		a = a.Clone().AddClass("zs-math")
+	}
+	tag := literalTags[sym]
+	enc.writeStartTag(tag, a)
+	enc.writeEscaped(text)
+	enc.writeEndTag(tag)
+}
+
+// plainText returns the text of the inline nodes, without any formatting.
+// Endnotes and comments are ignored.
+func plainText(ins *sx.Pair) string {
+	var tv textVisitor
+	zsx.WalkItList(&tv, ins, 0, nil)
+	return tv.sb.String()
+}
+
+type textVisitor struct{ sb strings.Builder }
+
+func (tv *textVisitor) VisitItBefore(node *sx.Pair, _ *sx.Pair) bool {
+	switch sym := zsx.NodeSymbol(node); sym {
+	case zsx.SymText:
+		tv.sb.WriteString(zsx.GetText(node))
+	case zsx.SymSoft, zsx.SymHard:
+		tv.sb.WriteByte(' ')
+	case zsx.SymLiteralCode, zsx.SymLiteralInput, zsx.SymLiteralMath, zsx.SymLiteralOutput:
+		_, _, text := zsx.GetLiteral(node)
+		tv.sb.WriteString(text)
+	case zsx.SymEndnote:
+		return true
+	}
+	return false
+}
+
+func (*textVisitor) VisitItAfter(*sx.Pair, *sx.Pair) {}
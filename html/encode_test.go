@@ -0,0 +1,206 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package html_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/html"
+)
+
+type encodeCase struct {
+	name string
+	node *sx.Pair
+	exp  string
+}
+
+func checkEncode(t *testing.T, testcases []encodeCase) {
+	t.Helper()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := html.Encode(&sb, tc.node); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tc.exp {
+				t.Errorf("\nnode: %v\nexp:  %s\ngot:  %s", tc.node, tc.exp, got)
+			}
+		})
+	}
+}
+
+func text(s string) *sx.Pair { return zsx.MakeText(s) }
+
+func attrs(kv ...string) *sx.Pair {
+	var a zsx.Attributes
+	for i := 0; i+1 < len(kv); i += 2 {
+		a = a.Set(kv[i], kv[i+1])
+	}
+	return a.AsAssoc()
+}
+
+func TestEncodeBlocks(t *testing.T) {
+	t.Parallel()
+	checkEncode(t, []encodeCase{
+		{"empty", zsx.MakeBlock(), ""},
+		{"para", zsx.MakeBlock(zsx.MakePara(text("a<b"), zsx.MakeSoft(), text("c"), zsx.MakeHard(), text("d"))),
+			"<p>a&lt;b\nc<br>d</p>"},
+		{"heading", zsx.MakeBlock(zsx.MakeHeading(attrs("id", "x"), 2, sx.MakeList(text("H")))),
+			`<h2 id="x">H</h2>`},
+		{"heading-level", zsx.MakeBlock(zsx.MakeHeading(nil, 9, sx.MakeList(text("H")))), "<h6>H</h6>"},
+		{"thematic", zsx.MakeBlock(zsx.MakeThematic(attrs("class", "x"))), `<hr class="x">`},
+		{"lists", zsx.MakeBlock(
+			zsx.MakeList(zsx.SymListOrdered, attrs("start", "3"), sx.MakeList(
+				zsx.MakeListItem(nil, sx.MakeList(zsx.MakePara(text("a")))))),
+			zsx.MakeList(zsx.SymListUnordered, nil, sx.MakeList(
+				zsx.MakeListItem(nil, sx.MakeList(zsx.MakePara(text("b"))))))),
+			`<ol start="3"><li><p>a</p></li></ol><ul><li><p>b</p></li></ul>`},
+		{"quotation", zsx.MakeBlock(zsx.MakeList(zsx.SymListQuote, nil, sx.MakeList(
+			zsx.MakeListItem(nil, sx.MakeList(zsx.MakePara(text("a")))),
+			zsx.MakeListItem(nil, sx.MakeList(zsx.MakePara(text("b"))))))),
+			"<blockquote><p>a</p><p>b</p></blockquote>"},
+		{"description", zsx.MakeBlock(zsx.MakeDescription(nil, sx.MakeList(
+			zsx.MakeTerm(nil, sx.MakeList(text("t"))),
+			zsx.MakeDetail(sx.MakeList(zsx.MakeEntry(nil, sx.MakeList(zsx.MakePara(text("d"))))))))),
+			"<dl><dt>t</dt><dd><p>d</p></dd></dl>"},
+		{"table", zsx.MakeBlock(zsx.MakeTable(nil,
+			zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(nil, sx.MakeList(text("h"))))),
+			sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(sx.MakeList(sx.Cons(zsx.SymAttrAlign, zsx.AttrAlignRight)), sx.MakeList(text("1")))))))),
+			`<table><thead><tr><th>h</th></tr></thead><tbody><tr><td style="text-align:right">1</td></tr></tbody></table>`},
+		{"table-no-header", zsx.MakeBlock(zsx.MakeTable(nil, nil,
+			sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(nil, sx.MakeList(text("c")))))))),
+			`<table><tbody><tr><td>c</td></tr></tbody></table>`},
+		{"region", zsx.MakeBlock(zsx.MakeRegion(zsx.SymRegionBlock, attrs("", "note"),
+			sx.MakeList(zsx.MakePara(text("a"))), nil)),
+			`<div class="note"><p>a</p></div>`},
+		{"region-quote", zsx.MakeBlock(zsx.MakeRegion(zsx.SymRegionQuote, nil,
+			sx.MakeList(zsx.MakePara(text("a"))), sx.MakeList(text("me")))),
+			"<blockquote><p>a</p><p><cite>me</cite></p></blockquote>"},
+		{"region-verse", zsx.MakeBlock(zsx.MakeRegion(zsx.SymRegionVerse, nil,
+			sx.MakeList(zsx.MakePara(text("a"), zsx.MakeSoft(), text("b"))), nil)),
+			`<div class="zs-verse"><p>a<br>b</p></div>`},
+		{"code", zsx.MakeBlock(zsx.MakeVerbatim(zsx.SymVerbatimCode, attrs("", "go"), "a<b")),
+			`<pre><code class="language-go">a&lt;b</code></pre>`},
+		{"eval", zsx.MakeBlock(zsx.MakeVerbatim(zsx.SymVerbatimEval, nil, "e")),
+			`<pre class="zs-eval"><code>e</code></pre>`},
+		{"math", zsx.MakeBlock(zsx.MakeVerbatim(zsx.SymVerbatimMath, nil, "m")),
+			`<pre class="zs-math"><code>m</code></pre>`},
+		{"zettel", zsx.MakeBlock(zsx.MakeVerbatim(zsx.SymVerbatimZettel, nil, "z")),
+			`<pre class="zs-zettel"><code>z</code></pre>`},
+		{"comment", zsx.MakeBlock(zsx.MakeVerbatim(zsx.SymVerbatimComment, nil, "a-->b")),
+			"<!-- a- ->b -->"},
+		{"html", zsx.MakeBlock(zsx.MakeVerbatim(zsx.SymVerbatimHTML, nil, `<p onclick="x()">a<script>b</script></p>`)),
+			"<p>a</p>"},
+		{"transclude", zsx.MakeBlock(zsx.MakeTransclusion(nil, zsx.ParseReference("/z"), nil)),
+			`<p><a href="/z">/z</a></p>`},
+		{"blob", zsx.MakeBlock(zsx.MakeBLOB(nil, "png", []byte("abc"), sx.MakeList(text("d")))),
+			`<figure><img alt="d" src="data:image/png;base64,YWJj"><figcaption>d</figcaption></figure>`},
+		{"blob-svg", zsx.MakeBlock(zsx.MakeBLOB(attrs("class", "x"), zsx.SyntaxSVG, []byte("<svg></svg>"), nil)),
+			`<figure class="x"><img alt="" src="data:image/svg+xml;base64,PHN2Zz48L3N2Zz4="></figure>`},
+		{"splice", zsx.MakeBlock(sx.MakeList(zsx.SymSpecialSplice, zsx.MakePara(text("a")), zsx.MakePara(text("b")))),
+			"<p>a</p><p>b</p>"},
+	})
+}
+
+func TestEncodeInlines(t *testing.T) {
+	t.Parallel()
+	checkEncode(t, []encodeCase{
+		{"formats", zsx.MakeInline(
+			zsx.MakeFormat(zsx.SymFormatDelete, nil, sx.MakeList(text("d"))),
+			zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text("e"))),
+			zsx.MakeFormat(zsx.SymFormatInsert, nil, sx.MakeList(text("i"))),
+			zsx.MakeFormat(zsx.SymFormatMark, nil, sx.MakeList(text("m"))),
+			zsx.MakeFormat(zsx.SymFormatQuote, nil, sx.MakeList(text("q"))),
+			zsx.MakeFormat(zsx.SymFormatSpan, attrs("lang", "de"), sx.MakeList(text("s"))),
+			zsx.MakeFormat(zsx.SymFormatStrong, nil, sx.MakeList(text("b"))),
+			zsx.MakeFormat(zsx.SymFormatSub, nil, sx.MakeList(text("1"))),
+			zsx.MakeFormat(zsx.SymFormatSuper, nil, sx.MakeList(text("2")))),
+			`<del>d</del><em>e</em><ins>i</ins><mark>m</mark><q>q</q><span lang="de">s</span><strong>b</strong><sub>1</sub><sup>2</sup>`},
+		{"literals", zsx.MakeInline(
+			zsx.MakeLiteral(zsx.SymLiteralCode, attrs("", "go"), "c"),
+			zsx.MakeLiteral(zsx.SymLiteralInput, nil, "i"),
+			zsx.MakeLiteral(zsx.SymLiteralMath, nil, "m"),
+			zsx.MakeLiteral(zsx.SymLiteralOutput, nil, "o"),
+			zsx.MakeLiteral(zsx.SymLiteralComment, nil, "x")),
+			`<code class="language-go">c</code><kbd>i</kbd><code class="zs-math">m</code><samp>o</samp><!-- x -->`},
+		{"link", zsx.MakeInline(zsx.MakeLink(attrs("title", `"t"`), zsx.ParseReference("https://t73f.de"), sx.MakeList(text("a")))),
+			`<a href="https://t73f.de" title="&quot;t&quot;">a</a>`},
+		{"link-self", zsx.MakeInline(zsx.MakeLink(nil, zsx.ParseReference("#x"), nil)), `<a href="#x">#x</a>`},
+		{"link-invalid", zsx.MakeInline(zsx.MakeLink(nil, zsx.MakeReference(zsx.SymRefStateInvalid, ":x"), sx.MakeList(text("a")))),
+			"<span>a</span>"},
+		{"link-unsafe", zsx.MakeInline(zsx.MakeLink(nil, zsx.ParseReference("javascript:x()"), sx.MakeList(text("a")))),
+			"<span>a</span>"},
+		{"embed", zsx.MakeInline(zsx.MakeEmbed(nil, zsx.ParseReference("i.png"), "png", sx.MakeList(text("a "), zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text("b")))))),
+			`<img alt="a b" src="i.png">`},
+		{"embed-blob", zsx.MakeInline(zsx.MakeEmbedBLOB(nil, "jpg", []byte("abc"), nil)),
+			`<img alt="" src="data:image/jpeg;base64,YWJj">`},
+		{"embed-svg", zsx.MakeInline(zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte(`<svg onload="alert(1)"/>`), nil)),
+			`<img alt="" src="data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9ImFsZXJ0KDEpIi8+">`},
+		{"cite", zsx.MakeInline(zsx.MakeCite(nil, "k", nil), zsx.MakeCite(nil, "k", sx.MakeList(text("p. 7")))),
+			"<cite>k</cite><cite>k, p. 7</cite>"},
+		{"mark", zsx.MakeInline(zsx.MakeMark(nil, "m", sx.MakeList(text("t")))), `<a id="m">t</a>`},
+		{"attrs", zsx.MakeInline(zsx.MakeFormat(zsx.SymFormatSpan, attrs("", "x", "class", "y", "-", "", "onclick", "z", "a b", "c"), nil)),
+			`<span class="y x"></span>`},
+		{"mark-hostile", zsx.MakeInline(zsx.MakeMark(attrs("href", "javascript:alert(1)", "style", "x"), "m", sx.MakeList(text("t")))),
+			`<a id="m">t</a>`},
+		{"attrs-hostile", zsx.MakeInline(zsx.MakeFormat(zsx.SymFormatSpan, attrs(
+			"href", "javascript:x()", "src", "x", "srcset", "x", "style", "x", "formaction", "x", "srcdoc", "x",
+			"data-x", "1", "dir", "rtl", "id", "i", "lang", "de", "title", "t"), nil)),
+			`<span data-x="1" dir="rtl" id="i" lang="de" title="t"></span>`},
+		{"link-hostile", zsx.MakeInline(zsx.MakeLink(attrs("href", "javascript:x()"), zsx.ParseReference("java\tscript:x()"), sx.MakeList(text("a")))),
+			"<span>a</span>"},
+		{"embed-hostile", zsx.MakeInline(zsx.MakeEmbed(attrs("src", "javascript:x()", "srcset", "x"), zsx.ParseReference("i.png"), "png", nil)),
+			`<img alt="" src="i.png">`},
+	})
+}
+
+func TestEncodeEndnotes(t *testing.T) {
+	t.Parallel()
+	checkEncode(t, []encodeCase{
+		{"simple", zsx.MakeBlock(zsx.MakePara(text("a"), zsx.MakeEndnote(nil, sx.MakeList(text("n"))))),
+			`<p>a<sup id="fnref-1"><a class="zs-noteref" href="#fn-1" role="doc-noteref">1</a></sup></p>` +
+				`<ol class="zs-endnotes"><li id="fn-1" role="doc-endnote" value="1">n ` +
+				`<a class="zs-endnote-backref" href="#fnref-1" role="doc-backlink">&#x21a9;&#xfe0e;</a></li></ol>`},
+		{"nested", zsx.MakeInline(zsx.MakeEndnote(attrs("class", "x"), sx.MakeList(zsx.MakeEndnote(nil, sx.MakeList(text("m")))))),
+			`<sup id="fnref-1"><a class="zs-noteref" href="#fn-1" role="doc-noteref">1</a></sup>` +
+				`<ol class="zs-endnotes"><li class="x" id="fn-1" role="doc-endnote" value="1">` +
+				`<sup id="fnref-2"><a class="zs-noteref" href="#fn-2" role="doc-noteref">2</a></sup> ` +
+				`<a class="zs-endnote-backref" href="#fnref-1" role="doc-backlink">&#x21a9;&#xfe0e;</a></li>` +
+				`<li id="fn-2" role="doc-endnote" value="2">m ` +
+				`<a class="zs-endnote-backref" href="#fnref-2" role="doc-backlink">&#x21a9;&#xfe0e;</a></li></ol>`},
+	})
+}
+
+var symAside = sx.MakeSymbol("ASIDE")
+
+func init() {
+	err := zsx.RegisterLayout(symAside, zsx.Layout{
+		Context:  zsx.ContextBlock,
+		Elements: []zsx.Element{{Name: "attrs", Kind: zsx.ElementAttrs}, {Name: "blocks", Kind: zsx.ElementRest, Context: zsx.ContextBlock}},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestEncodeCustom(t *testing.T) {
+	t.Parallel()
+	checkEncode(t, []encodeCase{
+		{"custom", zsx.MakeBlock(sx.MakeList(symAside, attrs("class", "x"), zsx.MakePara(text("a")))), `<p>a</p>`},
+		{"unknown", zsx.MakeBlock(sx.MakeList(sx.MakeSymbol("UNKNOWN"), zsx.MakePara(text("a")))), ``},
+	})
+}
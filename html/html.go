@@ -0,0 +1,38 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package html provides a parser for a safe subset of HTML, as well as an
+// encoder that writes zsx nodes as HTML5. The parser produces a BLOCK node.
+//
+// Headings, paragraphs, lists, description lists, tables, block quotes, and
+// preformatted text are translated into their zsx counterparts, as well as
+// inline formatting, links, and images. Unknown elements are translated into
+// a REGION-BLOCK node, if they contain block elements, and into a FORMAT-SPAN
+// node otherwise. Both keep the attributes of the element. Scripts, styles,
+// forms, and embedded objects are removed. Only attributes that cannot execute
+// code or load resources are kept: "id", "class", "lang", "title", "dir",
+// "start", and custom data attributes. Links and images are kept only for
+// relative URLs and for URLs with the scheme "http", "https", or "mailto".
+package html
+
+import (
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// ParseBlocks parses the remaining input as HTML and returns a BLOCK node.
+func ParseBlocks(inp *input.Input) *sx.Pair {
+	root := parseTree(inp)
+	return zsx.MakeBlockList(convertBlocks(root.children))
+}
@@ -0,0 +1,521 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package html
+
+import (
+	"slices"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// blockElements are rendered as blocks.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "center": true,
+	"dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hgroup": true, "hr": true, "li": true, "main": true, "menu": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true, "table": true,
+	"tbody": true, "td": true, "tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// transparentElements are replaced by their content.
+var transparentElements = map[string]bool{
+	"body": true, "form": true, "html": true,
+}
+
+// droppedElements are removed, together with their content.
+var droppedElements = map[string]bool{
+	"applet": true, "area": true, "audio": true, "base": true, "button": true, "canvas": true,
+	"datalist": true, "embed": true, "frame": true, "frameset": true, "head": true,
+	"iframe": true, "input": true, "link": true, "map": true, "math": true, "meta": true,
+	"noscript": true, "object": true, "optgroup": true, "option": true, "param": true,
+	"script": true, "select": true, "source": true, "style": true, "svg": true,
+	"template": true, "textarea": true, "title": true, "track": true, "video": true,
+}
+
+// formatSymbols maps inline elements to the symbols of format nodes.
+var formatSymbols = map[string]*sx.Symbol{
+	"b":      zsx.SymFormatStrong,
+	"del":    zsx.SymFormatDelete,
+	"em":     zsx.SymFormatEmph,
+	"i":      zsx.SymFormatEmph,
+	"ins":    zsx.SymFormatInsert,
+	"mark":   zsx.SymFormatMark,
+	"q":      zsx.SymFormatQuote,
+	"s":      zsx.SymFormatDelete,
+	"span":   zsx.SymFormatSpan,
+	"strike": zsx.SymFormatDelete,
+	"strong": zsx.SymFormatStrong,
+	"sub":    zsx.SymFormatSub,
+	"sup":    zsx.SymFormatSuper,
+	"u":      zsx.SymFormatInsert,
+}
+
+// literalSymbols maps inline elements to the symbols of literal nodes.
+var literalSymbols = map[string]*sx.Symbol{
+	"code": zsx.SymLiteralCode,
+	"kbd":  zsx.SymLiteralInput,
+	"samp": zsx.SymLiteralOutput,
+	"tt":   zsx.SymLiteralCode,
+}
+
+// isBlock returns true, if the node must be translated into a block node.
+// Unknown elements are blocks, if they contain a block.
+func isBlock(n *node) bool {
+	switch tag := n.tag; {
+	case tag == "" || droppedElements[tag]:
+		return false
+	case blockElements[tag]:
+		return true
+	case formatSymbols[tag] != nil || literalSymbols[tag] != nil || tag == "a" || tag == "img" || tag == "br":
+		return false
+	}
+	return slices.ContainsFunc(n.children, isBlock)
+}
+
+// filterAttrs returns the allowed attributes of the element, without the given
+// attribute keys.
+func filterAttrs(n *node, consumed ...string) zsx.Attributes {
+	var a zsx.Attributes
+	for key, val := range n.attrs {
+		if !isAllowedAttribute(key) || slices.Contains(consumed, key) {
+			continue
+		}
+		a = a.Set(key, val)
+	}
+	return a
+}
+
+func makeAttrs(n *node, consumed ...string) *sx.Pair {
+	return filterAttrs(n, consumed...).AsAssoc()
+}
+
+// allowedAttributes contains the keys of attributes that cannot be used to
+// execute code, to load resources, or to change the layout of a page.
+var allowedAttributes = map[string]bool{
+	"class": true,
+	"dir":   true,
+	"id":    true,
+	"lang":  true,
+	"start": true,
+	"title": true,
+}
+
+// isAllowedAttribute returns true, if the attribute key is allowed in the
+// safe subset of HTML. Besides some fixed keys, custom data attributes are
+// allowed.
+func isAllowedAttribute(key string) bool {
+	key = strings.ToLower(key)
+	if allowedAttributes[key] {
+		return true
+	}
+	name, isData := strings.CutPrefix(key, "data-")
+	return isData && name != "" && !strings.ContainsFunc(name, func(ch rune) bool { return !isNameChar(ch) })
+}
+
+// allowedSchemes contains the schemes of URLs that are safe to follow.
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// isSafeURL returns true for relative URLs, and for URLs with an allowed
+// scheme. Data URLs are allowed for raster images only.
+//
+// Browsers ignore ASCII control characters and spaces within the scheme, so
+// they are removed before the check.
+func isSafeURL(u string, image bool) bool {
+	u = strings.ToLower(strings.Map(func(ch rune) rune {
+		if ch <= ' ' || ch == 0x7f {
+			return -1
+		}
+		return ch
+	}, u))
+	pos := strings.IndexAny(u, ":/?#")
+	if pos < 0 || u[pos] != ':' {
+		return true // relative URL
+	}
+	scheme := u[:pos]
+	if scheme == "data" {
+		return image && strings.HasPrefix(u, "data:image/") && !strings.HasPrefix(u, "data:image/svg")
+	}
+	return allowedSchemes[scheme]
+}
+
+// blockBuilder collects block nodes. Inline nodes in a block context are
+// collected into paragraphs.
+type blockBuilder struct {
+	lb      sx.ListBuilder
+	pending []*node
+}
+
+func convertBlocks(nodes []*node) *sx.Pair {
+	var bb blockBuilder
+	bb.add(nodes)
+	bb.flush()
+	return bb.lb.List()
+}
+
+func (bb *blockBuilder) add(nodes []*node) {
+	for _, n := range nodes {
+		switch {
+		case droppedElements[n.tag]:
+		case transparentElements[n.tag]:
+			bb.add(n.children)
+		case isBlock(n):
+			bb.flush()
+			if block := convertBlock(n); block != nil {
+				bb.lb.Add(block)
+			}
+		default:
+			bb.pending = append(bb.pending, n)
+		}
+	}
+}
+
+func (bb *blockBuilder) flush() {
+	if ins := convertInlines(bb.pending); ins != nil {
+		bb.lb.Add(zsx.MakeParaList(ins))
+	}
+	bb.pending = nil
+}
+
+func convertBlock(n *node) *sx.Pair {
+	switch tag := n.tag; tag {
+	case "p":
+		if ins := convertInlines(n.children); ins != nil {
+			return zsx.MakeParaList(ins)
+		}
+		return nil
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return zsx.MakeHeading(makeAttrs(n), int(tag[1]-'0'), convertInlines(n.children))
+	case "hr":
+		return zsx.MakeThematic(makeAttrs(n))
+	case "ul", "menu":
+		return convertList(n, zsx.SymListUnordered)
+	case "ol":
+		return convertList(n, zsx.SymListOrdered)
+	case "dl":
+		return convertDescription(n)
+	case "blockquote":
+		return zsx.MakeRegion(zsx.SymRegionQuote, makeAttrs(n), convertBlocks(n.children), nil)
+	case "pre":
+		return convertPre(n)
+	case "table":
+		return convertTable(n)
+	}
+	return zsx.MakeRegion(zsx.SymRegionBlock, makeAttrs(n), convertBlocks(n.children), nil)
+}
+
+func convertList(n *node, sym *sx.Symbol) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, child := range n.children {
+		if child.tag == "li" {
+			lb.Add(zsx.MakeListItem(nil, convertBlocks(child.children)))
+		} else if blocks := convertBlocks([]*node{child}); blocks != nil {
+			lb.Add(zsx.MakeListItem(nil, blocks))
+		}
+	}
+	return zsx.MakeList(sym, makeAttrs(n), lb.List())
+}
+
+func convertDescription(n *node) *sx.Pair {
+	var lb, entries sx.ListBuilder
+	hasTerm := false
+	flushDetail := func() {
+		if hasTerm {
+			lb.Add(zsx.MakeDetail(entries.List()))
+			entries = sx.ListBuilder{}
+		}
+	}
+	for _, child := range n.children {
+		switch child.tag {
+		case "dt":
+			flushDetail()
+			lb.Add(zsx.MakeTerm(nil, convertInlines(child.children)))
+			hasTerm = true
+		case "dd":
+			if !hasTerm {
+				lb.Add(zsx.MakeTerm(nil, nil))
+				hasTerm = true
+			}
+			entries.Add(zsx.MakeEntry(nil, convertBlocks(child.children)))
+		}
+	}
+	flushDetail()
+	return zsx.MakeDescription(makeAttrs(n), lb.List())
+}
+
+// convertPre translates preformatted text into a code block. The syntax is
+// taken from a "language-" class of a contained code element.
+func convertPre(n *node) *sx.Pair {
+	a := filterAttrs(n)
+	for _, child := range n.children {
+		if child.tag != "code" {
+			continue
+		}
+		for _, class := range filterAttrs(child).GetClasses() {
+			if lang, found := strings.CutPrefix(class, "language-"); found && lang != "" {
+				a = a.Set("", lang)
+				break
+			}
+		}
+	}
+	content := strings.TrimPrefix(textContent(n), "\n")
+	content = strings.TrimSuffix(content, "\n")
+	return zsx.MakeVerbatim(zsx.SymVerbatimCode, a.AsAssoc(), content)
+}
+
+// textContent returns the text of the node and of all its descendants.
+func textContent(n *node) string {
+	var sb strings.Builder
+	var walk func(*node)
+	walk = func(n *node) {
+		switch {
+		case n.tag == "":
+			sb.WriteString(n.text)
+		case n.tag == "br":
+			sb.WriteByte('\n')
+		case droppedElements[n.tag]:
+		default:
+			for _, child := range n.children {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// convertTable translates a table. The first row of the table head is the
+// header row. Without a table head, the first row is the header row, if it
+// contains only header cells.
+func convertTable(n *node) *sx.Pair {
+	var header []*node
+	var rows [][]*node
+	hasHeader := false
+	addRows := func(parent *node, isHead bool) {
+		for _, child := range parent.children {
+			if child.tag != "tr" {
+				continue
+			}
+			cells := tableCells(child)
+			if isHead && !hasHeader {
+				header, hasHeader = cells, true
+			} else {
+				rows = append(rows, cells)
+			}
+		}
+	}
+	for _, child := range n.children {
+		switch child.tag {
+		case "thead":
+			addRows(child, true)
+		case "tbody", "tfoot":
+			addRows(child, false)
+		case "tr":
+			rows = append(rows, tableCells(child))
+		}
+	}
+	if !hasHeader && len(rows) > 0 && !slices.ContainsFunc(rows[0], func(cell *node) bool { return cell.tag != "th" }) {
+		header, rows, hasHeader = rows[0], rows[1:], true
+	}
+
+	width := len(header)
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var headerRow *sx.Pair
+	if hasHeader {
+		headerRow = convertRow(header, width)
+	}
+	var lb sx.ListBuilder
+	for _, row := range rows {
+		lb.Add(convertRow(row, width))
+	}
+	return zsx.MakeTable(makeAttrs(n), headerRow, lb.List())
+}
+
+func tableCells(tr *node) []*node {
+	var cells []*node
+	for _, child := range tr.children {
+		if child.tag == "td" || child.tag == "th" {
+			cells = append(cells, child)
+		}
+	}
+	return cells
+}
+
+// convertRow builds a row node with exactly width cells.
+func convertRow(cells []*node, width int) *sx.Pair {
+	var lb sx.ListBuilder
+	for i := range width {
+		if i >= len(cells) {
+			lb.Add(zsx.MakeCell(nil, nil))
+			continue
+		}
+		cell := cells[i]
+		var attrs *sx.Pair
+		if align, found := cell.attrs.Get("align"); found {
+			switch strings.ToLower(align) {
+			case "left":
+				attrs = sx.MakeList(sx.Cons(zsx.SymAttrAlign, zsx.AttrAlignLeft))
+			case "center":
+				attrs = sx.MakeList(sx.Cons(zsx.SymAttrAlign, zsx.AttrAlignCenter))
+			case "right":
+				attrs = sx.MakeList(sx.Cons(zsx.SymAttrAlign, zsx.AttrAlignRight))
+			}
+		}
+		lb.Add(zsx.MakeCell(attrs, convertInlines(cell.children)))
+	}
+	return zsx.MakeRow(nil, lb.List())
+}
+
+// convertInlines translates the nodes into inline nodes, without leading and
+// trailing spaces.
+func convertInlines(nodes []*node) *sx.Pair {
+	return cleanInlines(appendInlines(nil, nodes), true)
+}
+
+func appendInlines(ins []*sx.Pair, nodes []*node) []*sx.Pair {
+	for _, n := range nodes {
+		ins = appendInline(ins, n)
+	}
+	return ins
+}
+
+func appendInline(ins []*sx.Pair, n *node) []*sx.Pair {
+	tag := n.tag
+	switch {
+	case tag == "":
+		return append(ins, zsx.MakeText(n.text))
+	case tag == "br":
+		return append(ins, zsx.MakeHard())
+	case droppedElements[tag]:
+		return ins
+	case tag == "a":
+		return append(ins, convertLink(n))
+	case tag == "img":
+		if embed := convertImage(n); embed != nil {
+			return append(ins, embed)
+		}
+		return ins
+	}
+	if sym := formatSymbols[tag]; sym != nil {
+		return append(ins, zsx.MakeFormat(sym, makeAttrs(n), cleanInlines(appendInlines(nil, n.children), false)))
+	}
+	if sym := literalSymbols[tag]; sym != nil {
+		return append(ins, zsx.MakeLiteral(sym, makeAttrs(n), collapseSpace(textContent(n))))
+	}
+	if transparentElements[tag] || blockElements[tag] {
+		// Block elements within inline content are separated by spaces.
+		ins = append(ins, zsx.MakeText(" "))
+		return append(appendInlines(ins, n.children), zsx.MakeText(" "))
+	}
+	return append(ins, zsx.MakeFormat(zsx.SymFormatSpan, makeAttrs(n), cleanInlines(appendInlines(nil, n.children), false)))
+}
+
+// convertLink translates an anchor element. Without a safe reference, it is
+// translated into a span.
+func convertLink(n *node) *sx.Pair {
+	ins := cleanInlines(appendInlines(nil, n.children), false)
+	href, found := n.attrs.Get("href")
+	if !found || !isSafeURL(href, false) {
+		return zsx.MakeFormat(zsx.SymFormatSpan, makeAttrs(n, "href"), ins)
+	}
+	return zsx.MakeLink(makeAttrs(n, "href"), zsx.ParseReference(strings.TrimSpace(href)), ins)
+}
+
+// convertImage translates an image element. Images without a safe source are
+// removed.
+func convertImage(n *node) *sx.Pair {
+	src, found := n.attrs.Get("src")
+	if !found || !isSafeURL(src, true) {
+		return nil
+	}
+	var ins *sx.Pair
+	if alt, _ := n.attrs.Get("alt"); alt != "" {
+		ins = sx.MakeList(zsx.MakeText(collapseSpace(alt)))
+	}
+	return zsx.MakeEmbed(makeAttrs(n, "src", "alt"), zsx.ParseReference(strings.TrimSpace(src)), "", ins)
+}
+
+// collapseSpace replaces each sequence of whitespace by one space character.
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, ch := range s {
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(ch)
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+func isText(in *sx.Pair) bool { return zsx.NodeSymbol(in) == zsx.SymText }
+
+// cleanInlines merges adjacent text nodes, collapses whitespace, and removes
+// empty text nodes. Spaces before and after a line break are removed, as well
+// as leading and trailing spaces, if trim is set.
+func cleanInlines(ins []*sx.Pair, trim bool) *sx.Pair {
+	result := make([]*sx.Pair, 0, len(ins))
+	var sb strings.Builder
+	flushText := func(trimRight bool) {
+		s := collapseSpace(sb.String())
+		sb.Reset()
+		if trimRight {
+			s = strings.TrimRight(s, " ")
+		}
+		if len(result) == 0 && trim || len(result) > 0 && zsx.NodeSymbol(result[len(result)-1]) == zsx.SymHard {
+			s = strings.TrimLeft(s, " ")
+		}
+		if s != "" {
+			result = append(result, zsx.MakeText(s))
+		}
+	}
+	for _, in := range ins {
+		switch {
+		case isText(in):
+			sb.WriteString(zsx.GetText(in))
+		case zsx.NodeSymbol(in) == zsx.SymHard:
+			flushText(true)
+			result = append(result, in)
+		default:
+			flushText(false)
+			result = append(result, in)
+		}
+	}
+	flushText(trim)
+	for trim && len(result) > 0 && zsx.NodeSymbol(result[len(result)-1]) == zsx.SymHard {
+		result = result[:len(result)-1]
+	}
+	var lb sx.ListBuilder
+	for _, in := range result {
+		lb.Add(in)
+	}
+	return lb.List()
+}
@@ -0,0 +1,109 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package html_test
+
+import (
+	"testing"
+
+	"t73f.de/r/zsx/html"
+	"t73f.de/r/zsx/input"
+)
+
+type testCase struct {
+	name string
+	src  string
+	exp  string
+}
+
+func checkBlocks(t *testing.T, testcases []testCase) {
+	t.Helper()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := html.ParseBlocks(input.NewInput([]byte(tc.src))).String()
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %s\ngot: %s", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestParseBlocks(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"empty", "", "(BLOCK)"},
+		{"document", "<!DOCTYPE html><html><head><title>T</title></head><body><p>a</p></body></html>",
+			`(BLOCK (PARA (TEXT "a")))`},
+		{"para", "<p>a\n  <b>b</b> <br> c &amp; d<p>e", `(BLOCK (PARA (TEXT "a ") (FORMAT-STRONG () (TEXT "b")) (HARD) (TEXT "c & d")) (PARA (TEXT "e")))`},
+		{"bare-text", "a<div>b</div>c", `(BLOCK (PARA (TEXT "a")) (REGION-BLOCK () ((PARA (TEXT "b")))) (PARA (TEXT "c")))`},
+		{"heading", `<h2 id="x">A <em>b</em></h2>`, `(BLOCK (HEADING (("id" . "x")) 2 (TEXT "A ") (FORMAT-EMPH () (TEXT "b"))))`},
+		{"thematic", "<hr>", `(BLOCK (THEMATIC ()))`},
+		{"list", "<ul><li>a<li>b<ol start=2><li>c</ol></ul>",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a"))) (ITEM () (PARA (TEXT "b")) (ORDERED (("start" . "2")) (ITEM () (PARA (TEXT "c")))))))`},
+		{"description", "<dl><dt>T<dd>D<dt>U</dl>",
+			`(BLOCK (DESCRIPTION () (TERM () (TEXT "T")) (DETAIL (ENTRY () (PARA (TEXT "D")))) (TERM () (TEXT "U")) (DETAIL)))`},
+		{"quote", "<blockquote><p>q</blockquote>", `(BLOCK (REGION-QUOTE () ((PARA (TEXT "q")))))`},
+		{"pre", "<pre><code class=\"language-go\">\na <b>b</b>\n  c\n</code></pre>",
+			`(BLOCK (VERBATIM-CODE (("" . "go")) "a b\n  c"))`},
+		{"table", `<table><tr><th>A<th align="center">B<tr><td>1</table>`,
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "A")) (CELL ((align . "center")) (TEXT "B"))) (ROW () (CELL () (TEXT "1")) (CELL ()))))`},
+		{"table-head", "<table><thead><tr><td>A</thead><tbody><tr><th>1</tbody></table>",
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "A"))) (ROW () (CELL () (TEXT "1")))))`},
+		{"table-no-header", "<table><tr><td>1<td>2</table>",
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "1")) (CELL () (TEXT "2")))))`},
+	})
+}
+
+func TestParseInlines(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"formats", "<em>a</em><i>b</i><strong>c</strong><del>d</del><ins>e</ins><mark>f</mark><sub>g</sub><sup>h</sup><q>i</q>",
+			`(BLOCK (PARA (FORMAT-EMPH () (TEXT "a")) (FORMAT-EMPH () (TEXT "b")) (FORMAT-STRONG () (TEXT "c")) (FORMAT-DELETE () (TEXT "d")) (FORMAT-INSERT () (TEXT "e")) (FORMAT-MARK () (TEXT "f")) (FORMAT-SUB () (TEXT "g")) (FORMAT-SUPER () (TEXT "h")) (FORMAT-QUOTE () (TEXT "i"))))`},
+		{"literals", "<code>a  <b>b</b></code><kbd>c</kbd><samp>d</samp>",
+			`(BLOCK (PARA (LITERAL-CODE () "a b") (LITERAL-INPUT () "c") (LITERAL-OUTPUT () "d")))`},
+		{"link", `<a href="https://t73f.de" title="T">a <em>b</em></a>`,
+			`(BLOCK (PARA (LINK (("title" . "T")) (EXTERNAL "https://t73f.de") (TEXT "a ") (FORMAT-EMPH () (TEXT "b")))))`},
+		{"link-hosted", `<a href="../x">a</a>`, `(BLOCK (PARA (LINK () (HOSTED "../x") (TEXT "a"))))`},
+		{"anchor", `<a name="x" id="y">a</a>`, `(BLOCK (PARA (FORMAT-SPAN (("id" . "y")) (TEXT "a"))))`},
+		{"image", `<img src="a.png" alt="A  b">`, `(BLOCK (PARA (EMBED () (HOSTED "a.png") "" (TEXT "A b"))))`},
+		{"unknown", `<abbr title="x">a</abbr>`, `(BLOCK (PARA (FORMAT-SPAN (("title" . "x")) (TEXT "a"))))`},
+		{"unknown-block", `<article class="c"><p>a</article>`, `(BLOCK (REGION-BLOCK (("class" . "c")) ((PARA (TEXT "a")))))`},
+		{"no-markup", "a < b && c", `(BLOCK (PARA (TEXT "a < b && c")))`},
+	})
+}
+
+func TestParseUnsafe(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"script", "<p>a<script>alert('<p>')</script>b", `(BLOCK (PARA (TEXT "ab")))`},
+		{"style", "<style>p {}</style>", "(BLOCK)"},
+		{"iframe", `<iframe src="x">a</iframe>`, "(BLOCK)"},
+		{"form", "<form><input name=x><p>a</form>", `(BLOCK (PARA (TEXT "a")))`},
+		{"event", `<span onclick="x()">a</span>`, `(BLOCK (PARA (FORMAT-SPAN () (TEXT "a"))))`},
+		{"javascript", `<a href=" JavaScript:x()">a</a>`, `(BLOCK (PARA (FORMAT-SPAN () (TEXT "a"))))`},
+		{"data-link", `<a href="data:text/html,x">a</a>`, `(BLOCK (PARA (FORMAT-SPAN () (TEXT "a"))))`},
+		{"data-image", `<img src="data:image/png;base64,AA"><img src="data:image/svg+xml,x">`,
+			`(BLOCK (PARA (EMBED () (EXTERNAL "data:image/png;base64,AA") "")))`},
+		{"comment", "<!-- <p>a</p> -->", "(BLOCK)"},
+		{"tab-scheme", "<a href=\"java\tscript:x()\">a</a><a href=\" java\nscript:x()\">b</a>",
+			`(BLOCK (PARA (FORMAT-SPAN () (TEXT "a")) (FORMAT-SPAN () (TEXT "b"))))`},
+		{"control-scheme", "<a href=\"\x01javascript:x()\">a</a>", `(BLOCK (PARA (FORMAT-SPAN () (TEXT "a"))))`},
+		{"other-scheme", `<a href="vbscript:x()">a</a><img src="file:///etc/passwd">`,
+			`(BLOCK (PARA (FORMAT-SPAN () (TEXT "a"))))`},
+		{"mailto", `<a href="mailto:a@b.c">a</a>`, `(BLOCK (PARA (LINK () (EXTERNAL "mailto:a@b.c") (TEXT "a"))))`},
+		{"attributes", `<p style="color:red" data-x="1" data-="2" lang="de" formaction="x" xlink:href="y" srcset="z">a</p>`,
+			`(BLOCK (PARA (TEXT "a")))`},
+		{"attributes-span", `<span style="x" srcdoc="y" dir="ltr" data-x="1">a</span>`,
+			`(BLOCK (PARA (FORMAT-SPAN (("data-x" . "1") ("dir" . "ltr")) (TEXT "a"))))`},
+	})
+}
@@ -0,0 +1,333 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package html
+
+import (
+	"strings"
+
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// node is an element or a text of the parsed HTML document.
+type node struct {
+	tag      string // Lower case name of the element, empty for text nodes
+	text     string
+	attrs    zsx.Attributes
+	children []*node
+}
+
+// voidElements have no content and no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements contain text that is not parsed as HTML.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true, "xmp": true,
+}
+
+// closesPara contains all elements that implicitly close an open paragraph.
+var closesPara = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true,
+	"div": true, "dl": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "ul": true,
+}
+
+// treeBuilder builds a tree of nodes, while it scans the HTML source.
+type treeBuilder struct {
+	inp   *input.Input
+	stack []*node
+}
+
+// parseTree parses the remaining input into a tree of nodes.
+func parseTree(inp *input.Input) *node {
+	root := &node{tag: "#root"}
+	tb := treeBuilder{inp: inp, stack: []*node{root}}
+	for inp.Ch != input.EOS {
+		if inp.Ch == '<' && tb.parseMarkup() {
+			continue
+		}
+		tb.parseText()
+	}
+	return root
+}
+
+func (tb *treeBuilder) current() *node { return tb.stack[len(tb.stack)-1] }
+
+func (tb *treeBuilder) appendNode(n *node) {
+	cur := tb.current()
+	cur.children = append(cur.children, n)
+}
+
+// parseText parses text up to the next markup. At least one character is
+// consumed.
+func (tb *treeBuilder) parseText() {
+	inp := tb.inp
+	var sb strings.Builder
+	for {
+		if inp.Ch == '&' {
+			writeEntity(&sb, inp)
+		} else {
+			sb.WriteRune(inp.Ch)
+			inp.Next()
+		}
+		if inp.Ch == input.EOS || inp.Ch == '<' {
+			break
+		}
+	}
+	text := sb.String()
+	cur := tb.current()
+	if l := len(cur.children); l > 0 && cur.children[l-1].tag == "" {
+		cur.children[l-1].text += text
+		return
+	}
+	tb.appendNode(&node{text: text})
+}
+
+// writeEntity writes the entity at the current position, or the ampersand
+// character if there is no valid entity.
+func writeEntity(sb *strings.Builder, inp *input.Input) {
+	pos := inp.Pos
+	if s, ok := zsx.ScanEntity(inp); ok {
+		sb.WriteString(s)
+		return
+	}
+	inp.SetPos(pos)
+	sb.WriteByte('&')
+	inp.Next()
+}
+
+// parseMarkup parses a tag, a comment, or a declaration. If there is no valid
+// markup, the read position is not changed and false is returned.
+func (tb *treeBuilder) parseMarkup() bool {
+	inp := tb.inp
+	pos := inp.Pos
+	switch inp.Next() {
+	case '!':
+		if inp.Accept("!--") {
+			skipTo(inp, "-->")
+		} else {
+			skipTo(inp, ">")
+		}
+		return true
+	case '?':
+		skipTo(inp, ">")
+		return true
+	case '/':
+		inp.Next()
+		name := scanTagName(inp)
+		if name == "" {
+			inp.SetPos(pos)
+			return false
+		}
+		skipTo(inp, ">")
+		tb.closeElement(name)
+		return true
+	}
+	name := scanTagName(inp)
+	if name == "" {
+		inp.SetPos(pos)
+		return false
+	}
+	attrs, selfClosing := scanAttributes(inp)
+	n := &node{tag: name, attrs: attrs}
+	tb.openElement(n)
+	if rawTextElements[name] {
+		text := scanRawText(inp, name)
+		if text != "" {
+			n.children = append(n.children, &node{text: text})
+		}
+		tb.closeElement(name)
+	} else if selfClosing || voidElements[name] {
+		tb.closeElement(name)
+	}
+	return true
+}
+
+// skipTo reads until after the given string, or up to the end of input.
+func skipTo(inp *input.Input, s string) {
+	for inp.Ch != input.EOS {
+		if inp.Accept(s) {
+			return
+		}
+		inp.Next()
+	}
+}
+
+func isNameChar(ch rune) bool {
+	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+		ch == '-' || ch == '_' || ch == ':'
+}
+
+// scanTagName scans the name of an element. It must start with a letter.
+func scanTagName(inp *input.Input) string {
+	if ch := inp.Ch; !('a' <= ch && ch <= 'z') && !('A' <= ch && ch <= 'Z') {
+		return ""
+	}
+	pos := inp.Pos
+	for isNameChar(inp.Ch) {
+		inp.Next()
+	}
+	return strings.ToLower(string(inp.Src[pos:inp.Pos]))
+}
+
+func skipWhitespace(inp *input.Input) {
+	for inp.IsSpace() || inp.Ch == '\n' || inp.Ch == '\r' {
+		inp.Next()
+	}
+}
+
+// scanAttributes scans all attributes of a start tag, including the closing
+// ">". It returns true, if the tag is self-closing.
+func scanAttributes(inp *input.Input) (zsx.Attributes, bool) {
+	var attrs zsx.Attributes
+	for {
+		skipWhitespace(inp)
+		switch inp.Ch {
+		case input.EOS:
+			return attrs, false
+		case '>':
+			inp.Next()
+			return attrs, false
+		case '/':
+			if inp.Next() == '>' {
+				inp.Next()
+				return attrs, true
+			}
+			continue
+		}
+		pos := inp.Pos
+		for ch := inp.Ch; ch != input.EOS && ch != '>' && ch != '/' && ch != '=' &&
+			!input.IsSpace(ch) && !input.IsEOLEOS(ch); ch = inp.Next() {
+		}
+		key := strings.ToLower(string(inp.Src[pos:inp.Pos]))
+		if key == "" {
+			inp.Next()
+			continue
+		}
+		value := ""
+		skipWhitespace(inp)
+		if inp.Ch == '=' {
+			inp.Next()
+			skipWhitespace(inp)
+			value = scanAttributeValue(inp)
+		}
+		if _, found := attrs.Get(key); !found {
+			attrs = attrs.Set(key, value)
+		}
+	}
+}
+
+// scanAttributeValue scans a quoted or an unquoted attribute value.
+func scanAttributeValue(inp *input.Input) string {
+	var sb strings.Builder
+	if quote := inp.Ch; quote == '"' || quote == '\'' {
+		inp.Next()
+		for inp.Ch != input.EOS && inp.Ch != quote {
+			if inp.Ch == '&' {
+				writeEntity(&sb, inp)
+				continue
+			}
+			sb.WriteRune(inp.Ch)
+			inp.Next()
+		}
+		inp.Next()
+		return sb.String()
+	}
+	for ch := inp.Ch; ch != input.EOS && ch != '>' && !input.IsSpace(ch) && !input.IsEOLEOS(ch); ch = inp.Ch {
+		if ch == '&' {
+			writeEntity(&sb, inp)
+			continue
+		}
+		sb.WriteRune(ch)
+		inp.Next()
+	}
+	return sb.String()
+}
+
+// scanRawText reads up to the end tag of the given element. The end tag is
+// consumed too.
+func scanRawText(inp *input.Input, name string) string {
+	pos := inp.Pos
+	for inp.Ch != input.EOS {
+		if inp.Ch == '<' && inp.Peek() == '/' {
+			end := inp.Pos
+			inp.Next()
+			inp.Next()
+			if scanTagName(inp) == name {
+				skipTo(inp, ">")
+				return string(inp.Src[pos:end])
+			}
+			continue
+		}
+		inp.Next()
+	}
+	return string(inp.Src[pos:inp.Pos])
+}
+
+// openElement adds the element to the tree and makes it the current element.
+// Elements that are implicitly closed by the new element are closed before.
+func (tb *treeBuilder) openElement(n *node) {
+	if closesPara[n.tag] {
+		tb.closeImplied([]string{"p"}, "button", "caption", "table", "td", "th")
+	}
+	switch n.tag {
+	case "li":
+		tb.closeImplied([]string{"li"}, "ul", "ol", "table")
+	case "dt", "dd":
+		tb.closeImplied([]string{"dt", "dd"}, "dl", "table")
+	case "tr":
+		tb.closeImplied([]string{"tr"}, "table", "thead", "tbody", "tfoot")
+	case "td", "th":
+		tb.closeImplied([]string{"td", "th"}, "tr", "table")
+	case "thead", "tbody", "tfoot":
+		tb.closeImplied([]string{"thead", "tbody", "tfoot"}, "table")
+	}
+	tb.appendNode(n)
+	tb.stack = append(tb.stack, n)
+}
+
+// closeImplied closes the innermost open element with one of the given names,
+// if there is no open stop element in between.
+func (tb *treeBuilder) closeImplied(names []string, stops ...string) {
+	for i := len(tb.stack) - 1; i > 0; i-- {
+		tag := tb.stack[i].tag
+		for _, name := range names {
+			if tag == name {
+				tb.stack = tb.stack[:i]
+				return
+			}
+		}
+		for _, stop := range stops {
+			if tag == stop {
+				return
+			}
+		}
+	}
+}
+
+// closeElement closes the innermost open element with the given name, and
+// all elements within it. If there is no such element, nothing happens.
+func (tb *treeBuilder) closeElement(name string) {
+	for i := len(tb.stack) - 1; i > 0; i-- {
+		if tb.stack[i].tag == name {
+			tb.stack = tb.stack[:i]
+			return
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package layouttest provides custom nodes to test the encoders.
+package layouttest
+
+import (
+	"sync"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Symbols of the custom nodes.
+var (
+	SymAside = sx.MakeSymbol("ASIDE") // Block node with a title, a summary, and blocks
+	SymBadge = sx.MakeSymbol("BADGE") // Inline node with an icon and inlines
+)
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+// Register registers the layouts of the custom nodes. It may be called by
+// every test that uses them.
+func Register(t testing.TB) {
+	t.Helper()
+	registerOnce.Do(func() {
+		registerErr = zsx.RegisterLayout(SymAside, zsx.Layout{
+			Context: zsx.ContextBlock,
+			Elements: []zsx.Element{
+				{Name: "attrs", Kind: zsx.ElementAttrs},
+				{Name: "title", Kind: zsx.ElementList, Context: zsx.ContextInline},
+				{Name: "summary", Kind: zsx.ElementNode, Context: zsx.ContextBlock},
+				{Name: "blocks", Kind: zsx.ElementRest, Context: zsx.ContextBlock},
+			},
+		})
+		if registerErr == nil {
+			registerErr = zsx.RegisterLayout(SymBadge, zsx.Layout{
+				Context: zsx.ContextInline,
+				Elements: []zsx.Element{
+					{Name: "icon", Kind: zsx.ElementNode, Context: zsx.ContextInline},
+					{Name: "inlines", Kind: zsx.ElementRest, Context: zsx.ContextInline},
+				},
+			})
+		}
+	})
+	if registerErr != nil {
+		t.Fatal(registerErr)
+	}
+}
+
+// MakeAside builds an ASIDE node. The summary may be nil.
+func MakeAside(title *sx.Pair, summary *sx.Pair, blocks ...*sx.Pair) *sx.Pair {
+	var lb sx.ListBuilder
+	lb.AddN(SymAside, sx.Nil(), title, summary)
+	for _, block := range blocks {
+		lb.Add(block)
+	}
+	return lb.List()
+}
+
+// MakeBadge builds a BADGE node. The icon may be nil.
+func MakeBadge(icon *sx.Pair, inlines ...*sx.Pair) *sx.Pair {
+	var lb sx.ListBuilder
+	lb.AddN(SymBadge, icon)
+	for _, in := range inlines {
+		lb.Add(in)
+	}
+	return lb.List()
+}
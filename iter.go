@@ -0,0 +1,135 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx
+
+import (
+	"iter"
+	"slices"
+
+	"t73f.de/r/sx"
+)
+
+// All returns an iterator over the given node and all its descendants, in
+// pre-order, i.e. a node is yielded before its child nodes.
+//
+// Besides the node, the iterator yields an association list that describes
+// the context of the node. Use [GetWalkPos] and [GetWalkList] to retrieve its
+// position within the parent list, and [GetWalkParents] and [GetWalkDepth] to
+// retrieve its ancestors. Splice nodes are not yielded, only their elements.
+// Their positions are those within the enclosing list, as if the splice node
+// were replaced by its elements, and the positions of the subsequent nodes
+// are shifted accordingly. For an element of a splice node, [GetWalkList]
+// returns the element and the subsequent elements of the splice node.
+func All(node *sx.Pair) iter.Seq2[*sx.Pair, *sx.Pair] {
+	return func(yield func(*sx.Pair, *sx.Pair) bool) {
+		v := iterVisitor{yield: yield, pre: true}
+		WalkIt(&v, node, nil)
+	}
+}
+
+// AllPostOrder returns an iterator over the given node and all its
+// descendants, in post-order, i.e. a node is yielded after its child nodes.
+// Otherwise, it works like [All].
+func AllPostOrder(node *sx.Pair) iter.Seq2[*sx.Pair, *sx.Pair] {
+	return func(yield func(*sx.Pair, *sx.Pair) bool) {
+		v := iterVisitor{yield: yield, pre: false}
+		WalkIt(&v, node, nil)
+	}
+}
+
+// AllWithSymbol returns an iterator over the given node and all its
+// descendants, in pre-order, that have one of the given symbols. Otherwise,
+// it works like [All].
+func AllWithSymbol(node *sx.Pair, syms ...*sx.Symbol) iter.Seq2[*sx.Pair, *sx.Pair] {
+	return func(yield func(*sx.Pair, *sx.Pair) bool) {
+		v := iterVisitor{yield: yield, pre: true, syms: syms}
+		WalkIt(&v, node, nil)
+	}
+}
+
+type iterVisitor struct {
+	yield   func(*sx.Pair, *sx.Pair) bool
+	pre     bool         // yield in pre-order
+	syms    []*sx.Symbol // only yield nodes with one of these symbols, if not empty
+	stopped bool         // yield returned false
+
+	// shift maps the position pair of a walked list to the number of
+	// positions its elements must be shifted, due to splice nodes.
+	shift map[*sx.Pair]int
+}
+
+func (v *iterVisitor) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	if v.stopped {
+		return true
+	}
+	if SymSpecialSplice.IsEqualSymbol(NodeSymbol(node)) {
+		v.walkSplice(node, alst)
+		return true
+	}
+	alst = v.flatAlist(alst)
+	if v.pre && v.matches(node) && !v.yield(node, alst) {
+		v.stopped = true
+		return true
+	}
+	return false
+}
+
+func (v *iterVisitor) VisitItAfter(node *sx.Pair, alst *sx.Pair) {
+	if v.stopped || v.pre || !v.matches(node) {
+		return
+	}
+	if !v.yield(node, v.flatAlist(alst)) {
+		v.stopped = true
+	}
+}
+
+// walkSplice walks the elements of a splice node, as if they were elements of
+// the list that contains the splice node.
+func (v *iterVisitor) walkSplice(node *sx.Pair, alst *sx.Pair) {
+	if v.shift == nil {
+		v.shift = map[*sx.Pair]int{}
+	}
+	outerPos := alst.Assoc(symWalkPos)
+	start := max(GetWalkPos(alst)+v.shift[outerPos], 0)
+
+	pairPos, pairList := sx.Cons(symWalkPos, sx.Int64(0)), sx.Cons(symWalkList, sx.Nil())
+	v.shift[pairPos] = start
+	elemAlst := alst.Cons(pairPos).Cons(pairList)
+	count := 0
+	for n := range node.Tail().Pairs() {
+		pairList.SetCdr(n)
+		pairPos.SetCdr(sx.Int64(count))
+		WalkIt(v, n.Head(), elemAlst)
+		count++
+	}
+	// Nested splice nodes have increased the shift of the element list.
+	count += v.shift[pairPos] - start
+	delete(v.shift, pairPos)
+	if outerPos != nil {
+		v.shift[outerPos] += count - 1
+	}
+}
+
+// flatAlist returns the association list with the position of the node
+// within its list, shifted by the elements of preceding splice nodes.
+func (v *iterVisitor) flatAlist(alst *sx.Pair) *sx.Pair {
+	if shift := v.shift[alst.Assoc(symWalkPos)]; shift != 0 {
+		return alst.Cons(sx.Cons(symWalkPos, sx.Int64(GetWalkPos(alst)+shift)))
+	}
+	return alst
+}
+
+func (v *iterVisitor) matches(node *sx.Pair) bool {
+	return len(v.syms) == 0 || slices.Contains(v.syms, NodeSymbol(node))
+}
@@ -0,0 +1,126 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx_test
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+func makeIterTree() *sx.Pair {
+	return zsx.MakeBlock(
+		zsx.MakeHeading(nil, 1, sx.MakeList(zsx.MakeText("a"))),
+		zsx.MakePara(
+			zsx.MakeLink(nil, zsx.ParseReference("b"), sx.MakeList(zsx.MakeText("c"))),
+			sx.MakeList(zsx.SymSpecialSplice, zsx.MakeText("d"), zsx.MakeSoft()),
+		),
+	)
+}
+
+// makeSpliceTree returns a paragraph with nested and empty splice nodes. Each
+// text contains the position within the flattened paragraph.
+func makeSpliceTree() *sx.Pair {
+	return zsx.MakePara(
+		zsx.MakeText("0"),
+		sx.MakeList(zsx.SymSpecialSplice,
+			zsx.MakeText("1"),
+			sx.MakeList(zsx.SymSpecialSplice, zsx.MakeText("2"), zsx.MakeText("3")),
+			sx.MakeList(zsx.SymSpecialSplice),
+		),
+		zsx.MakeSoft(),
+		zsx.MakeText("5"),
+	)
+}
+
+// iterString returns the symbols of all yielded nodes, together with their
+// position, and their parent symbols.
+func iterString(seq iter.Seq2[*sx.Pair, *sx.Pair]) string {
+	var result []string
+	for node, alst := range seq {
+		var parents []string
+		for p := range zsx.GetWalkParents(alst).Values() {
+			parents = append(parents, zsx.NodeSymbol(p.(*sx.Pair)).GetValue())
+		}
+		result = append(result, fmt.Sprintf("%v@%d/%s", zsx.NodeSymbol(node), zsx.GetWalkPos(alst), strings.Join(parents, "/")))
+	}
+	return strings.Join(result, " ")
+}
+
+func TestIterators(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		seq  iter.Seq2[*sx.Pair, *sx.Pair]
+		exp  string
+	}{
+		{"all", zsx.All(makeIterTree()),
+			"BLOCK@-1/ HEADING@0/BLOCK TEXT@0/HEADING/BLOCK PARA@1/BLOCK LINK@0/PARA/BLOCK TEXT@0/LINK/PARA/BLOCK TEXT@1/PARA/BLOCK SOFT@2/PARA/BLOCK"},
+		{"post", zsx.AllPostOrder(makeIterTree()),
+			"TEXT@0/HEADING/BLOCK HEADING@0/BLOCK TEXT@0/LINK/PARA/BLOCK LINK@0/PARA/BLOCK TEXT@1/PARA/BLOCK SOFT@2/PARA/BLOCK PARA@1/BLOCK BLOCK@-1/"},
+		{"symbol", zsx.AllWithSymbol(makeIterTree(), zsx.SymText, zsx.SymHeading),
+			"HEADING@0/BLOCK TEXT@0/HEADING/BLOCK TEXT@0/LINK/PARA/BLOCK TEXT@1/PARA/BLOCK"},
+		{"splice", zsx.All(makeSpliceTree()),
+			"PARA@-1/ TEXT@0/PARA TEXT@1/PARA TEXT@2/PARA TEXT@3/PARA SOFT@4/PARA TEXT@5/PARA"},
+		{"splice-post", zsx.AllPostOrder(makeSpliceTree()),
+			"TEXT@0/PARA TEXT@1/PARA TEXT@2/PARA TEXT@3/PARA SOFT@4/PARA TEXT@5/PARA PARA@-1/"},
+		{"none", zsx.AllWithSymbol(makeIterTree(), zsx.SymTable), ""},
+		{"nil", zsx.All(nil), ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := iterString(tc.seq); got != tc.exp {
+				t.Errorf("\nexp: %s\ngot: %s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestIteratorsBreak(t *testing.T) {
+	t.Parallel()
+	seqs := []iter.Seq2[*sx.Pair, *sx.Pair]{
+		zsx.All(makeIterTree()),
+		zsx.AllPostOrder(makeIterTree()),
+		zsx.AllWithSymbol(makeIterTree(), zsx.SymText),
+	}
+	for _, seq := range seqs {
+		count := 0
+		for range seq {
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		if count != 2 {
+			t.Errorf("iteration should stop after 2 nodes, but got %d", count)
+		}
+	}
+}
+
+func TestAllLinks(t *testing.T) {
+	t.Parallel()
+	var refs []string
+	for node := range zsx.AllWithSymbol(makeIterTree(), zsx.SymLink) {
+		_, ref, _ := zsx.GetLink(node)
+		_, val := zsx.GetReference(ref)
+		refs = append(refs, val)
+	}
+	if got := strings.Join(refs, ","); got != "b" {
+		t.Errorf("exp=%q, got=%q", "b", got)
+	}
+}
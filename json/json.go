@@ -0,0 +1,432 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package json provides an encoder and a decoder for a JSON representation
+// of zsx nodes, e.g. for clients that cannot process s-expressions.
+//
+// Every node is a JSON object. Its member "type" contains the node symbol,
+// e.g. "PARA" or "FORMAT-EMPH". All other members are named after the
+// arguments of the corresponding Make function:
+//
+//   - "attrs" is an array of attributes. Each attribute is an object with
+//     the string members "key" and "value". If the key is a symbol, like
+//     the "align" key of table cells, the member "symbol" is true.
+//   - "blocks", "inlines", "items", "elements", "entries", "rows",
+//     "cells", and "nodes" are arrays of nodes.
+//   - "header" is a ROW node or null.
+//   - "level" is the number of a heading level.
+//   - "ref" is a reference, an object with the string members "state" and
+//     "value".
+//   - "content", "text", "syntax", "key", "mark", and "data" are strings.
+//     The data of a BLOB is encoded in the same way as within the node:
+//     base64 encoded, or verbatim for SVG data.
+//
+// Decoding the encoded node results in an equal node. Attributes are
+// decoded as dotted pairs, references as a list, which is the form produced
+// by all builders and parsers.
+//
+// Custom nodes, registered with [zsx.RegisterLayout], are represented in
+// the same way. Their members are named after the elements of their layout.
+//
+// The JSON Schema of this representation is available as [Schema]. It
+// describes only the predefined nodes.
+package json
+
+import (
+	_ "embed" // Needed for embedding the schema
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Schema is the JSON Schema of the JSON representation.
+//
+//go:embed schema.json
+var Schema string
+
+// fieldKind specifies how a field of a node is represented.
+type fieldKind uint8
+
+const (
+	kindAttrs  fieldKind = iota // Element is an attribute list
+	kindInt                     // Element is an integer number
+	kindList                    // Element is a list of nodes
+	kindOpt                     // Element is a node, or nil
+	kindRef                     // Element is a reference
+	kindRest                    // All remaining elements are nodes
+	kindString                  // Element is a string
+)
+
+type field struct {
+	name string
+	kind fieldKind
+}
+
+// spliceShape contains the fields of a splice node, which has no layout.
+var spliceShape = []field{{"nodes", kindRest}}
+
+// getShape returns the fields of a node, in the order of the node elements.
+// They are derived from the layout of the node.
+func getShape(sym *sx.Symbol) ([]field, bool) {
+	if zsx.SymSpecialSplice.IsEqualSymbol(sym) {
+		return spliceShape, true
+	}
+	layout, found := zsx.GetLayout(sym)
+	if !found {
+		return nil, false
+	}
+	shape := make([]field, len(layout.Elements))
+	for i, e := range layout.Elements {
+		shape[i] = field{e.Name, elementKinds[e.Kind]}
+	}
+	return shape, true
+}
+
+var elementKinds = [...]fieldKind{
+	zsx.ElementAttrs:  kindAttrs,
+	zsx.ElementInt:    kindInt,
+	zsx.ElementString: kindString,
+	zsx.ElementRef:    kindRef,
+	zsx.ElementList:   kindList,
+	zsx.ElementNode:   kindOpt,
+	zsx.ElementRest:   kindRest,
+}
+
+// Encode writes the JSON representation of the given node to the writer.
+// An error is returned, if the node or one of its descendants is not a
+// valid zsx node.
+func Encode(w io.Writer, node *sx.Pair) error {
+	var enc encoder
+	if err := enc.encodeNode(node); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, enc.sb.String())
+	return err
+}
+
+type encoder struct {
+	sb strings.Builder
+}
+
+func (enc *encoder) encodeNode(node *sx.Pair) error {
+	sym := zsx.NodeSymbol(node)
+	if sym == nil {
+		return fmt.Errorf("not a node: %v", node)
+	}
+	shape, found := getShape(sym)
+	if !found {
+		return fmt.Errorf("unknown node type: %v", sym)
+	}
+	enc.sb.WriteString(`{"type":`)
+	enc.writeString(sym.GetValue())
+	elems := node.Tail()
+	for _, f := range shape {
+		enc.sb.WriteString(",")
+		enc.writeString(f.name)
+		enc.sb.WriteString(":")
+		if f.kind == kindRest {
+			if err := enc.encodeNodes(elems); err != nil {
+				return err
+			}
+			elems = nil
+			continue
+		}
+		if elems == nil && f.kind != kindAttrs && f.kind != kindOpt && f.kind != kindList {
+			return fmt.Errorf("%v: missing %s", sym, f.name)
+		}
+		if err := enc.encodeField(f, elems.Car()); err != nil {
+			return fmt.Errorf("%v: %s: %w", sym, f.name, err)
+		}
+		elems = elems.Tail()
+	}
+	if elems != nil {
+		return fmt.Errorf("%v: superfluous elements: %v", sym, elems)
+	}
+	enc.sb.WriteString("}")
+	return nil
+}
+
+func (enc *encoder) encodeField(f field, obj sx.Object) error {
+	switch f.kind {
+	case kindAttrs:
+		return enc.encodeAttributes(obj)
+	case kindInt:
+		if num, isNum := sx.GetNumber(obj); isNum {
+			if val, isInt := num.(sx.Int64); isInt {
+				enc.sb.WriteString(strconv.FormatInt(int64(val), 10))
+				return nil
+			}
+		}
+		return fmt.Errorf("not an integer: %v", obj)
+	case kindList:
+		if lst, isPair := sx.GetPair(obj); isPair {
+			return enc.encodeNodes(lst)
+		}
+		return fmt.Errorf("not a list: %v", obj)
+	case kindOpt:
+		if sx.IsNil(obj) {
+			enc.sb.WriteString("null")
+			return nil
+		}
+		if node, isPair := sx.GetPair(obj); isPair {
+			return enc.encodeNode(node)
+		}
+		return fmt.Errorf("not a node: %v", obj)
+	case kindRef:
+		if ref, isPair := sx.GetPair(obj); isPair {
+			if sym, val := zsx.GetReference(ref); sym != nil {
+				enc.sb.WriteString(`{"state":`)
+				enc.writeString(sym.GetValue())
+				enc.sb.WriteString(`,"value":`)
+				enc.writeString(val)
+				enc.sb.WriteString("}")
+				return nil
+			}
+		}
+		return fmt.Errorf("not a reference: %v", obj)
+	case kindString:
+		if s, isString := sx.GetString(obj); isString {
+			enc.writeString(s.GetValue())
+			return nil
+		}
+		return fmt.Errorf("not a string: %v", obj)
+	}
+	panic(fmt.Sprintf("unknown field kind %d", f.kind))
+}
+
+func (enc *encoder) encodeNodes(lst *sx.Pair) error {
+	enc.sb.WriteString("[")
+	first := true
+	for obj := range lst.Values() {
+		if !first {
+			enc.sb.WriteString(",")
+		}
+		first = false
+		node, isPair := sx.GetPair(obj)
+		if !isPair {
+			return fmt.Errorf("not a node: %v", obj)
+		}
+		if err := enc.encodeNode(node); err != nil {
+			return err
+		}
+	}
+	enc.sb.WriteString("]")
+	return nil
+}
+
+func (enc *encoder) encodeAttributes(obj sx.Object) error {
+	attrs, isPair := sx.GetPair(obj)
+	if !isPair {
+		return fmt.Errorf("not an attribute list: %v", obj)
+	}
+	enc.sb.WriteString("[")
+	first := true
+	for elem := range attrs.Values() {
+		pair, isPair := sx.GetPair(elem)
+		if !isPair || pair == nil {
+			return fmt.Errorf("not an attribute: %v", elem)
+		}
+		obj := pair.Cdr()
+		if tail, isTail := sx.GetPair(obj); isTail && tail != nil {
+			obj = tail.Car() // list form (key value)
+		}
+		val, isString := sx.GetString(obj)
+		if !isString {
+			return fmt.Errorf("not a string attribute value: %v", pair)
+		}
+		if !first {
+			enc.sb.WriteString(",")
+		}
+		first = false
+		enc.sb.WriteString(`{"key":`)
+		switch key := pair.Car().(type) {
+		case *sx.Symbol:
+			enc.writeString(key.GetValue())
+			enc.sb.WriteString(`,"symbol":true`)
+		case sx.String:
+			enc.writeString(key.GetValue())
+		default:
+			return fmt.Errorf("not an attribute key: %v", key)
+		}
+		enc.sb.WriteString(`,"value":`)
+		enc.writeString(val.GetValue())
+		enc.sb.WriteString("}")
+	}
+	enc.sb.WriteString("]")
+	return nil
+}
+
+// writeString writes the string as a JSON string. In contrast to the
+// standard library, HTML characters are not escaped.
+func (enc *encoder) writeString(s string) {
+	enc.sb.WriteByte('"')
+	for i := 0; i < len(s); {
+		ch, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case ch == '"' || ch == '\\':
+			enc.sb.WriteByte('\\')
+			enc.sb.WriteRune(ch)
+		case ch == '\n':
+			enc.sb.WriteString(`\n`)
+		case ch == '\r':
+			enc.sb.WriteString(`\r`)
+		case ch == '\t':
+			enc.sb.WriteString(`\t`)
+		case ch < 0x20 || ch == '\u2028' || ch == '\u2029':
+			fmt.Fprintf(&enc.sb, `\u%04x`, ch)
+		case ch == utf8.RuneError && size == 1:
+			enc.sb.WriteString(`\ufffd`)
+		default:
+			enc.sb.WriteRune(ch)
+		}
+		i += size
+	}
+	enc.sb.WriteByte('"')
+}
+
+// Decode reads the JSON representation of a node from the reader and
+// returns the node.
+func Decode(r io.Reader) (*sx.Pair, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	return decodeNode(val)
+}
+
+func decodeNode(val any) (*sx.Pair, error) {
+	obj, isObject := val.(map[string]any)
+	if !isObject {
+		return nil, fmt.Errorf("node must be an object, but got: %v", val)
+	}
+	typ, isString := obj["type"].(string)
+	if !isString {
+		return nil, fmt.Errorf("node without type: %v", val)
+	}
+	sym := sx.MakeSymbol(typ)
+	shape, found := getShape(sym)
+	if !found {
+		return nil, fmt.Errorf("unknown node type: %q", typ)
+	}
+	var lb sx.ListBuilder
+	lb.Add(sym)
+	for _, f := range shape {
+		if f.kind == kindRest {
+			nodes, err := decodeNodes(obj[f.name])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", typ, f.name, err)
+			}
+			for node := range nodes.Values() {
+				lb.Add(node)
+			}
+			continue
+		}
+		elem, err := decodeField(f, obj[f.name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", typ, f.name, err)
+		}
+		lb.Add(elem)
+	}
+	return lb.List(), nil
+}
+
+func decodeField(f field, val any) (sx.Object, error) {
+	switch f.kind {
+	case kindAttrs:
+		return decodeAttributes(val)
+	case kindInt:
+		if num, isNum := val.(json.Number); isNum {
+			if i, err := num.Int64(); err == nil {
+				return sx.Int64(i), nil
+			}
+		}
+		return nil, fmt.Errorf("not an integer: %v", val)
+	case kindList:
+		return decodeNodes(val)
+	case kindOpt:
+		if val == nil {
+			return sx.Nil(), nil
+		}
+		return decodeNode(val)
+	case kindRef:
+		if obj, isObject := val.(map[string]any); isObject {
+			state, isState := obj["state"].(string)
+			value, isValue := obj["value"].(string)
+			if isState && isValue && state != "" {
+				return zsx.MakeReference(sx.MakeSymbol(state), value), nil
+			}
+		}
+		return nil, fmt.Errorf("not a reference: %v", val)
+	case kindString:
+		if s, isString := val.(string); isString {
+			return sx.MakeString(s), nil
+		}
+		return nil, fmt.Errorf("not a string: %v", val)
+	}
+	panic(fmt.Sprintf("unknown field kind %d", f.kind))
+}
+
+func decodeNodes(val any) (*sx.Pair, error) {
+	if val == nil {
+		return nil, nil
+	}
+	arr, isArray := val.([]any)
+	if !isArray {
+		return nil, fmt.Errorf("not an array: %v", val)
+	}
+	var lb sx.ListBuilder
+	for _, elem := range arr {
+		node, err := decodeNode(elem)
+		if err != nil {
+			return nil, err
+		}
+		lb.Add(node)
+	}
+	return lb.List(), nil
+}
+
+func decodeAttributes(val any) (*sx.Pair, error) {
+	if val == nil {
+		return nil, nil
+	}
+	arr, isArray := val.([]any)
+	if !isArray {
+		return nil, fmt.Errorf("not an array: %v", val)
+	}
+	var lb sx.ListBuilder
+	for _, elem := range arr {
+		obj, isObject := elem.(map[string]any)
+		if !isObject {
+			return nil, fmt.Errorf("not an attribute: %v", elem)
+		}
+		key, isKey := obj["key"].(string)
+		value, isValue := obj["value"].(string)
+		if !isKey || !isValue {
+			return nil, fmt.Errorf("not an attribute: %v", elem)
+		}
+		var keyObj sx.Object = sx.MakeString(key)
+		if isSymbol, _ := obj["symbol"].(bool); isSymbol {
+			keyObj = sx.MakeSymbol(key)
+		}
+		lb.Add(sx.Cons(keyObj, sx.MakeString(value)))
+	}
+	return lb.List(), nil
+}
@@ -0,0 +1,219 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/json"
+	"t73f.de/r/zsx/markdown"
+	"t73f.de/r/zsx/zmk"
+)
+
+func roundTrip(t *testing.T, node *sx.Pair) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := json.Encode(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	enc := sb.String()
+	if !stdjson.Valid([]byte(enc)) {
+		t.Fatalf("invalid JSON: %s", enc)
+	}
+	got, err := json.Decode(strings.NewReader(enc))
+	if err != nil {
+		t.Fatalf("%v\njson: %s", err, enc)
+	}
+	if s1, s2 := node.String(), got.String(); s1 != s2 {
+		t.Errorf("\njson: %s\nexp:  %s\ngot:  %s", enc, s1, s2)
+	}
+	return enc
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name   string
+		src    string
+		parser func(*input.Input) *sx.Pair
+	}{
+		{"para", "a\nb\\\nc\n\nd", zmk.ParseBlocks},
+		{"heading", "=== A {id=x}\n====== B", zmk.ParseBlocks},
+		{"lists", "* a\n** b\n# c\n> d", zmk.ParseBlocks},
+		{"description", "; a\n: b\n: c\n; d", zmk.ParseBlocks},
+		{"table", "|=a|=b>\n|c|:d\n|e", zmk.ParseBlocks},
+		{"regions", "<<<\na\n<<< b\n\n\"\"\"\nc\n\"\"\"\n\n:::{.x}\nd\n:::", zmk.ParseBlocks},
+		{"verbatim", "```go\n\"a\\\tb\"\n```\n\n$$$\nx\n$$$\n\n%%%\nc\n%%%", zmk.ParseBlocks},
+		{"formats", "__a__{lang=de} **b** >>c>> ~~d~~ ##e## ,,f,, ^^g^^ \"\"h\"\" ::i::", zmk.ParseBlocks},
+		{"literals", "``a`` ''b'' ==c== $$d$$ %% e", zmk.ParseBlocks},
+		{"references", "[[a|https://t73f.de]] [[b|./c]] [[d|:e]] {{f|img.png}} {{{g}}}", zmk.ParseBlocks},
+		{"inlines", "[@key p. 7] [^a] [!m|b] a&nbsp;b <\u2028>", zmk.ParseBlocks},
+		{"markdown", "# A\n\n- a\n\n  b\n\n1. `c` <d>\n\n```\ne\n```", markdown.ParseBlocks},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			roundTrip(t, tc.parser(input.NewInput([]byte(tc.src))))
+		})
+	}
+}
+
+func TestRoundTripBuild(t *testing.T) {
+	t.Parallel()
+	attrs := zsx.Attributes{"a": "b"}.AsAssoc()
+	cellAttrs := sx.MakeList(sx.Cons(zsx.SymAttrAlign, zsx.AttrAlignRight))
+	node := zsx.MakeBlock(
+		zsx.MakeBLOB(attrs, "png", []byte{0, 1, 2, 255}, sx.MakeList(zsx.MakeText("a"))),
+		zsx.MakeTable(nil, nil, sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(cellAttrs, nil))))),
+		zsx.MakePara(
+			zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), nil),
+			zsx.MakeEmbed(nil, zsx.MakeReference(zsx.SymRefStateHosted, "x.png"), "", nil),
+			zsx.MakeText("\x01\"\\</>\t\ufffd"),
+		),
+		zsx.MakeHeading(nil, 3, nil),
+		zsx.MakeThematic(nil),
+		sx.MakeList(zsx.SymSpecialSplice, zsx.MakeText("s")),
+	)
+	enc := roundTrip(t, node)
+	if exp := `"cells":[{"type":"CELL","attrs":[{"key":"align","symbol":true,"value":"right"}],"inlines":[]}]`; !strings.Contains(enc, exp) {
+		t.Errorf("%q not found in %s", exp, enc)
+	}
+	if exp := `{"type":"HEADING","attrs":[],"level":3,"inlines":[]}`; !strings.Contains(enc, exp) {
+		t.Errorf("%q not found in %s", exp, enc)
+	}
+	if exp := `"header":null`; !strings.Contains(enc, exp) {
+		t.Errorf("%q not found in %s", exp, enc)
+	}
+}
+
+var symTask = sx.MakeSymbol("TASK")
+
+func init() {
+	err := zsx.RegisterLayout(symTask, zsx.Layout{
+		Context: zsx.ContextItem,
+		Elements: []zsx.Element{
+			{Name: "attrs", Kind: zsx.ElementAttrs},
+			{Name: "state", Kind: zsx.ElementString},
+			{Name: "due", Kind: zsx.ElementNode, Context: zsx.ContextInline},
+			{Name: "blocks", Kind: zsx.ElementRest, Context: zsx.ContextBlock},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRoundTripCustom(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeList(zsx.SymListUnordered, nil, sx.MakeList(
+		sx.MakeList(symTask, sx.Nil(), sx.MakeString("done"), zsx.MakeText("today"), zsx.MakePara(zsx.MakeText("a"))),
+		sx.MakeList(symTask, sx.Nil(), sx.MakeString("open"), sx.Nil()),
+	))
+	enc := roundTrip(t, node)
+	if exp := `{"type":"TASK","attrs":[],"state":"open","due":null,"blocks":[]}`; !strings.Contains(enc, exp) {
+		t.Errorf("%q not found in %s", exp, enc)
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		node *sx.Pair
+	}{
+		{"no-node", sx.MakeList(sx.MakeString("a"))},
+		{"unknown", sx.MakeList(sx.MakeSymbol("UNKNOWN"))},
+		{"missing", sx.MakeList(zsx.SymText)},
+		{"superfluous", sx.MakeList(zsx.SymSoft, sx.MakeString("a"))},
+		{"level", sx.MakeList(zsx.SymHeading, sx.Nil(), sx.MakeString("1"))},
+		{"ref", sx.MakeList(zsx.SymLink, sx.Nil(), sx.MakeString("a"))},
+		{"attrs", sx.MakeList(zsx.SymThematic, sx.MakeList(sx.MakeString("a")))},
+		{"child", zsx.MakePara(sx.MakeList(sx.MakeSymbol("UNKNOWN")))},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := json.Encode(&sb, tc.node); err == nil {
+				t.Errorf("error expected, but got: %s", sb.String())
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+	}{
+		{"syntax", `{"type":`},
+		{"no-object", `[]`},
+		{"no-type", `{}`},
+		{"unknown", `{"type":"UNKNOWN"}`},
+		{"text", `{"type":"TEXT","text":1}`},
+		{"level", `{"type":"HEADING","level":1.5}`},
+		{"ref", `{"type":"LINK","ref":{"value":"a"}}`},
+		{"attrs", `{"type":"THEMATIC","attrs":[{"key":"a"}]}`},
+		{"nodes", `{"type":"PARA","inlines":{}}`},
+		{"child", `{"type":"PARA","inlines":[{"type":"UNKNOWN"}]}`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if node, err := json.Decode(strings.NewReader(tc.src)); err == nil {
+				t.Errorf("error expected, but got: %v", node)
+			}
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := stdjson.Unmarshal([]byte(json.Schema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	syms := []*sx.Symbol{
+		zsx.SymBlock, zsx.SymInline, zsx.SymPara, zsx.SymHeading, zsx.SymThematic,
+		zsx.SymListOrdered, zsx.SymListUnordered, zsx.SymListQuote, zsx.SymListItem,
+		zsx.SymDescription, zsx.SymTerm, zsx.SymDetail, zsx.SymEntry,
+		zsx.SymTable, zsx.SymRow, zsx.SymCell,
+		zsx.SymRegionBlock, zsx.SymRegionQuote, zsx.SymRegionVerse,
+		zsx.SymVerbatimCode, zsx.SymVerbatimComment, zsx.SymVerbatimEval,
+		zsx.SymVerbatimHTML, zsx.SymVerbatimMath, zsx.SymVerbatimZettel,
+		zsx.SymTransclude, zsx.SymBLOB, zsx.SymText, zsx.SymSoft, zsx.SymHard,
+		zsx.SymLink, zsx.SymEmbed, zsx.SymEmbedBLOB, zsx.SymCite, zsx.SymEndnote, zsx.SymMark,
+		zsx.SymFormatDelete, zsx.SymFormatEmph, zsx.SymFormatInsert, zsx.SymFormatMark,
+		zsx.SymFormatQuote, zsx.SymFormatSpan, zsx.SymFormatStrong, zsx.SymFormatSub, zsx.SymFormatSuper,
+		zsx.SymLiteralCode, zsx.SymLiteralComment, zsx.SymLiteralInput, zsx.SymLiteralMath, zsx.SymLiteralOutput,
+		zsx.SymSpecialSplice,
+	}
+	for _, sym := range syms {
+		def, found := schema.Defs[sym.GetValue()]
+		if !found {
+			t.Errorf("no schema definition for %v", sym)
+			continue
+		}
+		if _, found = def.Properties["type"]; !found {
+			t.Errorf("no type property for %v", sym)
+		}
+	}
+}
@@ -0,0 +1,601 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package latex provides an encoder that writes zsx nodes as LaTeX. The
+// result is a document body, which must be embedded into a document that
+// loads the packages graphicx, hyperref, ulem (with option normalem), and
+// xcolor.
+//
+// Headings are written as sectioning commands, lists and description lists
+// as environments, and endnotes as footnotes. Math content is written
+// unchanged. The content of BLOBs is stored in side files by a callback.
+// Verbatim HTML has no meaning in LaTeX and is ignored.
+package latex
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// BLOBFunc stores the data of a BLOB with the given syntax, typically in a
+// file. It returns the file name that is used to include the BLOB.
+type BLOBFunc func(syntax string, data []byte) (string, error)
+
+// Encode writes the given node as LaTeX to the writer. Typically, the node is
+// a BLOCK or an INLINE node.
+//
+// BLOBs are stored by calling the given function. If it is nil, only the
+// description of a BLOB is written.
+func Encode(w io.Writer, node *sx.Pair, blobFunc BLOBFunc) error {
+	enc := encoder{w: w, blobFunc: blobFunc}
+	zsx.WalkIt(&enc, node, nil)
+	return enc.err
+}
+
+type encoder struct {
+	w         io.Writer
+	err       error
+	blobFunc  BLOBFunc
+	inVerse   bool // Soft line breaks are written as hard line breaks
+	inCell    bool // Line breaks are written as spaces
+	enumDepth int  // Nesting depth of enumerate environments
+}
+
+var encodeFuncs map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair)
+
+func init() {
+	encodeFuncs = map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair){
+		zsx.SymBlock:           (*encoder).encodeBlock,
+		zsx.SymPara:            (*encoder).encodePara,
+		zsx.SymHeading:         (*encoder).encodeHeading,
+		zsx.SymThematic:        (*encoder).encodeThematic,
+		zsx.SymListOrdered:     (*encoder).encodeList,
+		zsx.SymListUnordered:   (*encoder).encodeList,
+		zsx.SymListQuote:       (*encoder).encodeQuotation,
+		zsx.SymDescription:     (*encoder).encodeDescription,
+		zsx.SymTable:           (*encoder).encodeTable,
+		zsx.SymRegionBlock:     (*encoder).encodeRegion,
+		zsx.SymRegionQuote:     (*encoder).encodeRegion,
+		zsx.SymRegionVerse:     (*encoder).encodeRegion,
+		zsx.SymVerbatimCode:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimComment: (*encoder).encodeVerbatimComment,
+		zsx.SymVerbatimEval:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimMath:    (*encoder).encodeVerbatimMath,
+		zsx.SymVerbatimZettel:  (*encoder).encodeVerbatim,
+		zsx.SymTransclude:      (*encoder).encodeTransclusion,
+		zsx.SymBLOB:            (*encoder).encodeBLOB,
+
+		zsx.SymInline:        (*encoder).encodeInline,
+		zsx.SymText:          (*encoder).encodeText,
+		zsx.SymSoft:          (*encoder).encodeSoft,
+		zsx.SymHard:          (*encoder).encodeHard,
+		zsx.SymLink:          (*encoder).encodeLink,
+		zsx.SymEmbed:         (*encoder).encodeEmbed,
+		zsx.SymEmbedBLOB:     (*encoder).encodeEmbedBLOB,
+		zsx.SymCite:          (*encoder).encodeCite,
+		zsx.SymEndnote:       (*encoder).encodeEndnote,
+		zsx.SymMark:          (*encoder).encodeMark,
+		zsx.SymFormatDelete:  (*encoder).encodeFormat,
+		zsx.SymFormatEmph:    (*encoder).encodeFormat,
+		zsx.SymFormatInsert:  (*encoder).encodeFormat,
+		zsx.SymFormatMark:    (*encoder).encodeFormat,
+		zsx.SymFormatQuote:   (*encoder).encodeQuote,
+		zsx.SymFormatSpan:    (*encoder).encodeInline,
+		zsx.SymFormatStrong:  (*encoder).encodeFormat,
+		zsx.SymFormatSub:     (*encoder).encodeFormat,
+		zsx.SymFormatSuper:   (*encoder).encodeFormat,
+		zsx.SymLiteralCode:   (*encoder).encodeLiteral,
+		zsx.SymLiteralInput:  (*encoder).encodeLiteral,
+		zsx.SymLiteralMath:   (*encoder).encodeLiteralMath,
+		zsx.SymLiteralOutput: (*encoder).encodeLiteral,
+		zsx.SymSpecialSplice: (*encoder).encodeInline,
+	}
+}
+
+func (enc *encoder) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	if fn, found := encodeFuncs[zsx.NodeSymbol(node)]; found {
+		fn(enc, node, alst)
+	} else {
+		enc.encodeOther(node, alst)
+	}
+	return true
+}
+
+func (*encoder) VisitItAfter(*sx.Pair, *sx.Pair) {}
+
+func (enc *encoder) write(s string) {
+	if enc.err == nil {
+		_, enc.err = io.WriteString(enc.w, s)
+	}
+}
+
+// textEscaper escapes all characters that have a special meaning in LaTeX.
+// Brackets are escaped, so that they are not treated as an optional
+// argument.
+var textEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"#", `\#`,
+	"$", `\$`,
+	"%", `\%`,
+	"&", `\&`,
+	"_", `\_`,
+	"^", `\textasciicircum{}`,
+	"~", `\textasciitilde{}`,
+	"<", `\textless{}`,
+	">", `\textgreater{}`,
+	"|", `\textbar{}`,
+	"[", "{[}",
+	"]", "{]}",
+	"\u00a0", "~",
+)
+
+// writeEscaped writes the escaped text. Hyphens are separated from each
+// other, so that no dash ligature is produced. Since ReplaceAll does not
+// handle overlapping matches, this needs two passes.
+func (enc *encoder) writeEscaped(s string) {
+	s = textEscaper.Replace(s)
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "--", "-{}-"), "--", "-{}-")
+	enc.write(s)
+}
+
+// writeBlocks writes the block nodes, separated by an empty line.
+func (enc *encoder) writeBlocks(blocks *sx.Pair, alst *sx.Pair) {
+	for bn := range blocks.Pairs() {
+		if bn != blocks {
+			enc.write("\n\n")
+		}
+		zsx.WalkIt(enc, bn.Head(), alst)
+	}
+}
+
+func (enc *encoder) walkInlines(ins *sx.Pair, alst *sx.Pair) { zsx.WalkItList(enc, ins, 0, alst) }
+
+// writeCommand writes a LaTeX command with the inline nodes as its argument.
+func (enc *encoder) writeCommand(cmd string, ins *sx.Pair, alst *sx.Pair) {
+	enc.write(`\` + cmd + "{")
+	enc.walkInlines(ins, alst)
+	enc.write("}")
+}
+
+// writeLabel writes a label for the value of the "id" attribute.
+func (enc *encoder) writeLabel(attrs *sx.Pair) {
+	if id, found := zsx.GetAttributes(attrs).Get("id"); found && id != "" {
+		enc.write(`\label{` + labelEscaper.Replace(id) + "}")
+	}
+}
+
+// labelEscaper removes all characters that are not allowed within a label.
+var labelEscaper = strings.NewReplacer(`\`, "", "{", "", "}", "", "#", "", "%", "", "$", "", "^", "", "~", "")
+
+// encodeOther writes the child nodes of a custom node. Unknown nodes are
+// ignored.
+func (enc *encoder) encodeOther(node *sx.Pair, alst *sx.Pair) {
+	layout, _ := zsx.GetLayout(zsx.NodeSymbol(node))
+	first := true
+	for ctx, lst := range zsx.LayoutChildren(node) {
+		if layout.Context == zsx.ContextInline {
+			enc.walkInlines(lst, alst)
+			continue
+		}
+		if !first {
+			enc.write("\n\n")
+		}
+		if ctx == zsx.ContextInline {
+			enc.walkInlines(lst, alst)
+		} else {
+			enc.writeBlocks(lst, alst)
+		}
+		first = false
+	}
+}
+
+func (enc *encoder) encodeBlock(node *sx.Pair, alst *sx.Pair) {
+	enc.writeBlocks(zsx.GetBlock(node), alst)
+}
+
+func (enc *encoder) encodePara(node *sx.Pair, alst *sx.Pair) {
+	enc.walkInlines(zsx.GetPara(node), alst)
+}
+
+// sectionCommands contains the sectioning command for each heading level.
+var sectionCommands = []string{"section", "subsection", "subsubsection", "paragraph", "subparagraph"}
+
+func (enc *encoder) encodeHeading(node *sx.Pair, alst *sx.Pair) {
+	attrs, level, ins := zsx.GetHeading(node)
+	enc.writeCommand(sectionCommands[min(max(level, 1), len(sectionCommands))-1], ins, alst)
+	enc.writeLabel(attrs)
+}
+
+func (enc *encoder) encodeThematic(*sx.Pair, *sx.Pair) {
+	enc.write(`\begin{center}\rule{0.5\linewidth}{0.4pt}\end{center}`)
+}
+
+// enumCounters contains the counter of each nesting level of an enumerate
+// environment.
+var enumCounters = []string{"enumi", "enumii", "enumiii", "enumiv"}
+
+func (enc *encoder) encodeList(node *sx.Pair, alst *sx.Pair) {
+	sym, attrs, items := zsx.GetList(node)
+	env := "itemize"
+	if sym == zsx.SymListOrdered {
+		env = "enumerate"
+		enc.enumDepth++
+		defer func() { enc.enumDepth-- }()
+	}
+	enc.write(`\begin{` + env + "}")
+	if sym == zsx.SymListOrdered {
+		if val, found := zsx.GetAttributes(attrs).Get("start"); found {
+			if start, err := strconv.Atoi(val); err == nil {
+				counter := enumCounters[min(enc.enumDepth, len(enumCounters))-1]
+				enc.write("\n" + `\setcounter{` + counter + `}{` + strconv.Itoa(start-1) + "}")
+			}
+		}
+	}
+	for item := range items.Values() {
+		if itemNode, isPair := sx.GetPair(item); isPair {
+			_, elems := zsx.GetListItem(itemNode)
+			enc.write("\n" + `\item `)
+			enc.writeBlocks(elems, alst)
+		}
+	}
+	enc.write("\n" + `\end{` + env + "}")
+}
+
+func (enc *encoder) encodeQuotation(node *sx.Pair, alst *sx.Pair) {
+	_, _, items := zsx.GetList(node)
+	enc.write(`\begin{quote}` + "\n")
+	first := true
+	for item := range items.Values() {
+		if itemNode, isPair := sx.GetPair(item); isPair {
+			if !first {
+				enc.write("\n\n")
+			}
+			_, elems := zsx.GetListItem(itemNode)
+			enc.writeBlocks(elems, alst)
+			first = false
+		}
+	}
+	enc.write("\n" + `\end{quote}`)
+}
+
+func (enc *encoder) encodeDescription(node *sx.Pair, alst *sx.Pair) {
+	_, elems := zsx.GetDescription(node)
+	enc.write(`\begin{description}`)
+	for elem := range elems.Values() {
+		elemNode, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		switch zsx.NodeSymbol(elemNode) {
+		case zsx.SymTerm:
+			_, ins := zsx.GetTerm(elemNode)
+			enc.write("\n" + `\item[`)
+			enc.walkInlines(ins, alst)
+			enc.write("]")
+		case zsx.SymDetail:
+			for entry := range zsx.GetDetail(elemNode).Values() {
+				if entryNode, isEntry := sx.GetPair(entry); isEntry {
+					_, blocks := zsx.GetEntry(entryNode)
+					enc.write("\n")
+					enc.writeBlocks(blocks, alst)
+					enc.write("\n")
+				}
+			}
+		}
+	}
+	enc.write("\n" + `\end{description}`)
+}
+
+func (enc *encoder) encodeTable(node *sx.Pair, alst *sx.Pair) {
+	_, header, rows := zsx.GetTable(node)
+
+	// The alignment of a column is taken from its first cell with an
+	// alignment.
+	var aligns []string
+	for row := range rows.Cons(header).Values() {
+		rowNode, isPair := sx.GetPair(row)
+		if !isPair || rowNode == nil {
+			continue
+		}
+		_, cells := zsx.GetRow(rowNode)
+		i := 0
+		for cell := range cells.Values() {
+			if cellNode, isCell := sx.GetPair(cell); isCell {
+				attrs, _ := zsx.GetCell(cellNode)
+				align, _ := zsx.GetAttributes(attrs).Get(zsx.SymAttrAlign.GetValue())
+				if i >= len(aligns) {
+					aligns = append(aligns, "")
+				}
+				if aligns[i] == "" {
+					aligns[i] = align
+				}
+				i++
+			}
+		}
+	}
+	var spec strings.Builder
+	for _, align := range aligns {
+		switch align {
+		case zsx.AttrAlignCenter.GetValue():
+			spec.WriteByte('c')
+		case zsx.AttrAlignRight.GetValue():
+			spec.WriteByte('r')
+		default:
+			spec.WriteByte('l')
+		}
+	}
+
+	enc.write(`\begin{tabular}{` + spec.String() + "}")
+	enc.inCell = true
+	if header != nil {
+		enc.writeRow(header, alst)
+		enc.write("\n" + `\hline`)
+	}
+	for row := range rows.Values() {
+		if rowNode, isPair := sx.GetPair(row); isPair {
+			enc.writeRow(rowNode, alst)
+		}
+	}
+	enc.inCell = false
+	enc.write("\n" + `\end{tabular}`)
+}
+
+func (enc *encoder) writeRow(row *sx.Pair, alst *sx.Pair) {
+	_, cells := zsx.GetRow(row)
+	enc.write("\n")
+	for cn := range cells.Pairs() {
+		if cn != cells {
+			enc.write(" & ")
+		}
+		if cellNode, isCell := sx.GetPair(cn.Car()); isCell {
+			_, ins := zsx.GetCell(cellNode)
+			enc.walkInlines(ins, alst)
+		}
+	}
+	enc.write(` \\`)
+}
+
+var regionEnvironments = map[*sx.Symbol]string{
+	zsx.SymRegionQuote: "quote",
+	zsx.SymRegionVerse: "verse",
+}
+
+func (enc *encoder) encodeRegion(node *sx.Pair, alst *sx.Pair) {
+	sym, _, blocks, ins := zsx.GetRegion(node)
+	env, found := regionEnvironments[sym]
+	if found {
+		enc.write(`\begin{` + env + "}\n")
+	}
+	if sym == zsx.SymRegionVerse {
+		enc.inVerse = true
+		enc.writeBlocks(blocks, alst)
+		enc.inVerse = false
+	} else {
+		enc.writeBlocks(blocks, alst)
+	}
+	if ins != nil {
+		enc.write("\n\n" + `\hfill--- `)
+		enc.walkInlines(ins, alst)
+	}
+	if found {
+		enc.write("\n" + `\end{` + env + "}")
+	}
+}
+
+// encodeVerbatim writes the content within a verbatim environment. If the
+// content would end that environment, it is written as escaped text in a
+// typewriter font instead, where spaces are not collapsed.
+func (enc *encoder) encodeVerbatim(node *sx.Pair, _ *sx.Pair) {
+	_, _, content := zsx.GetVerbatim(node)
+	if strings.Contains(content, `\end{verbatim}`) {
+		enc.write(`\begin{flushleft}\ttfamily` + "\n")
+		for i, line := range strings.Split(content, "\n") {
+			if i > 0 {
+				enc.write(`\\` + "\n")
+			}
+			enc.write(`\mbox{}`)
+			enc.writeEscaped(spaceProtector.Replace(line))
+		}
+		enc.write("\n" + `\end{flushleft}`)
+		return
+	}
+	enc.write(`\begin{verbatim}` + "\n")
+	if content != "" {
+		enc.write(content + "\n")
+	}
+	enc.write(`\end{verbatim}`)
+}
+
+// spaceProtector replaces spaces by non-breaking spaces, which are escaped as
+// "~", so that they are not collapsed.
+var spaceProtector = strings.NewReplacer(" ", "\u00a0", "\t", "\u00a0")
+
+func (enc *encoder) encodeVerbatimMath(node *sx.Pair, _ *sx.Pair) {
+	_, _, content := zsx.GetVerbatim(node)
+	enc.write(`\[` + "\n" + content + "\n" + `\]`)
+}
+
+func (enc *encoder) encodeVerbatimComment(node *sx.Pair, _ *sx.Pair) {
+	_, _, content := zsx.GetVerbatim(node)
+	for i, line := range strings.Split(content, "\n") {
+		if i > 0 {
+			enc.write("\n")
+		}
+		enc.write("% " + line)
+	}
+}
+
+func (enc *encoder) encodeTransclusion(node *sx.Pair, alst *sx.Pair) {
+	_, ref, ins := zsx.GetTransclusion(node)
+	enc.writeLink(ref, ins, alst)
+}
+
+func (enc *encoder) encodeBLOB(node *sx.Pair, alst *sx.Pair) {
+	_, syntax, data, description := zsx.GetBLOB(node)
+	name := enc.storeBLOB(syntax, data)
+	if name == "" {
+		enc.walkInlines(description, alst)
+		return
+	}
+	enc.write(`\begin{figure}` + "\n" + `\centering` + "\n")
+	enc.write(`\includegraphics{` + urlEscaper.Replace(name) + "}")
+	if description != nil {
+		enc.write("\n")
+		enc.writeCommand("caption", description, alst)
+	}
+	enc.write("\n" + `\end{figure}`)
+}
+
+// storeBLOB calls the BLOB function and returns the file name. If no file
+// name is available, the empty string is returned.
+func (enc *encoder) storeBLOB(syntax string, data []byte) string {
+	if enc.blobFunc == nil || enc.err != nil {
+		return ""
+	}
+	name, err := enc.blobFunc(syntax, data)
+	if err != nil {
+		enc.err = err
+		return ""
+	}
+	return name
+}
+
+func (enc *encoder) encodeInline(node *sx.Pair, alst *sx.Pair) { zsx.WalkItList(enc, node, 1, alst) }
+
+func (enc *encoder) encodeText(node *sx.Pair, _ *sx.Pair) { enc.writeEscaped(zsx.GetText(node)) }
+
+func (enc *encoder) encodeSoft(node *sx.Pair, alst *sx.Pair) {
+	if enc.inVerse {
+		enc.encodeHard(node, alst)
+	} else {
+		enc.write("\n")
+	}
+}
+
+func (enc *encoder) encodeHard(*sx.Pair, *sx.Pair) {
+	if enc.inCell {
+		enc.write(" ")
+	} else {
+		enc.write(`\\` + "\n")
+	}
+}
+
+func (enc *encoder) encodeLink(node *sx.Pair, alst *sx.Pair) {
+	_, ref, ins := zsx.GetLink(node)
+	enc.writeLink(ref, ins, alst)
+}
+
+// urlEscaper escapes all characters of an URL that must be escaped within
+// the argument of a LaTeX command.
+var urlEscaper = strings.NewReplacer(`\`, `\\`, "#", `\#`, "%", `\%`, "{", `\{`, "}", `\}`)
+
+// writeLink writes a hyperlink. A link with an invalid reference is written
+// as text.
+func (enc *encoder) writeLink(ref *sx.Pair, ins *sx.Pair, alst *sx.Pair) {
+	sym, val := zsx.GetReference(ref)
+	switch {
+	case sym == zsx.SymRefStateInvalid:
+		if ins == nil {
+			enc.writeEscaped(val)
+		}
+		enc.walkInlines(ins, alst)
+	case ins == nil:
+		enc.write(`\url{` + urlEscaper.Replace(val) + "}")
+	default:
+		enc.write(`\href{` + urlEscaper.Replace(val) + "}{")
+		enc.walkInlines(ins, alst)
+		enc.write("}")
+	}
+}
+
+func (enc *encoder) encodeEmbed(node *sx.Pair, alst *sx.Pair) {
+	_, ref, _, ins := zsx.GetEmbed(node)
+	if sym, val := zsx.GetReference(ref); sym == zsx.SymRefStateHosted {
+		enc.write(`\includegraphics{` + urlEscaper.Replace(val) + "}")
+	} else {
+		enc.walkInlines(ins, alst)
+	}
+}
+
+func (enc *encoder) encodeEmbedBLOB(node *sx.Pair, alst *sx.Pair) {
+	_, syntax, data, ins := zsx.GetEmbedBLOB(node)
+	if name := enc.storeBLOB(syntax, data); name != "" {
+		enc.write(`\includegraphics{` + urlEscaper.Replace(name) + "}")
+	} else {
+		enc.walkInlines(ins, alst)
+	}
+}
+
+func (enc *encoder) encodeCite(node *sx.Pair, alst *sx.Pair) {
+	_, key, ins := zsx.GetCite(node)
+	enc.write(`\cite`)
+	if ins != nil {
+		enc.write("[")
+		enc.walkInlines(ins, alst)
+		enc.write("]")
+	}
+	enc.write("{" + labelEscaper.Replace(key) + "}")
+}
+
+func (enc *encoder) encodeEndnote(node *sx.Pair, alst *sx.Pair) {
+	_, ins := zsx.GetEndnote(node)
+	enc.writeCommand("footnote", ins, alst)
+}
+
+func (enc *encoder) encodeMark(node *sx.Pair, alst *sx.Pair) {
+	_, mark, ins := zsx.GetMark(node)
+	if mark != "" {
+		enc.write(`\label{` + labelEscaper.Replace(mark) + "}")
+	}
+	enc.walkInlines(ins, alst)
+}
+
+var formatCommands = map[*sx.Symbol]string{
+	zsx.SymFormatDelete: "sout",
+	zsx.SymFormatEmph:   "emph",
+	zsx.SymFormatInsert: "uline",
+	zsx.SymFormatMark:   "colorbox{yellow}",
+	zsx.SymFormatStrong: "textbf",
+	zsx.SymFormatSub:    "textsubscript",
+	zsx.SymFormatSuper:  "textsuperscript",
+}
+
+func (enc *encoder) encodeFormat(node *sx.Pair, alst *sx.Pair) {
+	sym, _, ins := zsx.GetFormat(node)
+	enc.writeCommand(formatCommands[sym], ins, alst)
+}
+
+func (enc *encoder) encodeQuote(node *sx.Pair, alst *sx.Pair) {
+	_, _, ins := zsx.GetFormat(node)
+	enc.write("``")
+	enc.walkInlines(ins, alst)
+	enc.write("''")
+}
+
+func (enc *encoder) encodeLiteral(node *sx.Pair, _ *sx.Pair) {
+	_, _, content := zsx.GetLiteral(node)
+	enc.write(`\texttt{`)
+	enc.writeEscaped(content)
+	enc.write("}")
+}
+
+func (enc *encoder) encodeLiteralMath(node *sx.Pair, _ *sx.Pair) {
+	_, _, content := zsx.GetLiteral(node)
+	enc.write(`\(` + content + `\)`)
+}
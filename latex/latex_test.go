@@ -0,0 +1,170 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package latex_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/internal/layouttest"
+	"t73f.de/r/zsx/latex"
+	"t73f.de/r/zsx/zmk"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+		exp  string
+	}{
+		{"para", "a\nb\\\nc\n\nd", "a\nb\\\\\nc\n\nd"},
+		{"escapes", `\\ {a} # $ % & _ ^ ~ < > | [b] c-\-\-d`,
+			`\textbackslash{} \{a\} \# \$ \% \& \_ \textasciicircum{} \textasciitilde{} \textless{} \textgreater{} \textbar{} {[}b{]} c-{}-{}-d`},
+		{"nbsp", "a&nbsp;b", "a~b"},
+		{"headings", "=== A {id=x}\n==== B\n======= C",
+			"\\section{A}\\label{x}\n\n\\subsection{B}\n\n\\subparagraph{C}"},
+		{"thematic", "---", `\begin{center}\rule{0.5\linewidth}{0.4pt}\end{center}`},
+		{"itemize", "* a\n** b\n* c",
+			"\\begin{itemize}\n\\item a\n\n\\begin{itemize}\n\\item b\n\\end{itemize}\n\\item c\n\\end{itemize}"},
+		{"enumerate", "# [b]", "\\begin{enumerate}\n\\item {[}b{]}\n\\end{enumerate}"},
+		{"quotation", "> a", "\\begin{quote}\na\n\\end{quote}"},
+		{"description", "; a\n: b\n: c\n; d",
+			"\\begin{description}\n\\item[a]\nb\n\nc\n\n\\item[d]\n\\end{description}"},
+		{"table", "|=a|=b>\n|c|d\n|e",
+			"\\begin{tabular}{lr}\na & b \\\\\n\\hline\nc & d \\\\\ne &  \\\\\n\\end{tabular}"},
+		{"region-quote", "<<<\na\n<<< b", "\\begin{quote}\na\n\n\\hfill--- b\n\\end{quote}"},
+		{"region-verse", "\"\"\"\na\nb\n\"\"\"", "\\begin{verse}\na\\\\\nb\n\\end{verse}"},
+		{"region-block", ":::\na\n:::", "a"},
+		{"verbatim", "```go\n\\x{}\n```", "\\begin{verbatim}\n\\x{}\n\\end{verbatim}"},
+		{"verbatim-end", "```\na\n\\end{verbatim}  b\n```",
+			"\\begin{flushleft}\\ttfamily\n\\mbox{}a\\\\\n\\mbox{}\\textbackslash{}end\\{verbatim\\}~~b\n\\end{flushleft}"},
+		{"verbatim-math", "$$$\n\\frac{a}{b}\n$$$", "\\[\n\\frac{a}{b}\n\\]"},
+		{"verbatim-comment", "%%%\na\nb\n%%%", "% a\n% b"},
+		{"verbatim-html", "```html\n<b>\n```", ""},
+		{"formats", "__a__ **b** >>c>> ~~d~~ ##e## ,,f,, ^^g^^ \"\"h\"\" ::i::",
+			"\\emph{a} \\textbf{b} \\uline{c} \\sout{d} \\colorbox{yellow}{e} \\textsubscript{f} \\textsuperscript{g} ``h'' i"},
+		{"literals", "``a_b`` ''c'' ==d== $$\\alpha_1$$ %% e",
+			"\\texttt{a\\_b} \\texttt{c} \\texttt{d} \\(\\alpha_1\\) "},
+		{"links", "[[a|https://t73f.de/#x%20]] [[https://t73f.de]] [[b|:c]]",
+			"\\href{https://t73f.de/\\#x\\%20}{a} \\url{https://t73f.de} b"},
+		{"embed", "{{a|img.png}} {{b|https://t73f.de/x.png}}", "\\includegraphics{img.png} b"},
+		{"cite", "[@key] [@key p. 7]", "\\cite{key} \\cite[p. 7]{key}"},
+		{"endnote", "a[^b __c__]", "a\\footnote{b \\emph{c}}"},
+		{"mark", "[!m|a]", "\\label{m}a"},
+		{"transclude", "{{{ref}}}", "\\url{ref}"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := zmk.ParseBlocks(input.NewInput([]byte(tc.src)))
+			var sb strings.Builder
+			if err := latex.Encode(&sb, tree, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %q\ngot: %q", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestEncodeEnumerateStart(t *testing.T) {
+	t.Parallel()
+	ordered := func(start string, items ...*sx.Pair) *sx.Pair {
+		var lb sx.ListBuilder
+		for _, item := range items {
+			lb.Add(zsx.MakeListItem(nil, sx.MakeList(item)))
+		}
+		return zsx.MakeList(zsx.SymListOrdered, zsx.Attributes{"start": start}.AsAssoc(), lb.List())
+	}
+	para := func(s string) *sx.Pair { return zsx.MakePara(zsx.MakeText(s)) }
+	node := zsx.MakeBlock(ordered("3",
+		zsx.MakeList(zsx.SymListUnordered, nil, sx.MakeList(zsx.MakeListItem(nil, sx.MakeList(
+			ordered("5", ordered("7", para("a"))),
+		)))),
+	), ordered("2", para("b")))
+
+	var sb strings.Builder
+	if err := latex.Encode(&sb, node, nil); err != nil {
+		t.Fatal(err)
+	}
+	exp := "\\begin{enumerate}\n\\setcounter{enumi}{2}\n\\item \\begin{itemize}\n\\item \\begin{enumerate}\n\\setcounter{enumii}{4}" +
+		"\n\\item \\begin{enumerate}\n\\setcounter{enumiii}{6}\n\\item a\n\\end{enumerate}\n\\end{enumerate}\n\\end{itemize}\n\\end{enumerate}" +
+		"\n\n\\begin{enumerate}\n\\setcounter{enumi}{1}\n\\item b\n\\end{enumerate}"
+	if got := sb.String(); got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+}
+
+func TestEncodeBLOB(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeBlock(
+		zsx.MakeBLOB(nil, "png", []byte("abc"), sx.MakeList(zsx.MakeText("a"))),
+		zsx.MakePara(zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), sx.MakeList(zsx.MakeText("b")))),
+	)
+	var files []string
+	blobFunc := func(syntax string, data []byte) (string, error) {
+		name := "blob" + string(rune('0'+len(files))) + "." + syntax
+		files = append(files, name+":"+string(data))
+		return name, nil
+	}
+
+	var sb strings.Builder
+	if err := latex.Encode(&sb, node, blobFunc); err != nil {
+		t.Fatal(err)
+	}
+	exp := "\\begin{figure}\n\\centering\n\\includegraphics{blob0.png}\n\\caption{a}\n\\end{figure}\n\n\\includegraphics{blob1.svg}"
+	if got := sb.String(); got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+	if got, exp := strings.Join(files, " "), "blob0.png:abc blob1.svg:<svg/>"; got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+
+	sb.Reset()
+	if err := latex.Encode(&sb, node, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "a\n\nb"; got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+
+	errBLOB := errors.New("no space left")
+	err := latex.Encode(&sb, node, func(string, []byte) (string, error) { return "", errBLOB })
+	if !errors.Is(err, errBLOB) {
+		t.Errorf("expected error %v, but got %v", errBLOB, err)
+	}
+}
+
+func TestEncodeCustom(t *testing.T) {
+	t.Parallel()
+	layouttest.Register(t)
+	text := zsx.MakeText
+	node := zsx.MakeBlock(
+		layouttest.MakeAside(sx.MakeList(text("t")), zsx.MakePara(text("s")), zsx.MakePara(text("a")), zsx.MakePara(text("b"))),
+		zsx.MakePara(text("x "), layouttest.MakeBadge(text("i"), zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text("c"))))),
+		zsx.MakePara(text("y"), sx.MakeList(sx.MakeSymbol("UNKNOWN"), text("z"))),
+	)
+	var sb strings.Builder
+	if err := latex.Encode(&sb, node, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "t\n\ns\n\na\n\nb\n\nx i\\emph{c}\n\ny"; got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+}
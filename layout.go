@@ -0,0 +1,239 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+
+	"t73f.de/r/sx"
+)
+
+// Context specifies where a node may be placed.
+type Context uint8
+
+// Constants for Context.
+const (
+	ContextAny         Context = iota // Every node
+	ContextBlock                      // Block nodes
+	ContextInline                     // Inline nodes
+	ContextItem                       // ITEM nodes of a list
+	ContextDescription                // TERM and DETAIL nodes of a description list
+	ContextEntry                      // ENTRY nodes of a detail
+	ContextRow                        // ROW nodes of a table
+	ContextCell                       // CELL nodes of a row
+)
+
+var contextNames = [...]string{
+	ContextAny:         "any",
+	ContextBlock:       "block",
+	ContextInline:      "inline",
+	ContextItem:        "list",
+	ContextDescription: "description",
+	ContextEntry:       "detail",
+	ContextRow:         "table",
+	ContextCell:        "row",
+}
+
+// ElementKind specifies the kind of an element of a node.
+type ElementKind uint8
+
+// Constants for ElementKind.
+const (
+	ElementAttrs  ElementKind = iota // Element is an attribute list
+	ElementInt                       // Element is a positive integer number
+	ElementString                    // Element is a string
+	ElementRef                       // Element is a reference
+	ElementList                      // Element is a list of nodes
+	ElementNode                      // Element is a node, or nil
+	ElementRest                      // All remaining elements are nodes
+)
+
+// Element describes an element of a node.
+type Element struct {
+	Name    string // Used in validation messages, and as JSON member name
+	Kind    ElementKind
+	Context Context // Context of the nodes of ElementList, ElementNode, and ElementRest
+}
+
+// Layout describes the elements of a node, i.e. all list elements after its
+// symbol.
+type Layout struct {
+	Context  Context // Where the node may be placed
+	Elements []Element
+}
+
+var (
+	elemAttrs     = Element{"attrs", ElementAttrs, ContextAny}
+	elemBlocks    = Element{"blocks", ElementRest, ContextBlock}
+	elemContent   = Element{"content", ElementString, ContextAny}
+	elemData      = Element{"data", ElementString, ContextAny}
+	elemInlines   = Element{"inlines", ElementRest, ContextInline}
+	elemRef       = Element{"ref", ElementRef, ContextAny}
+	elemSyntax    = Element{"syntax", ElementString, ContextAny}
+	layoutFormat  = Layout{ContextInline, []Element{elemAttrs, elemInlines}}
+	layoutList    = Layout{ContextBlock, []Element{elemAttrs, {"items", ElementRest, ContextItem}}}
+	layoutLiteral = Layout{ContextInline, []Element{elemAttrs, elemContent}}
+	layoutRegion  = Layout{ContextBlock, []Element{elemAttrs, {"blocks", ElementList, ContextBlock}, elemInlines}}
+	layoutVerb    = Layout{ContextBlock, []Element{elemAttrs, elemContent}}
+)
+
+// layouts contains the layouts of all predefined nodes.
+var layouts = map[*sx.Symbol]Layout{
+	SymBlock:           {ContextBlock, []Element{elemBlocks}},
+	SymInline:          {ContextInline, []Element{elemInlines}},
+	SymPara:            {ContextBlock, []Element{elemInlines}},
+	SymHeading:         {ContextBlock, []Element{elemAttrs, {"level", ElementInt, ContextAny}, elemInlines}},
+	SymThematic:        {ContextBlock, []Element{elemAttrs}},
+	SymListOrdered:     layoutList,
+	SymListUnordered:   layoutList,
+	SymListQuote:       layoutList,
+	SymListItem:        {ContextItem, []Element{elemAttrs, elemBlocks}},
+	SymDescription:     {ContextBlock, []Element{elemAttrs, {"elements", ElementRest, ContextDescription}}},
+	SymTerm:            {ContextDescription, []Element{elemAttrs, elemInlines}},
+	SymDetail:          {ContextDescription, []Element{{"entries", ElementRest, ContextEntry}}},
+	SymEntry:           {ContextEntry, []Element{elemAttrs, elemBlocks}},
+	SymTable:           {ContextBlock, []Element{elemAttrs, {"header", ElementNode, ContextRow}, {"rows", ElementRest, ContextRow}}},
+	SymRow:             {ContextRow, []Element{elemAttrs, {"cells", ElementRest, ContextCell}}},
+	SymCell:            {ContextCell, []Element{elemAttrs, elemInlines}},
+	SymRegionBlock:     layoutRegion,
+	SymRegionQuote:     layoutRegion,
+	SymRegionVerse:     layoutRegion,
+	SymVerbatimCode:    layoutVerb,
+	SymVerbatimComment: layoutVerb,
+	SymVerbatimEval:    layoutVerb,
+	SymVerbatimHTML:    layoutVerb,
+	SymVerbatimMath:    layoutVerb,
+	SymVerbatimZettel:  layoutVerb,
+	SymTransclude:      {ContextBlock, []Element{elemAttrs, elemRef, elemInlines}},
+	SymBLOB:            {ContextBlock, []Element{elemAttrs, elemSyntax, elemData, elemInlines}},
+	SymText:            {ContextInline, []Element{{"text", ElementString, ContextAny}}},
+	SymSoft:            {ContextInline, nil},
+	SymHard:            {ContextInline, nil},
+	SymLink:            {ContextInline, []Element{elemAttrs, elemRef, elemInlines}},
+	SymEmbed:           {ContextInline, []Element{elemAttrs, elemRef, elemSyntax, elemInlines}},
+	SymEmbedBLOB:       {ContextInline, []Element{elemAttrs, elemSyntax, elemData, elemInlines}},
+	SymCite:            {ContextInline, []Element{elemAttrs, {"key", ElementString, ContextAny}, elemInlines}},
+	SymEndnote:         {ContextInline, []Element{elemAttrs, elemInlines}},
+	SymMark:            {ContextInline, []Element{elemAttrs, {"mark", ElementString, ContextAny}, elemInlines}},
+	SymFormatDelete:    layoutFormat,
+	SymFormatEmph:      layoutFormat,
+	SymFormatInsert:    layoutFormat,
+	SymFormatMark:      layoutFormat,
+	SymFormatQuote:     layoutFormat,
+	SymFormatSpan:      layoutFormat,
+	SymFormatStrong:    layoutFormat,
+	SymFormatSub:       layoutFormat,
+	SymFormatSuper:     layoutFormat,
+	SymLiteralCode:     layoutLiteral,
+	SymLiteralComment:  layoutLiteral,
+	SymLiteralInput:    layoutLiteral,
+	SymLiteralMath:     layoutLiteral,
+	SymLiteralOutput:   layoutLiteral,
+}
+
+// customLayouts contains the layouts of all registered custom nodes.
+var (
+	customMx      sync.RWMutex
+	customLayouts = map[*sx.Symbol]Layout{}
+)
+
+// RegisterLayout registers the layout of a custom node, e.g. an admonition
+// or a task item. Afterwards, [Walk] and [WalkIt] traverse its child nodes,
+// and [Validate] checks it like a predefined node.
+//
+// The layout of a predefined node cannot be changed, and a custom node can be
+// registered only once. Registration should be done before any tree with a
+// custom node is walked, typically in an init function.
+func RegisterLayout(sym *sx.Symbol, layout Layout) error {
+	if sym == nil || SymSpecialSplice.IsEqualSymbol(sym) {
+		return fmt.Errorf("invalid node symbol: %v", sym)
+	}
+	if _, found := layouts[sym]; found {
+		return fmt.Errorf("predefined node %v cannot be registered", sym)
+	}
+	if int(layout.Context) >= len(contextNames) {
+		return fmt.Errorf("%v: unknown context %d", sym, layout.Context)
+	}
+	for i, e := range layout.Elements {
+		if e.Kind > ElementRest {
+			return fmt.Errorf("%v: %s: unknown element kind %d", sym, e.Name, e.Kind)
+		}
+		if int(e.Context) >= len(contextNames) {
+			return fmt.Errorf("%v: %s: unknown context %d", sym, e.Name, e.Context)
+		}
+		if e.Kind == ElementRest && i < len(layout.Elements)-1 {
+			return fmt.Errorf("%v: %s: must be the last element", sym, e.Name)
+		}
+	}
+
+	customMx.Lock()
+	defer customMx.Unlock()
+	if _, found := customLayouts[sym]; found {
+		return fmt.Errorf("node %v already registered", sym)
+	}
+	customLayouts[sym] = Layout{Context: layout.Context, Elements: slices.Clone(layout.Elements)}
+	return nil
+}
+
+// GetLayout returns the layout of a predefined or of a registered custom
+// node.
+func GetLayout(sym *sx.Symbol) (Layout, bool) {
+	if layout, found := layouts[sym]; found {
+		return layout, true
+	}
+	return getCustomLayout(sym)
+}
+
+func getCustomLayout(sym *sx.Symbol) (Layout, bool) {
+	customMx.RLock()
+	layout, found := customLayouts[sym]
+	customMx.RUnlock()
+	return layout, found
+}
+
+// LayoutChildren returns an iterator over the child nodes of the given node,
+// as specified by its layout, in the same order as [WalkIt] visits them: the
+// list of an ElementList, the node of an ElementNode as a list with one
+// element, and all remaining elements for an ElementRest. Each non-empty list
+// is returned together with the context of its nodes. Encoders use it to
+// write at least the content of a custom node.
+func LayoutChildren(node *sx.Pair) iter.Seq2[Context, *sx.Pair] {
+	return func(yield func(Context, *sx.Pair) bool) {
+		layout, found := GetLayout(NodeSymbol(node))
+		if !found {
+			return
+		}
+		elems := node.Tail()
+		for _, e := range layout.Elements {
+			var lst *sx.Pair
+			switch e.Kind {
+			case ElementList:
+				lst, _ = sx.GetPair(elems.Car())
+			case ElementNode:
+				if child, isPair := sx.GetPair(elems.Car()); isPair && child != nil {
+					lst = sx.MakeList(child)
+				}
+			case ElementRest:
+				lst = elems
+			}
+			if lst != nil && !yield(e.Context, lst) {
+				return
+			}
+			elems = elems.Tail()
+		}
+	}
+}
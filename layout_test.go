@@ -0,0 +1,171 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/internal/layouttest"
+)
+
+var symAdmonition = sx.MakeSymbol("ADMONITION")
+
+func init() {
+	err := zsx.RegisterLayout(symAdmonition, zsx.Layout{
+		Context: zsx.ContextBlock,
+		Elements: []zsx.Element{
+			{Name: "attrs", Kind: zsx.ElementAttrs},
+			{Name: "kind", Kind: zsx.ElementString},
+			{Name: "title", Kind: zsx.ElementList, Context: zsx.ContextInline},
+			{Name: "blocks", Kind: zsx.ElementRest, Context: zsx.ContextBlock},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func makeAdmonition() *sx.Pair {
+	return sx.MakeList(symAdmonition, sx.Nil(), sx.MakeString("note"),
+		sx.MakeList(zsx.MakeText("a")),
+		zsx.MakePara(zsx.MakeText("b")),
+		zsx.MakePara(zsx.MakeText("c")),
+	)
+}
+
+func TestLayoutWalk(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeBlock(makeAdmonition())
+	obj := zsx.Walk(upperTextVisitor{}, node, nil)
+	exp := `(BLOCK (ADMONITION () "note" ((TEXT "A")) (PARA (TEXT "B")) (PARA (TEXT "C"))))`
+	if got := obj.String(); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+
+	var v textCollector
+	zsx.WalkIt(&v, node, nil)
+	if got := strings.Join(v.texts, ""); got != "abc" {
+		t.Errorf("WalkIt: exp=%q, got=%q", "abc", got)
+	}
+}
+
+type upperTextVisitor struct{}
+
+func (upperTextVisitor) VisitBefore(node *sx.Pair, _ *sx.Pair) (sx.Object, bool) {
+	if zsx.SymText.IsEqual(node.Car()) {
+		return zsx.MakeText(strings.ToUpper(zsx.GetText(node))), true
+	}
+	return sx.Nil(), false
+}
+func (upperTextVisitor) VisitAfter(node *sx.Pair, _ *sx.Pair) sx.Object { return node }
+
+type textCollector struct{ texts []string }
+
+func (v *textCollector) VisitItBefore(node *sx.Pair, _ *sx.Pair) bool {
+	if zsx.SymText.IsEqual(node.Car()) {
+		v.texts = append(v.texts, zsx.GetText(node))
+	}
+	return false
+}
+func (*textCollector) VisitItAfter(*sx.Pair, *sx.Pair) {}
+
+func TestLayoutChildren(t *testing.T) {
+	t.Parallel()
+	layouttest.Register(t)
+	text := zsx.MakeText
+	testcases := []struct {
+		name string
+		node *sx.Pair
+		exp  string
+	}{
+		{"list-rest", makeAdmonition(), `2 ((TEXT "a"))|1 ((PARA (TEXT "b")) (PARA (TEXT "c")))`},
+		{"node", layouttest.MakeAside(sx.MakeList(text("t")), zsx.MakePara(text("s")), zsx.MakePara(text("b"))),
+			`2 ((TEXT "t"))|1 ((PARA (TEXT "s")))|1 ((PARA (TEXT "b")))`},
+		{"empty", layouttest.MakeAside(nil, nil), ``},
+		{"inline-node", layouttest.MakeBadge(text("i"), text("b")), `2 ((TEXT "i"))|2 ((TEXT "b"))`},
+		{"unknown", sx.MakeList(sx.MakeSymbol("UNKNOWN"), text("a")), ``},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for ctx, lst := range zsx.LayoutChildren(tc.node) {
+				got = append(got, fmt.Sprintf("%d %v", ctx, lst))
+			}
+			if s := strings.Join(got, "|"); s != tc.exp {
+				t.Errorf("\nexp: %s\ngot: %s", tc.exp, s)
+			}
+		})
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	t.Parallel()
+	if got := zsx.Validate(zsx.MakeBlock(makeAdmonition())); got != nil {
+		t.Errorf("valid node expected, but got: %v", got)
+	}
+	node := zsx.MakePara(sx.MakeList(symAdmonition, sx.Nil(), sx.Int64(1), sx.MakeList(zsx.MakePara())))
+	var msgs []string
+	for _, v := range zsx.Validate(node) {
+		msgs = append(msgs, v.String())
+	}
+	exp := "[1]: ADMONITION not allowed in inline context\n" +
+		"[1 2]: ADMONITION: kind: not a string: 1\n" +
+		"[1 3 0]: PARA not allowed in inline context"
+	if got := strings.Join(msgs, "\n"); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+}
+
+func TestRegisterLayoutError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name   string
+		sym    *sx.Symbol
+		layout zsx.Layout
+	}{
+		{"nil", nil, zsx.Layout{}},
+		{"splice", zsx.SymSpecialSplice, zsx.Layout{}},
+		{"predefined", zsx.SymPara, zsx.Layout{}},
+		{"registered", symAdmonition, zsx.Layout{}},
+		{"context", sx.MakeSymbol("CUSTOM-CONTEXT"), zsx.Layout{Context: 99}},
+		{"kind", sx.MakeSymbol("CUSTOM-KIND"), zsx.Layout{Elements: []zsx.Element{{Name: "a", Kind: 99}}}},
+		{"rest", sx.MakeSymbol("CUSTOM-REST"), zsx.Layout{Elements: []zsx.Element{
+			{Name: "a", Kind: zsx.ElementRest}, {Name: "b", Kind: zsx.ElementString}}}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := zsx.RegisterLayout(tc.sym, tc.layout); err == nil {
+				t.Error("error expected")
+			}
+		})
+	}
+}
+
+func TestGetLayout(t *testing.T) {
+	t.Parallel()
+	if layout, found := zsx.GetLayout(zsx.SymHeading); !found || layout.Context != zsx.ContextBlock || len(layout.Elements) != 3 {
+		t.Errorf("wrong layout for HEADING: %v/%v", layout, found)
+	}
+	if layout, found := zsx.GetLayout(symAdmonition); !found || len(layout.Elements) != 4 {
+		t.Errorf("wrong layout for ADMONITION: %v/%v", layout, found)
+	}
+	if layout, found := zsx.GetLayout(sx.MakeSymbol("UNKNOWN")); found {
+		t.Errorf("no layout expected, but got %v", layout)
+	}
+}
@@ -0,0 +1,853 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// blockKind specifies the type of a block node.
+type blockKind uint8
+
+// Constants for blockKind.
+const (
+	kindDocument blockKind = iota
+	kindBlockQuote
+	kindList
+	kindItem
+	kindParagraph
+	kindHeading
+	kindThematic
+	kindCode
+	kindHTML
+	kindTable
+)
+
+// block is a node of the block structure of a CommonMark document.
+type block struct {
+	kind     blockKind
+	parent   *block
+	children []*block
+	open     bool
+
+	lines   []string  // Content of paragraphs, code, HTML blocks, and table rows
+	content string    // Inline content of headings
+	level   int       // Level of a heading
+	list    *listData // Data of lists and list items
+
+	isFenced    bool   // Code block is a fenced code block
+	fenceChar   byte   // Character of the fence
+	fenceLen    int    // Number of fence characters
+	fenceOffset int    // Indentation of the opening fence
+	info        string // Info string of a fenced code block
+	literal     string // Content of code blocks and HTML blocks
+
+	htmlType int // Type of an HTML block, 1..7
+
+	header []string    // Cells of the table header
+	aligns []sx.Object // Alignment of table columns
+}
+
+// listData stores the properties of a list and of a list item.
+type listData struct {
+	ordered      bool
+	bulletChar   byte
+	delimiter    byte
+	start        int
+	markerOffset int
+	padding      int
+}
+
+func (b *block) lastChild() *block {
+	if l := len(b.children); l > 0 {
+		return b.children[l-1]
+	}
+	return nil
+}
+
+func (b *block) canContain(kind blockKind) bool {
+	switch b.kind {
+	case kindDocument, kindBlockQuote, kindItem:
+		return kind != kindItem
+	case kindList:
+		return kind == kindItem
+	}
+	return false
+}
+
+func (b *block) acceptsLines() bool {
+	switch b.kind {
+	case kindParagraph, kindCode, kindHTML:
+		return true
+	}
+	return false
+}
+
+// isParaLike returns true for paragraphs and tables, which both may be
+// interrupted by other blocks.
+func (b *block) isParaLike() bool { return b.kind == kindParagraph || b.kind == kindTable }
+
+// unlink removes the block from its parent.
+func (b *block) unlink() {
+	if parent := b.parent; parent != nil {
+		for i, child := range parent.children {
+			if child == b {
+				parent.children = append(parent.children[:i], parent.children[i+1:]...)
+				break
+			}
+		}
+		b.parent = nil
+	}
+}
+
+// codeIndent is the number of columns an indented code block needs.
+const codeIndent = 4
+
+// Results of continuing a block.
+const (
+	continueMatched = iota
+	continueFailed
+	continueDone
+)
+
+// Results of starting a new block.
+const (
+	startNone = iota
+	startContainer
+	startLeaf
+)
+
+// blockParser builds the block structure of a CommonMark document, line by line.
+type blockParser struct {
+	doc                  *block
+	tip                  *block
+	oldtip               *block
+	lastMatchedContainer *block
+	allClosed            bool
+	refmap               map[string]linkRef
+
+	line                 string
+	offset               int
+	column               int
+	nextNonspace         int
+	nextNonspaceColumn   int
+	indent               int
+	indented             bool
+	blank                bool
+	partiallyConsumedTab bool
+}
+
+// linkRef is the destination and the title of a link reference definition.
+type linkRef struct {
+	dest  string
+	title string
+}
+
+var reLineEnding = regexp.MustCompile(`\r\n|\n|\r`)
+
+// parseDocument parses the source into a block structure.
+func parseDocument(src string) (*block, map[string]linkRef) {
+	doc := &block{kind: kindDocument, open: true}
+	p := blockParser{
+		doc:                  doc,
+		tip:                  doc,
+		lastMatchedContainer: doc,
+		refmap:               map[string]linkRef{},
+	}
+	lines := reLineEnding.Split(src, -1)
+	if l := len(lines); l > 0 && lines[l-1] == "" {
+		lines = lines[:l-1]
+	}
+	for _, line := range lines {
+		p.incorporateLine(line)
+	}
+	for p.tip != nil {
+		p.finalize(p.tip)
+	}
+	return doc, p.refmap
+}
+
+func peek(s string, pos int) int {
+	if pos < len(s) {
+		return int(s[pos])
+	}
+	return -1
+}
+
+func isSpaceOrTab(c int) bool { return c == ' ' || c == '\t' }
+
+func (p *blockParser) findNextNonspace() {
+	line := p.line
+	i, cols := p.offset, p.column
+	for i < len(line) {
+		if c := line[i]; c == ' ' {
+			i++
+			cols++
+		} else if c == '\t' {
+			i++
+			cols += 4 - (cols % 4)
+		} else {
+			break
+		}
+	}
+	p.blank = i >= len(line)
+	p.nextNonspace = i
+	p.nextNonspaceColumn = cols
+	p.indent = cols - p.column
+	p.indented = p.indent >= codeIndent
+}
+
+func (p *blockParser) advanceNextNonspace() {
+	p.offset = p.nextNonspace
+	p.column = p.nextNonspaceColumn
+	p.partiallyConsumedTab = false
+}
+
+func (p *blockParser) advanceOffset(count int, columns bool) {
+	line := p.line
+	for count > 0 && p.offset < len(line) {
+		if line[p.offset] == '\t' {
+			charsToTab := 4 - (p.column % 4)
+			if columns {
+				p.partiallyConsumedTab = charsToTab > count
+				charsToAdvance := min(charsToTab, count)
+				p.column += charsToAdvance
+				if !p.partiallyConsumedTab {
+					p.offset++
+				}
+				count -= charsToAdvance
+			} else {
+				p.partiallyConsumedTab = false
+				p.column += charsToTab
+				p.offset++
+				count--
+			}
+		} else {
+			p.partiallyConsumedTab = false
+			p.offset++
+			p.column++
+			count--
+		}
+	}
+}
+
+func (p *blockParser) addLine() {
+	prefix := ""
+	if p.partiallyConsumedTab {
+		p.offset++
+		prefix = strings.Repeat(" ", 4-(p.column%4))
+	}
+	p.tip.lines = append(p.tip.lines, prefix+p.line[p.offset:])
+}
+
+func (p *blockParser) addChild(kind blockKind) *block {
+	for !p.tip.canContain(kind) {
+		p.finalize(p.tip)
+	}
+	b := &block{kind: kind, parent: p.tip, open: true}
+	p.tip.children = append(p.tip.children, b)
+	p.tip = b
+	return b
+}
+
+func (p *blockParser) closeUnmatchedBlocks() {
+	if !p.allClosed {
+		for p.oldtip != p.lastMatchedContainer {
+			parent := p.oldtip.parent
+			p.finalize(p.oldtip)
+			p.oldtip = parent
+		}
+		p.allClosed = true
+	}
+}
+
+// incorporateLine analyzes a line of the source and updates the document.
+func (p *blockParser) incorporateLine(line string) {
+	container := p.doc
+	p.oldtip = p.tip
+	p.offset, p.column = 0, 0
+	p.blank, p.partiallyConsumedTab = false, false
+	p.line = strings.ReplaceAll(line, "\x00", "�")
+
+	// For each containing block, try to parse the associated line start.
+	for {
+		last := container.lastChild()
+		if last == nil || !last.open {
+			break
+		}
+		container = last
+		p.findNextNonspace()
+		res := p.continueBlock(container)
+		if res == continueDone {
+			return
+		}
+		if res == continueFailed {
+			container = container.parent
+			break
+		}
+	}
+	p.allClosed = container == p.oldtip
+	p.lastMatchedContainer = container
+
+	// Unless last matched container is a code block, try new container starts.
+	matchedLeaf := !container.isParaLike() && container.acceptsLines()
+	for !matchedLeaf {
+		p.findNextNonspace()
+		res := p.tryBlockStarts(container)
+		if res == startNone {
+			p.advanceNextNonspace()
+			break
+		}
+		container = p.tip
+		matchedLeaf = res == startLeaf
+	}
+
+	// What remains at the offset is a text line. First check for a lazy
+	// paragraph continuation.
+	if !p.allClosed && !p.blank && p.tip.kind == kindParagraph {
+		p.addLine()
+		return
+	}
+
+	p.closeUnmatchedBlocks()
+	switch {
+	case container.acceptsLines():
+		p.addLine()
+		if container.kind == kindHTML && container.htmlType >= 1 && container.htmlType <= 5 &&
+			reHTMLBlockClose[container.htmlType].MatchString(p.line[p.offset:]) {
+			p.finalize(container)
+		}
+	case p.offset < len(p.line) && !p.blank:
+		if container.kind != kindTable {
+			container = p.addChild(kindParagraph)
+			p.advanceNextNonspace()
+		}
+		p.addLine()
+	}
+}
+
+// continueBlock checks whether the given open block continues on the current line.
+func (p *blockParser) continueBlock(b *block) int {
+	switch b.kind {
+	case kindDocument, kindList:
+		return continueMatched
+	case kindBlockQuote:
+		if !p.indented && peek(p.line, p.nextNonspace) == '>' {
+			p.advanceNextNonspace()
+			p.advanceOffset(1, false)
+			if isSpaceOrTab(peek(p.line, p.offset)) {
+				p.advanceOffset(1, true)
+			}
+			return continueMatched
+		}
+	case kindItem:
+		if p.blank {
+			if len(b.children) == 0 {
+				// Blank line after empty list item
+				return continueFailed
+			}
+			p.advanceNextNonspace()
+			return continueMatched
+		}
+		if indent := b.list.markerOffset + b.list.padding; p.indent >= indent {
+			p.advanceOffset(indent, true)
+			return continueMatched
+		}
+	case kindCode:
+		if b.isFenced {
+			if p.indent <= 3 && peek(p.line, p.nextNonspace) == int(b.fenceChar) {
+				if n := closingFenceLength(p.line[p.nextNonspace:], b.fenceChar); n >= b.fenceLen {
+					p.finalize(b)
+					return continueDone
+				}
+			}
+			for i := b.fenceOffset; i > 0 && isSpaceOrTab(peek(p.line, p.offset)); i-- {
+				p.advanceOffset(1, true)
+			}
+			return continueMatched
+		}
+		if p.indent >= codeIndent {
+			p.advanceOffset(codeIndent, true)
+			return continueMatched
+		}
+		if p.blank {
+			p.advanceNextNonspace()
+			return continueMatched
+		}
+	case kindHTML:
+		if p.blank && (b.htmlType == 6 || b.htmlType == 7) {
+			return continueFailed
+		}
+		return continueMatched
+	case kindParagraph, kindTable:
+		if !p.blank {
+			return continueMatched
+		}
+	}
+	return continueFailed
+}
+
+// closingFenceLength returns the length of a closing code fence, or 0.
+func closingFenceLength(s string, fenceChar byte) int {
+	n := 0
+	for n < len(s) && s[n] == fenceChar {
+		n++
+	}
+	if n < 3 || strings.TrimLeft(s[n:], " \t") != "" {
+		return 0
+	}
+	return n
+}
+
+// finalize closes the given block.
+func (p *blockParser) finalize(b *block) {
+	parent := b.parent
+	b.open = false
+	switch b.kind {
+	case kindParagraph:
+		if p.extractRefDefs(b) && len(b.lines) == 0 {
+			b.unlink()
+		}
+	case kindCode:
+		if b.isFenced {
+			if len(b.lines) > 0 {
+				b.info = unescapeString(strings.TrimSpace(b.lines[0]))
+				b.literal = strings.Join(b.lines[1:], "\n")
+			}
+		} else {
+			lines := b.lines
+			for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+				lines = lines[:len(lines)-1]
+			}
+			b.literal = strings.Join(lines, "\n")
+		}
+		b.lines = nil
+	case kindHTML:
+		b.literal = strings.Join(b.lines, "\n")
+		b.lines = nil
+	}
+	p.tip = parent
+}
+
+// extractRefDefs parses link reference definitions at the beginning of a
+// paragraph and removes them from the paragraph. It returns true, if at least
+// one definition was found.
+func (p *blockParser) extractRefDefs(b *block) bool {
+	content := strings.Join(b.lines, "\n")
+	found := false
+	for strings.HasPrefix(content, "[") {
+		n := parseReference(content, p.refmap)
+		if n == 0 {
+			break
+		}
+		content = content[n:]
+		found = true
+	}
+	if found {
+		if strings.TrimSpace(content) == "" {
+			b.lines = nil
+		} else {
+			b.lines = strings.Split(content, "\n")
+		}
+	}
+	return found
+}
+
+// tryBlockStarts tries to start a new block.
+func (p *blockParser) tryBlockStarts(container *block) int {
+	if res := p.startBlockQuote(); res != startNone {
+		return res
+	}
+	if res := p.startATXHeading(); res != startNone {
+		return res
+	}
+	if res := p.startFencedCode(); res != startNone {
+		return res
+	}
+	if res := p.startHTMLBlock(container); res != startNone {
+		return res
+	}
+	if res := p.startSetextHeading(container); res != startNone {
+		return res
+	}
+	if res := p.startThematicBreak(); res != startNone {
+		return res
+	}
+	if res := p.startListItem(container); res != startNone {
+		return res
+	}
+	if res := p.startTable(container); res != startNone {
+		return res
+	}
+	return p.startIndentedCode()
+}
+
+func (p *blockParser) startBlockQuote() int {
+	if p.indented || peek(p.line, p.nextNonspace) != '>' {
+		return startNone
+	}
+	p.advanceNextNonspace()
+	p.advanceOffset(1, false)
+	if isSpaceOrTab(peek(p.line, p.offset)) {
+		p.advanceOffset(1, true)
+	}
+	p.closeUnmatchedBlocks()
+	p.addChild(kindBlockQuote)
+	return startContainer
+}
+
+var (
+	reATXHeadingMarker = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	reATXClosingOnly   = regexp.MustCompile(`^[ \t]*#+[ \t]*$`)
+	reATXClosing       = regexp.MustCompile(`[ \t]+#+[ \t]*$`)
+)
+
+func (p *blockParser) startATXHeading() int {
+	if p.indented {
+		return startNone
+	}
+	match := reATXHeadingMarker.FindString(p.line[p.nextNonspace:])
+	if match == "" {
+		return startNone
+	}
+	p.advanceNextNonspace()
+	p.advanceOffset(len(match), false)
+	p.closeUnmatchedBlocks()
+	b := p.addChild(kindHeading)
+	b.level = len(strings.TrimRight(match, " \t"))
+	content := reATXClosingOnly.ReplaceAllString(p.line[p.offset:], "")
+	b.content = reATXClosing.ReplaceAllString(content, "")
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return startLeaf
+}
+
+func (p *blockParser) startFencedCode() int {
+	if p.indented {
+		return startNone
+	}
+	s := p.line[p.nextNonspace:]
+	if len(s) < 3 || (s[0] != '`' && s[0] != '~') {
+		return startNone
+	}
+	fenceChar := s[0]
+	n := 0
+	for n < len(s) && s[n] == fenceChar {
+		n++
+	}
+	if n < 3 || (fenceChar == '`' && strings.IndexByte(s[n:], '`') >= 0) {
+		return startNone
+	}
+	p.closeUnmatchedBlocks()
+	b := p.addChild(kindCode)
+	b.isFenced = true
+	b.fenceLen = n
+	b.fenceChar = fenceChar
+	b.fenceOffset = p.indent
+	p.advanceNextNonspace()
+	p.advanceOffset(n, false)
+	return startLeaf
+}
+
+const (
+	htmlTagName           = `[A-Za-z][A-Za-z0-9-]*`
+	htmlAttributeName     = `[a-zA-Z_:][a-zA-Z0-9:._-]*`
+	htmlUnquotedValue     = "[^\"'=<>`\\x00-\\x20]+"
+	htmlSingleQuotedValue = `'[^']*'`
+	htmlDoubleQuotedValue = `"[^"]*"`
+	htmlAttributeValue    = `(?:` + htmlUnquotedValue + `|` + htmlSingleQuotedValue + `|` + htmlDoubleQuotedValue + `)`
+	htmlAttributeValSpec  = `(?:\s*=\s*` + htmlAttributeValue + `)`
+	htmlAttribute         = `(?:\s+` + htmlAttributeName + htmlAttributeValSpec + `?)`
+	htmlOpenTag           = `<` + htmlTagName + htmlAttribute + `*\s*/?>`
+	htmlCloseTag          = `</` + htmlTagName + `\s*[>]`
+	htmlComment           = `<!-->|<!--->|<!--[\s\S]*?-->`
+	htmlProcessing        = `[<][?][\s\S]*?[?][>]`
+	htmlDeclaration       = `<![A-Za-z]+[^>]*>`
+	htmlCDATA             = `<!\[CDATA\[[\s\S]*?\]\]>`
+	htmlTag               = `(?:` + htmlOpenTag + `|` + htmlCloseTag + `|` + htmlComment + `|` +
+		htmlProcessing + `|` + htmlDeclaration + `|` + htmlCDATA + `)`
+)
+
+var reHTMLBlockOpen = []*regexp.Regexp{
+	nil,
+	regexp.MustCompile(`(?i)^<(?:script|pre|textarea|style)(?:\s|>|$)`),
+	regexp.MustCompile(`^<!--`),
+	regexp.MustCompile(`^<[?]`),
+	regexp.MustCompile(`^<![A-Za-z]`),
+	regexp.MustCompile(`^<!\[CDATA\[`),
+	regexp.MustCompile(`(?i)^</?(?:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[123456]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:\s|/?>|$)`),
+	regexp.MustCompile(`(?i)^(?:` + htmlOpenTag + `|` + htmlCloseTag + `)\s*$`),
+}
+
+var reHTMLBlockClose = []*regexp.Regexp{
+	nil,
+	regexp.MustCompile(`(?i)</(?:script|pre|textarea|style)>`),
+	regexp.MustCompile(`-->`),
+	regexp.MustCompile(`\?>`),
+	regexp.MustCompile(`>`),
+	regexp.MustCompile(`\]\]>`),
+}
+
+func (p *blockParser) startHTMLBlock(container *block) int {
+	if p.indented || peek(p.line, p.nextNonspace) != '<' {
+		return startNone
+	}
+	s := p.line[p.nextNonspace:]
+	for blockType := 1; blockType <= 7; blockType++ {
+		if reHTMLBlockOpen[blockType].MatchString(s) &&
+			(blockType < 7 || (!container.isParaLike() && !(!p.allClosed && !p.blank && p.tip.kind == kindParagraph))) {
+			p.closeUnmatchedBlocks()
+			// Offset is not adjusted, spaces are part of the HTML block.
+			b := p.addChild(kindHTML)
+			b.htmlType = blockType
+			return startLeaf
+		}
+	}
+	return startNone
+}
+
+var reSetextHeadingLine = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+
+func (p *blockParser) startSetextHeading(container *block) int {
+	if p.indented || container.kind != kindParagraph {
+		return startNone
+	}
+	match := reSetextHeadingLine.FindString(p.line[p.nextNonspace:])
+	if match == "" {
+		return startNone
+	}
+	p.closeUnmatchedBlocks()
+	p.extractRefDefs(container)
+	if len(container.lines) == 0 {
+		return startNone
+	}
+	container.kind = kindHeading
+	container.content = strings.Join(container.lines, "\n")
+	container.lines = nil
+	container.level = 2
+	if match[0] == '=' {
+		container.level = 1
+	}
+	p.tip = container
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return startLeaf
+}
+
+var reThematicBreak = regexp.MustCompile(`^(?:\*[ \t]*){3,}$|^(?:_[ \t]*){3,}$|^(?:-[ \t]*){3,}$`)
+
+func (p *blockParser) startThematicBreak() int {
+	if p.indented || !reThematicBreak.MatchString(p.line[p.nextNonspace:]) {
+		return startNone
+	}
+	p.closeUnmatchedBlocks()
+	p.addChild(kindThematic)
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return startLeaf
+}
+
+func (p *blockParser) startListItem(container *block) int {
+	if p.indented && container.kind != kindList {
+		return startNone
+	}
+	data := p.parseListMarker(container)
+	if data == nil {
+		return startNone
+	}
+	p.closeUnmatchedBlocks()
+	if p.tip.kind != kindList || !listsMatch(p.tip.list, data) {
+		lst := p.addChild(kindList)
+		lst.list = data
+	}
+	item := p.addChild(kindItem)
+	item.list = data
+	return startContainer
+}
+
+func (p *blockParser) parseListMarker(container *block) *listData {
+	if p.indent >= codeIndent {
+		return nil
+	}
+	rest := p.line[p.nextNonspace:]
+	data := listData{markerOffset: p.indent}
+	markerLen := 0
+	if len(rest) > 0 && (rest[0] == '*' || rest[0] == '+' || rest[0] == '-') {
+		data.bulletChar = rest[0]
+		markerLen = 1
+	} else {
+		n := 0
+		for n < len(rest) && n < 10 && '0' <= rest[n] && rest[n] <= '9' {
+			n++
+		}
+		if n == 0 || n > 9 || n >= len(rest) || (rest[n] != '.' && rest[n] != ')') {
+			return nil
+		}
+		start, _ := strconv.Atoi(rest[:n])
+		if container.isParaLike() && start != 1 {
+			return nil
+		}
+		data.ordered = true
+		data.start = start
+		data.delimiter = rest[n]
+		markerLen = n + 1
+	}
+
+	// make sure we have spaces after
+	if nextc := peek(p.line, p.nextNonspace+markerLen); nextc != -1 && !isSpaceOrTab(nextc) {
+		return nil
+	}
+
+	// if it interrupts paragraph, make sure first line isn't blank
+	if container.isParaLike() && strings.TrimLeft(p.line[p.nextNonspace+markerLen:], " \t") == "" {
+		return nil
+	}
+
+	p.advanceNextNonspace()
+	p.advanceOffset(markerLen, true)
+	spacesStartCol, spacesStartOffset := p.column, p.offset
+	for {
+		p.advanceOffset(1, true)
+		if p.column-spacesStartCol >= 5 || !isSpaceOrTab(peek(p.line, p.offset)) {
+			break
+		}
+	}
+	blankItem := p.offset >= len(p.line)
+	spacesAfterMarker := p.column - spacesStartCol
+	if spacesAfterMarker >= 5 || spacesAfterMarker < 1 || blankItem {
+		data.padding = markerLen + 1
+		p.column, p.offset = spacesStartCol, spacesStartOffset
+		if isSpaceOrTab(peek(p.line, p.offset)) {
+			p.advanceOffset(1, true)
+		}
+	} else {
+		data.padding = markerLen + spacesAfterMarker
+	}
+	return &data
+}
+
+func listsMatch(listData, itemData *listData) bool {
+	return listData.ordered == itemData.ordered &&
+		listData.delimiter == itemData.delimiter &&
+		listData.bulletChar == itemData.bulletChar
+}
+
+// startTable starts a table, if the current line is a delimiter row and the
+// last line of the paragraph is a header row with the same number of cells.
+func (p *blockParser) startTable(container *block) int {
+	if p.indented || container.kind != kindParagraph || len(container.lines) == 0 {
+		return startNone
+	}
+	aligns := parseDelimiterRow(p.line[p.nextNonspace:])
+	if aligns == nil {
+		return startNone
+	}
+	last := len(container.lines) - 1
+	header := splitTableRow(container.lines[last])
+	if len(header) != len(aligns) {
+		return startNone
+	}
+	p.closeUnmatchedBlocks()
+	container.lines = container.lines[:last]
+	if last == 0 {
+		parent := container.parent
+		container.unlink()
+		p.tip = parent
+	}
+	b := p.addChild(kindTable)
+	b.header = header
+	b.aligns = aligns
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return startLeaf
+}
+
+// parseDelimiterRow parses the delimiter row of a table and returns the
+// alignments of all columns. If the line is not a delimiter row, nil is
+// returned.
+func parseDelimiterRow(line string) []sx.Object {
+	line = strings.TrimSpace(line)
+	hasPipe := strings.HasPrefix(line, "|") || strings.HasSuffix(line, "|")
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	hasPipe = hasPipe || len(cells) > 1
+	if !hasPipe {
+		return nil
+	}
+	aligns := make([]sx.Object, 0, len(cells))
+	for _, cell := range cells {
+		cell = strings.TrimSpace(cell)
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		dashes := strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return nil
+		}
+		switch {
+		case left && right:
+			aligns = append(aligns, zsx.AttrAlignCenter)
+		case left:
+			aligns = append(aligns, zsx.AttrAlignLeft)
+		case right:
+			aligns = append(aligns, zsx.AttrAlignRight)
+		default:
+			aligns = append(aligns, nil)
+		}
+	}
+	return aligns
+}
+
+// splitTableRow splits a table row into its cells. An escaped pipe
+// character is part of the cell content.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "|") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '\\':
+			if i+1 < len(line) {
+				i++
+				if line[i] != '|' {
+					sb.WriteByte('\\')
+				}
+				sb.WriteByte(line[i])
+			} else {
+				sb.WriteByte(c)
+			}
+		case '|':
+			cells = append(cells, strings.TrimSpace(sb.String()))
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(sb.String()))
+}
+
+func (p *blockParser) startIndentedCode() int {
+	if !p.indented || p.tip.isParaLike() || p.blank {
+		return startNone
+	}
+	p.advanceOffset(codeIndent, true)
+	p.closeUnmatchedBlocks()
+	p.addChild(kindCode)
+	return startLeaf
+}
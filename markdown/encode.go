@@ -0,0 +1,1008 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package markdown
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/html"
+)
+
+// Options control the encoding of zsx nodes as Markdown.
+type Options struct {
+	// GFM enables the extensions of GitHub Flavored Markdown: tables,
+	// strikethrough, and footnotes.
+	GFM bool
+
+	// Fallback specifies how nodes are written that have no Markdown
+	// equivalent.
+	Fallback Fallback
+}
+
+// Fallback specifies how nodes are written that cannot be represented in
+// Markdown.
+type Fallback uint8
+
+// Constants for Fallback.
+const (
+	// FallbackHTML writes such nodes as inline HTML or as HTML blocks.
+	FallbackHTML Fallback = iota
+
+	// FallbackText writes only the textual content of such nodes.
+	FallbackText
+)
+
+// Encode writes the given node as CommonMark to the writer. Typically, the
+// node is a BLOCK or an INLINE node.
+//
+// All nodes that could not be represented losslessly are returned, in the
+// order they were encountered. This includes nodes that are written
+// according to the fallback, and nodes where some information, like
+// attributes, is dropped. If a node is the result of parsing Markdown, it is
+// never reported.
+func Encode(w io.Writer, node *sx.Pair, opts Options) ([]*sx.Pair, error) {
+	enc := encoder{w: w, opts: opts, lineStart: true}
+	zsx.WalkIt(&enc, node, nil)
+	enc.writeEndnotes()
+	return enc.lossy, enc.err
+}
+
+type encoder struct {
+	w         io.Writer
+	err       error
+	opts      Options
+	prefix    string     // Written at the start of each line
+	pending   bool       // Prefix must be written before the next content
+	lineStart bool       // Next text is written at the start of a line
+	inHeading bool       // Line breaks are not allowed, '#' must be escaped
+	inCell    bool       // Line breaks are not allowed, '|' must be escaped
+	inVerse   bool       // Soft line breaks are written as hard breaks
+	inFormat  int        // Nesting level of emphasis
+	altMarker bool       // Use the alternative list marker
+	endnotes  []*sx.Pair // Collected endnotes
+	lossy     []*sx.Pair // Nodes that are not represented losslessly
+}
+
+var encodeFuncs map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair)
+
+func init() {
+	encodeFuncs = map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair){
+		zsx.SymBlock:           (*encoder).encodeBlock,
+		zsx.SymPara:            (*encoder).encodePara,
+		zsx.SymHeading:         (*encoder).encodeHeading,
+		zsx.SymThematic:        (*encoder).encodeThematic,
+		zsx.SymListOrdered:     (*encoder).encodeList,
+		zsx.SymListUnordered:   (*encoder).encodeList,
+		zsx.SymListQuote:       (*encoder).encodeQuotation,
+		zsx.SymDescription:     (*encoder).encodeDescription,
+		zsx.SymTable:           (*encoder).encodeTable,
+		zsx.SymRegionBlock:     (*encoder).encodeRegion,
+		zsx.SymRegionQuote:     (*encoder).encodeRegion,
+		zsx.SymRegionVerse:     (*encoder).encodeRegion,
+		zsx.SymVerbatimCode:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimComment: (*encoder).encodeVerbatimComment,
+		zsx.SymVerbatimEval:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimHTML:    (*encoder).encodeVerbatimHTML,
+		zsx.SymVerbatimMath:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimZettel:  (*encoder).encodeVerbatim,
+		zsx.SymTransclude:      (*encoder).encodeTransclusion,
+		zsx.SymBLOB:            (*encoder).encodeBLOB,
+
+		zsx.SymInline:         (*encoder).encodeInline,
+		zsx.SymText:           (*encoder).encodeText,
+		zsx.SymSoft:           (*encoder).encodeSoft,
+		zsx.SymHard:           (*encoder).encodeHard,
+		zsx.SymLink:           (*encoder).encodeLink,
+		zsx.SymEmbed:          (*encoder).encodeEmbed,
+		zsx.SymEmbedBLOB:      (*encoder).encodeEmbedBLOB,
+		zsx.SymCite:           (*encoder).encodeCite,
+		zsx.SymEndnote:        (*encoder).encodeEndnote,
+		zsx.SymMark:           (*encoder).encodeMark,
+		zsx.SymFormatDelete:   (*encoder).encodeDelete,
+		zsx.SymFormatEmph:     (*encoder).encodeEmphasis,
+		zsx.SymFormatInsert:   (*encoder).encodeFormat,
+		zsx.SymFormatMark:     (*encoder).encodeFormat,
+		zsx.SymFormatQuote:    (*encoder).encodeFormat,
+		zsx.SymFormatSpan:     (*encoder).encodeFormat,
+		zsx.SymFormatStrong:   (*encoder).encodeEmphasis,
+		zsx.SymFormatSub:      (*encoder).encodeFormat,
+		zsx.SymFormatSuper:    (*encoder).encodeFormat,
+		zsx.SymLiteralCode:    (*encoder).encodeLiteral,
+		zsx.SymLiteralComment: (*encoder).encodeLiteralComment,
+		zsx.SymLiteralInput:   (*encoder).encodeLiteral,
+		zsx.SymLiteralMath:    (*encoder).encodeLiteral,
+		zsx.SymLiteralOutput:  (*encoder).encodeLiteral,
+		zsx.SymSpecialSplice:  (*encoder).encodeInline,
+	}
+}
+
+func (enc *encoder) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	sym := zsx.NodeSymbol(node)
+	if sym != zsx.SymText {
+		enc.lineStart = false
+	}
+	if fn, found := encodeFuncs[sym]; found {
+		fn(enc, node, alst)
+	} else {
+		enc.encodeOther(node, alst)
+	}
+	return true
+}
+
+func (*encoder) VisitItAfter(*sx.Pair, *sx.Pair) {}
+
+// write writes the string, which must not contain a line ending. If it is
+// the first content of a line, the line prefix is written before.
+func (enc *encoder) write(s string) {
+	if enc.err != nil {
+		return
+	}
+	if enc.pending {
+		enc.pending = false
+		if _, enc.err = io.WriteString(enc.w, enc.prefix); enc.err != nil {
+			return
+		}
+	}
+	_, enc.err = io.WriteString(enc.w, s)
+}
+
+// newline ends the current line. The prefix of an empty line is written
+// without trailing spaces.
+func (enc *encoder) newline() {
+	if enc.pending {
+		enc.pending = false
+		enc.write(strings.TrimRight(enc.prefix, " "))
+	}
+	enc.write("\n")
+	enc.pending, enc.lineStart = true, true
+}
+
+// writeLines writes a string that may contain line endings.
+func (enc *encoder) writeLines(s string) {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			enc.newline()
+		}
+		if line != "" {
+			enc.write(line)
+		}
+	}
+}
+
+// addLossy records a node that is not represented losslessly.
+func (enc *encoder) addLossy(node *sx.Pair) { enc.lossy = append(enc.lossy, node) }
+
+// checkAttributes records the node, if its attributes contain other keys
+// than the given ones.
+func (enc *encoder) checkAttributes(node, attrs *sx.Pair, keys ...string) {
+	if zsx.GetAttributes(attrs).HasOtherKeys(keys...) {
+		enc.addLossy(node)
+	}
+}
+
+// writeBlocks writes the block nodes, separated by an empty line.
+func (enc *encoder) writeBlocks(blocks *sx.Pair, alst *sx.Pair) {
+	var prevSym *sx.Symbol
+	altMarker := false
+	for bn := range blocks.Pairs() {
+		node, isPair := sx.GetPair(bn.Car())
+		if !isPair {
+			continue
+		}
+		sym := zsx.NodeSymbol(node)
+		if prevSym != nil {
+			enc.newline()
+			enc.newline()
+		}
+
+		// Adjacent lists of the same kind would be merged into one list, if
+		// they use the same list marker.
+		altMarker = sym == prevSym && !altMarker
+		enc.altMarker = altMarker
+		enc.lineStart = true
+		zsx.WalkIt(enc, node, alst)
+		prevSym = sym
+	}
+}
+
+// writeInlines writes the inline nodes, which start at the beginning of a
+// line.
+func (enc *encoder) writeInlines(ins *sx.Pair, alst *sx.Pair) {
+	enc.lineStart = true
+	zsx.WalkItList(enc, ins, 0, alst)
+	enc.lineStart = false
+}
+
+// writeFallbackHTML writes the block node as a HTML block.
+func (enc *encoder) writeFallbackHTML(node *sx.Pair) {
+	var sb strings.Builder
+	if err := html.Encode(&sb, node); err != nil {
+		enc.err = err
+		return
+	}
+	enc.writeLines(sb.String())
+}
+
+// encodeOther writes an unknown node, or a custom node, according to the
+// fallback. With FallbackText, only the child nodes of a custom node are
+// written.
+func (enc *encoder) encodeOther(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	if enc.opts.Fallback == FallbackHTML {
+		enc.writeFallbackHTML(node)
+		return
+	}
+	layout, _ := zsx.GetLayout(zsx.NodeSymbol(node))
+	first := true
+	for ctx, lst := range zsx.LayoutChildren(node) {
+		if layout.Context != zsx.ContextInline && !first {
+			enc.newline()
+			enc.newline()
+		}
+		switch {
+		case ctx == zsx.ContextBlock:
+			enc.writeBlocks(lst, alst)
+		case layout.Context == zsx.ContextInline:
+			zsx.WalkItList(enc, lst, 0, alst)
+		default:
+			enc.writeInlines(lst, alst)
+		}
+		first = false
+	}
+}
+
+func (enc *encoder) encodeBlock(node *sx.Pair, alst *sx.Pair) {
+	enc.writeBlocks(zsx.GetBlock(node), alst)
+}
+
+func (enc *encoder) encodePara(node *sx.Pair, alst *sx.Pair) {
+	enc.writeInlines(zsx.GetPara(node), alst)
+}
+
+// maxHeadingLevel is the highest heading level of Markdown.
+const maxHeadingLevel = 6
+
+func (enc *encoder) encodeHeading(node *sx.Pair, alst *sx.Pair) {
+	attrs, level, ins := zsx.GetHeading(node)
+	enc.checkAttributes(node, attrs)
+	if level < 1 || level > maxHeadingLevel {
+		enc.addLossy(node)
+	}
+	enc.write(strings.Repeat("#", min(max(level, 1), maxHeadingLevel)))
+	if ins != nil {
+		enc.write(" ")
+		enc.inHeading = true
+		enc.writeInlines(ins, alst)
+		enc.inHeading = false
+	}
+}
+
+func (enc *encoder) encodeThematic(node *sx.Pair, _ *sx.Pair) {
+	enc.checkAttributes(node, zsx.GetThematic(node))
+	enc.write("***")
+}
+
+func (enc *encoder) encodeList(node *sx.Pair, alst *sx.Pair) {
+	sym, attrs, items := zsx.GetList(node)
+	start, delim := 0, ""
+	if sym == zsx.SymListOrdered {
+		enc.checkAttributes(node, attrs, "start")
+		start, delim = 1, "."
+		if val, found := zsx.GetAttributes(attrs).Get("start"); found {
+			if num, err := strconv.Atoi(val); err == nil && num >= 0 && num <= 999999999 {
+				start = num
+			} else {
+				enc.addLossy(node)
+			}
+		}
+		if enc.altMarker {
+			delim = ")"
+		}
+	} else {
+		enc.checkAttributes(node, attrs)
+		delim = "-"
+		if enc.altMarker {
+			delim = "*"
+		}
+	}
+
+	// A list is loose, if one of its items contains more than one block.
+	// Otherwise the blocks would not be separated by an empty line.
+	loose := false
+	for item := range items.Values() {
+		if itemNode, isPair := sx.GetPair(item); isPair {
+			if _, elems := zsx.GetListItem(itemNode); elems != nil && elems.Tail() != nil {
+				loose = true
+			}
+		}
+	}
+
+	prevPrefix := enc.prefix
+	i := 0
+	for item := range items.Values() {
+		itemNode, isPair := sx.GetPair(item)
+		if !isPair {
+			continue
+		}
+		if i > 0 {
+			enc.newline()
+			if loose {
+				enc.newline()
+			}
+		}
+		marker := delim
+		if start > 0 {
+			marker = strconv.Itoa(start+i) + delim
+		}
+		attrs, elems := zsx.GetListItem(itemNode)
+		enc.checkAttributes(itemNode, attrs)
+		enc.write(marker)
+		if elems != nil {
+			enc.write(" ")
+			enc.prefix = prevPrefix + strings.Repeat(" ", len(marker)+1)
+			enc.writeBlocks(elems, alst)
+			enc.prefix = prevPrefix
+		}
+		i++
+	}
+}
+
+func (enc *encoder) encodeQuotation(node *sx.Pair, alst *sx.Pair) {
+	_, attrs, items := zsx.GetList(node)
+	enc.checkAttributes(node, attrs)
+	var lb sx.ListBuilder
+	count := 0
+	for item := range items.Values() {
+		if itemNode, isPair := sx.GetPair(item); isPair {
+			attrs, elems := zsx.GetListItem(itemNode)
+			enc.checkAttributes(itemNode, attrs)
+			if count++; count == 2 {
+				// Items of a block quote cannot be separated in Markdown.
+				enc.addLossy(node)
+			}
+			for elem := range elems.Values() {
+				lb.Add(elem)
+			}
+		}
+	}
+	enc.writeBlockQuote(lb.List(), alst)
+}
+
+// writeBlockQuote writes the blocks as a block quote.
+func (enc *encoder) writeBlockQuote(blocks *sx.Pair, alst *sx.Pair) {
+	prevPrefix := enc.prefix
+	enc.prefix += "> "
+	if !enc.pending {
+		enc.write("> ")
+	}
+	if blocks == nil {
+		enc.write("") // An empty block quote consists of its prefix only
+	}
+	enc.writeBlocks(blocks, alst)
+	enc.prefix = prevPrefix
+}
+
+func (enc *encoder) encodeDescription(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	if enc.opts.Fallback == FallbackHTML {
+		enc.writeFallbackHTML(node)
+		return
+	}
+	_, elems := zsx.GetDescription(node)
+	first := true
+	for elem := range elems.Values() {
+		elemNode, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		switch zsx.NodeSymbol(elemNode) {
+		case zsx.SymTerm:
+			if !first {
+				enc.newline()
+				enc.newline()
+			}
+			_, ins := zsx.GetTerm(elemNode)
+			enc.writeInlines(ins, alst)
+			first = false
+		case zsx.SymDetail:
+			for entry := range zsx.GetDetail(elemNode).Values() {
+				if entryNode, isEntry := sx.GetPair(entry); isEntry {
+					if !first {
+						enc.newline()
+						enc.newline()
+					}
+					_, blocks := zsx.GetEntry(entryNode)
+					enc.writeBlocks(blocks, alst)
+					first = false
+				}
+			}
+		}
+	}
+}
+
+func (enc *encoder) encodeTable(node *sx.Pair, alst *sx.Pair) {
+	if !enc.opts.GFM {
+		enc.addLossy(node)
+		if enc.opts.Fallback == FallbackHTML {
+			enc.writeFallbackHTML(node)
+		} else {
+			enc.writeTableText(node, alst)
+		}
+		return
+	}
+
+	attrs, header, rows := zsx.GetTable(node)
+	enc.checkAttributes(node, attrs)
+	var allRows []*sx.Pair
+	if header != nil {
+		allRows = append(allRows, header)
+	}
+	for row := range rows.Values() {
+		if rowNode, isPair := sx.GetPair(row); isPair {
+			allRows = append(allRows, rowNode)
+		}
+	}
+
+	// The alignment of a column is taken from its first cell with an
+	// alignment.
+	var aligns []string
+	for _, row := range allRows {
+		i := 0
+		_, cells := zsx.GetRow(row)
+		for cell := range cells.Values() {
+			if cellNode, isPair := sx.GetPair(cell); isPair {
+				attrs, _ := zsx.GetCell(cellNode)
+				align, _ := zsx.GetAttributes(attrs).Get(zsx.SymAttrAlign.GetValue())
+				if i >= len(aligns) {
+					aligns = append(aligns, align)
+				} else if aligns[i] == "" {
+					aligns[i] = align
+				}
+				i++
+			}
+		}
+	}
+	if len(aligns) == 0 {
+		return
+	}
+
+	if header == nil {
+		// A GFM table always has a header row.
+		enc.addLossy(node)
+		enc.write("|" + strings.Repeat("  |", len(aligns)))
+	} else {
+		enc.writeTableRow(header, aligns, alst)
+	}
+	enc.newline()
+	enc.write("|")
+	for _, align := range aligns {
+		switch align {
+		case zsx.AttrAlignLeft.GetValue():
+			enc.write(" :-- |")
+		case zsx.AttrAlignCenter.GetValue():
+			enc.write(" :-: |")
+		case zsx.AttrAlignRight.GetValue():
+			enc.write(" --: |")
+		default:
+			enc.write(" --- |")
+		}
+	}
+	for row := range rows.Values() {
+		if rowNode, isPair := sx.GetPair(row); isPair {
+			enc.newline()
+			enc.writeTableRow(rowNode, aligns, alst)
+		}
+	}
+}
+
+// writeTableRow writes a row of a GFM table. Missing cells are written as
+// empty cells.
+func (enc *encoder) writeTableRow(row *sx.Pair, aligns []string, alst *sx.Pair) {
+	attrs, cells := zsx.GetRow(row)
+	enc.checkAttributes(row, attrs)
+	enc.write("|")
+	i := 0
+	enc.inCell = true
+	for cell := range cells.Values() {
+		if cellNode, isPair := sx.GetPair(cell); isPair {
+			attrs, ins := zsx.GetCell(cellNode)
+			enc.checkAttributes(cellNode, attrs, zsx.SymAttrAlign.GetValue())
+			if align, _ := zsx.GetAttributes(attrs).Get(zsx.SymAttrAlign.GetValue()); align != "" && align != aligns[i] {
+				enc.addLossy(cellNode)
+			}
+			enc.write(" ")
+			zsx.WalkItList(enc, ins, 0, alst)
+			enc.write(" |")
+			i++
+		}
+	}
+	enc.inCell = false
+	for ; i < len(aligns); i++ {
+		enc.write("  |")
+	}
+}
+
+// writeTableText writes each row of a table as a paragraph.
+func (enc *encoder) writeTableText(node *sx.Pair, alst *sx.Pair) {
+	_, header, rows := zsx.GetTable(node)
+	first := true
+	for row := range rows.Cons(header).Values() {
+		rowNode, isPair := sx.GetPair(row)
+		if !isPair || rowNode == nil {
+			continue
+		}
+		if !first {
+			enc.newline()
+			enc.newline()
+		}
+		enc.lineStart = true
+		_, cells := zsx.GetRow(rowNode)
+		for cn := range cells.Pairs() {
+			if cellNode, isCell := sx.GetPair(cn.Car()); isCell {
+				_, ins := zsx.GetCell(cellNode)
+				zsx.WalkItList(enc, ins, 0, alst)
+				if cn.Tail() != nil {
+					enc.write(" | ")
+				}
+			}
+		}
+		first = false
+	}
+}
+
+func (enc *encoder) encodeRegion(node *sx.Pair, alst *sx.Pair) {
+	sym, _, blocks, ins := zsx.GetRegion(node)
+	enc.addLossy(node)
+	if ins != nil {
+		var lb sx.ListBuilder
+		for block := range blocks.Values() {
+			lb.Add(block)
+		}
+		lb.Add(zsx.MakeParaList(ins.Cons(zsx.MakeText("— "))))
+		blocks = lb.List()
+	}
+	switch sym {
+	case zsx.SymRegionQuote:
+		enc.writeBlockQuote(blocks, alst)
+	case zsx.SymRegionVerse:
+		enc.inVerse = true
+		enc.writeBlocks(blocks, alst)
+		enc.inVerse = false
+	default:
+		enc.writeBlocks(blocks, alst)
+	}
+}
+
+// verbatimInfos contains the info string of a fenced code block, if the
+// verbatim node does not specify a language.
+var verbatimInfos = map[*sx.Symbol]string{
+	zsx.SymVerbatimMath: "math",
+}
+
+func (enc *encoder) encodeVerbatim(node *sx.Pair, _ *sx.Pair) {
+	sym, attrs, content := zsx.GetVerbatim(node)
+	if sym == zsx.SymVerbatimCode {
+		enc.checkAttributes(node, attrs, "")
+	} else {
+		enc.addLossy(node)
+	}
+	info, found := zsx.GetAttributes(attrs).Get("")
+	if !found {
+		info = verbatimInfos[sym]
+	}
+	if strings.ContainsAny(info, "`\n") {
+		enc.addLossy(node)
+		info = ""
+	}
+
+	longest, run := 0, 0
+	for _, ch := range content {
+		if ch == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	enc.write(fence + info)
+	enc.newline()
+	if content != "" {
+		enc.writeLines(content)
+		enc.newline()
+	}
+	enc.write(fence)
+}
+
+func (enc *encoder) encodeVerbatimHTML(node *sx.Pair, _ *sx.Pair) {
+	_, attrs, content := zsx.GetVerbatim(node)
+	enc.checkAttributes(node, attrs)
+	enc.writeLines(content)
+}
+
+func (enc *encoder) encodeVerbatimComment(node *sx.Pair, _ *sx.Pair) {
+	enc.addLossy(node)
+	if enc.opts.Fallback == FallbackHTML {
+		_, _, content := zsx.GetVerbatim(node)
+		enc.writeLines("<!--\n" + strings.ReplaceAll(content, "--", "- -") + "\n-->")
+	}
+}
+
+func (enc *encoder) encodeTransclusion(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	attrs, ref, ins := zsx.GetTransclusion(node)
+	enc.writeLink(node, "[", attrs, ref, ins, alst)
+}
+
+func (enc *encoder) encodeBLOB(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	_, syntax, data, ins := zsx.GetBLOBuncode(node)
+	enc.writeDataImage(syntax, data, ins, alst)
+}
+
+// writeDataImage writes an image with the given data as a data URL.
+func (enc *encoder) writeDataImage(syntax, data string, ins *sx.Pair, alst *sx.Pair) {
+	enc.write("![")
+	zsx.WalkItList(enc, ins, 0, alst)
+	enc.write("](" + zsx.DataURL(syntax, data) + ")")
+}
+
+func (enc *encoder) encodeInline(node *sx.Pair, alst *sx.Pair) {
+	zsx.WalkItList(enc, node, 1, alst)
+}
+
+func (enc *encoder) encodeText(node *sx.Pair, _ *sx.Pair) {
+	enc.writeText(zsx.GetText(node))
+}
+
+// lineStartSet contains all characters that may start a block element.
+const lineStartSet = "#>+-=~"
+
+// writeText writes the text, where all characters are escaped that would
+// otherwise be interpreted as Markdown.
+func (enc *encoder) writeText(s string) {
+	var sb strings.Builder
+	if enc.lineStart {
+		enc.lineStart = false
+		digits := 0
+		for digits < len(s) && digits < 10 && s[digits] >= '0' && s[digits] <= '9' {
+			digits++
+		}
+		if digits > 0 && digits < len(s) && (s[digits] == '.' || s[digits] == ')') {
+			sb.WriteString(s[:digits])
+			sb.WriteByte('\\')
+			sb.WriteByte(s[digits])
+			s = s[digits+1:]
+		} else if s != "" && strings.IndexByte(lineStartSet, s[0]) >= 0 {
+			sb.WriteByte('\\')
+			sb.WriteByte(s[0])
+			s = s[1:]
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch ch {
+		case '\\', '`', '*', '[', ']', '<':
+			sb.WriteByte('\\')
+		case '_':
+			if i == 0 || i+1 == len(s) || !isWordByte(s[i-1]) || !isWordByte(s[i+1]) {
+				sb.WriteByte('\\')
+			}
+		case '&':
+			if i+1 < len(s) && (s[i+1] == '#' || isLetterByte(s[i+1])) {
+				sb.WriteByte('\\')
+			}
+		case '#':
+			if enc.inHeading {
+				sb.WriteByte('\\')
+			}
+		case '~':
+			if enc.opts.GFM {
+				sb.WriteByte('\\')
+			}
+		case '|':
+			if enc.inCell {
+				sb.WriteByte('\\')
+			}
+		}
+		sb.WriteByte(ch)
+	}
+	enc.write(sb.String())
+}
+
+// isWordByte reports whether the byte is part of a word, where an
+// underscore does not start or end an emphasis. All bytes of non-ASCII
+// characters are treated as letters.
+func isWordByte(ch byte) bool {
+	return isLetterByte(ch) || (ch >= '0' && ch <= '9') || ch >= 0x80
+}
+
+func isLetterByte(ch byte) bool { return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') }
+
+func (enc *encoder) encodeSoft(node *sx.Pair, alst *sx.Pair) {
+	switch {
+	case enc.inHeading || enc.inCell:
+		enc.write(" ")
+	case enc.inVerse:
+		enc.encodeHard(node, alst)
+	default:
+		enc.newline()
+	}
+}
+
+func (enc *encoder) encodeHard(node *sx.Pair, _ *sx.Pair) {
+	switch {
+	case enc.inCell && enc.opts.Fallback == FallbackHTML:
+		enc.addLossy(node)
+		enc.write("<br>")
+	case enc.inHeading || enc.inCell:
+		enc.addLossy(node)
+		enc.write(" ")
+	default:
+		enc.write("\\")
+		enc.newline()
+	}
+}
+
+func (enc *encoder) encodeLink(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, ins := zsx.GetLink(node)
+	if sym, _ := zsx.GetReference(ref); sym == zsx.SymRefStateInvalid {
+		enc.addLossy(node)
+		if ins == nil {
+			_, val := zsx.GetReference(ref)
+			enc.writeText(val)
+		}
+		zsx.WalkItList(enc, ins, 0, alst)
+		return
+	}
+	enc.checkAttributes(node, attrs, "title")
+	enc.writeLink(node, "[", attrs, ref, ins, alst)
+}
+
+func (enc *encoder) encodeEmbed(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, syntax, ins := zsx.GetEmbed(node)
+	enc.checkAttributes(node, attrs, "title")
+	if syntax != "" {
+		enc.addLossy(node)
+	}
+	enc.writeLink(node, "![", attrs, ref, ins, alst)
+}
+
+// writeLink writes a link or an image. If there are no inline nodes, the
+// reference is used as the link text.
+func (enc *encoder) writeLink(node *sx.Pair, start string, attrs, ref, ins *sx.Pair, alst *sx.Pair) {
+	sym, dest := zsx.GetReference(ref)
+	switch sym {
+	case zsx.SymRefStateExternal, zsx.SymRefStateHosted, zsx.SymRefStateSelf:
+	default:
+		enc.addLossy(node)
+	}
+	enc.write(start)
+	if ins == nil {
+		enc.writeText(dest)
+	} else {
+		zsx.WalkItList(enc, ins, 0, alst)
+	}
+	enc.write("](" + linkDestination(dest))
+	if title, found := zsx.GetAttributes(attrs).Get("title"); found {
+		enc.write(" \"" + titleEscaper.Replace(title) + "\"")
+	}
+	enc.write(")")
+}
+
+var (
+	destEscaper  = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+	angleEscaper = strings.NewReplacer(`\`, `\\`, "<", `\<`, ">", `\>`)
+	titleEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// linkDestination returns the destination of a link, enclosed in angle
+// brackets if needed.
+func linkDestination(dest string) string {
+	if dest == "" || strings.ContainsAny(dest, " \t\n<>") {
+		return "<" + angleEscaper.Replace(dest) + ">"
+	}
+	return destEscaper.Replace(dest)
+}
+
+func (enc *encoder) encodeEmbedBLOB(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	_, syntax, data, ins := zsx.GetEmbedBLOBuncode(node)
+	enc.writeDataImage(syntax, data, ins, alst)
+}
+
+func (enc *encoder) encodeCite(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	_, key, ins := zsx.GetCite(node)
+	if enc.opts.Fallback == FallbackHTML {
+		enc.write("<cite>" + htmlEscaper.Replace(key))
+	} else {
+		enc.write(`\[`)
+		enc.writeText(key)
+	}
+	if ins != nil {
+		enc.write(", ")
+		zsx.WalkItList(enc, ins, 0, alst)
+	}
+	if enc.opts.Fallback == FallbackHTML {
+		enc.write("</cite>")
+	} else {
+		enc.write(`\]`)
+	}
+}
+
+func (enc *encoder) encodeEndnote(node *sx.Pair, _ *sx.Pair) {
+	enc.endnotes = append(enc.endnotes, node)
+	num := strconv.Itoa(len(enc.endnotes))
+	if enc.opts.GFM {
+		enc.write("[^" + num + "]")
+	} else {
+		enc.addLossy(node)
+		enc.write(`\[` + num + `\]`)
+	}
+}
+
+// writeEndnotes writes all collected endnotes, including those that are
+// referenced within an endnote. GFM footnotes are used, if enabled.
+// Otherwise each endnote is written as a paragraph.
+func (enc *encoder) writeEndnotes() {
+	enc.prefix = ""
+	for i := 0; i < len(enc.endnotes); i++ {
+		enc.newline()
+		enc.newline()
+		_, ins := zsx.GetEndnote(enc.endnotes[i])
+		num := strconv.Itoa(i + 1)
+		if enc.opts.GFM {
+			enc.write("[^" + num + "]: ")
+			enc.prefix = "    "
+		} else {
+			enc.write(`\[` + num + `\] `)
+		}
+		zsx.WalkItList(enc, ins, 0, nil)
+		enc.prefix = ""
+	}
+}
+
+func (enc *encoder) encodeMark(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	_, mark, ins := zsx.GetMark(node)
+	if enc.opts.Fallback == FallbackHTML {
+		enc.write("<a id=\"" + htmlEscaper.Replace(mark) + "\"></a>")
+	}
+	zsx.WalkItList(enc, ins, 0, alst)
+}
+
+func (enc *encoder) encodeEmphasis(node *sx.Pair, alst *sx.Pair) {
+	sym, attrs, ins := zsx.GetFormat(node)
+	enc.checkAttributes(node, attrs)
+
+	// Nested emphasis at the start or at the end of an outer emphasis uses
+	// the underscore, so that the delimiters are not merged.
+	delim := "*"
+	if enc.inFormat > 0 {
+		if pos := zsx.GetWalkPos(alst); pos == 0 || zsx.GetWalkList(alst).Tail() == nil {
+			delim = "_"
+		}
+	}
+	if sym == zsx.SymFormatStrong {
+		delim += delim
+	}
+	enc.write(delim)
+	enc.inFormat++
+	zsx.WalkItList(enc, ins, 0, alst)
+	enc.inFormat--
+	enc.write(delim)
+}
+
+func (enc *encoder) encodeDelete(node *sx.Pair, alst *sx.Pair) {
+	if !enc.opts.GFM {
+		enc.encodeFormat(node, alst)
+		return
+	}
+	_, attrs, ins := zsx.GetFormat(node)
+	enc.checkAttributes(node, attrs)
+	enc.write("~~")
+	zsx.WalkItList(enc, ins, 0, alst)
+	enc.write("~~")
+}
+
+var formatTags = map[*sx.Symbol]string{
+	zsx.SymFormatDelete: "del",
+	zsx.SymFormatInsert: "ins",
+	zsx.SymFormatMark:   "mark",
+	zsx.SymFormatQuote:  "q",
+	zsx.SymFormatSpan:   "span",
+	zsx.SymFormatSub:    "sub",
+	zsx.SymFormatSuper:  "sup",
+}
+
+// encodeFormat writes a format node that has no Markdown equivalent.
+func (enc *encoder) encodeFormat(node *sx.Pair, alst *sx.Pair) {
+	enc.addLossy(node)
+	sym, _, ins := zsx.GetFormat(node)
+	switch {
+	case enc.opts.Fallback == FallbackHTML:
+		tag := formatTags[sym]
+		enc.write("<" + tag + ">")
+		zsx.WalkItList(enc, ins, 0, alst)
+		enc.write("</" + tag + ">")
+	case sym == zsx.SymFormatQuote:
+		enc.write(`"`)
+		zsx.WalkItList(enc, ins, 0, alst)
+		enc.write(`"`)
+	default:
+		zsx.WalkItList(enc, ins, 0, alst)
+	}
+}
+
+var literalTags = map[*sx.Symbol]string{
+	zsx.SymLiteralInput:  "kbd",
+	zsx.SymLiteralOutput: "samp",
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func (enc *encoder) encodeLiteral(node *sx.Pair, _ *sx.Pair) {
+	sym, attrs, content := zsx.GetLiteral(node)
+	if sym == zsx.SymLiteralCode {
+		if syntax, _ := zsx.GetAttributes(attrs).Get(""); syntax == "html" {
+			enc.checkAttributes(node, attrs, "")
+			enc.writeLines(content)
+			return
+		}
+		enc.checkAttributes(node, attrs)
+	} else {
+		enc.addLossy(node)
+		if tag, found := literalTags[sym]; found && enc.opts.Fallback == FallbackHTML {
+			enc.writeLines("<" + tag + ">" + htmlEscaper.Replace(content) + "</" + tag + ">")
+			return
+		}
+	}
+	enc.writeLines(enc.codeSpan(content))
+}
+
+// codeSpan returns the content as a code span.
+func (enc *encoder) codeSpan(content string) string {
+	longest, run := 0, 0
+	for _, ch := range content {
+		if ch == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	if content == "" ||
+		content[0] == '`' || content[len(content)-1] == '`' ||
+		(content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "") {
+		content = " " + content + " "
+	}
+	if enc.inCell {
+		content = strings.ReplaceAll(content, "|", `\|`)
+	}
+	fence := strings.Repeat("`", longest+1)
+	return fence + content + fence
+}
+
+func (enc *encoder) encodeLiteralComment(node *sx.Pair, _ *sx.Pair) {
+	enc.addLossy(node)
+	if enc.opts.Fallback == FallbackHTML {
+		_, _, content := zsx.GetLiteral(node)
+		enc.writeLines("<!-- " + strings.ReplaceAll(content, "--", "- -") + " -->")
+	}
+}
@@ -0,0 +1,206 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/internal/layouttest"
+	"t73f.de/r/zsx/markdown"
+	"t73f.de/r/zsx/zmk"
+)
+
+func encode(t *testing.T, node *sx.Pair, opts markdown.Options) (string, []*sx.Pair) {
+	t.Helper()
+	var sb strings.Builder
+	lossy, err := markdown.Encode(&sb, node, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sb.String(), lossy
+}
+
+// TestRoundTrip checks that parsing the encoded tree results in the same
+// tree, and that no node is reported as lossy.
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+		exp  string
+	}{
+		{"para", "a\nb  \nc\n\nd", "a\nb\\\nc\n\nd"},
+		{"escapes", `\*a\* \_b\_ snake_case \[c\] \<d> \\ \` + "`e`", `\*a\* \_b\_ snake_case \[c\] \<d> \\ \` + "`e\\`"},
+		{"line-start", "\\# a\n\\- b\n1\\. c\n\\> d\n\\+ e", "\\# a\n\\- b\n1\\. c\n\\> d\n\\+ e"},
+		{"entity", "&amp;amp; & b", "\\&amp; & b"},
+		{"heading", "# A *b*\n\n###### C \\#", "# A *b*\n\n###### C \\#"},
+		{"thematic", "---", "***"},
+		{"emphasis", "*a* **b** ***c*** *a **b***", "*a* **b** *__c__* *a __b__*"},
+		{"nested-emph", "a *b _c_ d* e", "a *b *c* d* e"},
+		{"strike", "~~a~~ b~c", "~~a~~ b\\~c"},
+		{"code", "`a` `` b`c `` ` `` `", "`a` ``b`c`` ``` `` ```"},
+		{"html", "a <b>c</b>", "a <b>c</b>"},
+		{"link", `[a](/url "t") [b](<c d>) [e](f\(g\))`, `[a](/url "t") [b](<c d>) [e](f\(g\))`},
+		{"image", `![a *b*](img.png "t")`, `![a *b*](img.png "t")`},
+		{"autolink", "<https://t73f.de>", "[https://t73f.de](https://t73f.de)"},
+		{"fenced", "~~~ go\ncode\n```\n~~~", "````go\ncode\n```\n````"},
+		{"indented", "    a\n\n    b", "```\na\n\nb\n```"},
+		{"html-block", "<div>\na\n</div>", "<div>\na\n</div>"},
+		{"quote", "> a\n>\n> - b", "> a\n>\n> - b"},
+		{"quote-lazy", "> a\nb", "> a\n> b"},
+		{"list", "- a\n- b\n  - c\n\n  d", "- a\n\n- b\n\n  - c\n\n  d"},
+		{"list-tight", "* a\n* b", "- a\n- b"},
+		{"list-ordered", "3. a\n4. b", "3. a\n4. b"},
+		{"list-adjacent", "- a\n\n\n* b\n\n\n+ c", "- a\n\n* b\n\n- c"},
+		{"list-empty", "-\n- a", "-\n- a"},
+		{"list-code", "1. ```\n   a\n\n   b\n   ```", "1. ```\n   a\n\n   b\n   ```"},
+		{"table", "| a | b |\n|:-|--:|\n| c \\| d | `e\\|f` |\n| g |", "| a | b |\n| :-- | --: |\n| c \\| d | `e\\|f` |\n| g |  |"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := markdown.ParseBlocks(input.NewInput([]byte(tc.src)))
+			got, lossy := encode(t, tree, markdown.Options{GFM: true})
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %q\ngot: %q", tc.src, tc.exp, got)
+			}
+			if lossy != nil {
+				t.Errorf("lossy nodes: %v", lossy)
+			}
+			tree2 := markdown.ParseBlocks(input.NewInput([]byte(got)))
+			if s1, s2 := tree.String(), tree2.String(); s1 != s2 {
+				t.Errorf("\nenc:  %q\ntree: %s\ngot:  %s", got, s1, s2)
+			}
+		})
+	}
+}
+
+// TestEncodeLossy checks the encoding of nodes that have no equivalent in
+// CommonMark.
+func TestEncodeLossy(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name  string
+		src   string
+		opts  markdown.Options
+		exp   string
+		lossy string
+	}{
+		{"formats-html", ">>a>> ##b## ,,c,, ^^d^^ \"\"e\"\" ::f:: ~~g~~", markdown.Options{},
+			"<ins>a</ins> <mark>b</mark> <sub>c</sub> <sup>d</sup> <q>e</q> <span>f</span> <del>g</del>",
+			"FORMAT-INSERT FORMAT-MARK FORMAT-SUB FORMAT-SUPER FORMAT-QUOTE FORMAT-SPAN FORMAT-DELETE"},
+		{"formats-text", ">>a>> \"\"e\"\" ~~g~~", markdown.Options{Fallback: markdown.FallbackText},
+			"a \"e\" g", "FORMAT-INSERT FORMAT-QUOTE FORMAT-DELETE"},
+		{"formats-gfm", "~~g~~", markdown.Options{GFM: true, Fallback: markdown.FallbackText}, "~~g~~", ""},
+		{"attributes", "__a__{lang=de} [[b|https://t73f.de]]{title=t}", markdown.Options{},
+			"*a* [b](https://t73f.de \"t\")", "FORMAT-EMPH"},
+		{"literals-html", "''a<b'' ==c== $$d$$ %%e", markdown.Options{},
+			"<kbd>a&lt;b</kbd> <samp>c</samp> `d` <!-- e -->", "LITERAL-INPUT LITERAL-OUTPUT LITERAL-MATH LITERAL-COMMENT"},
+		{"literals-text", "''a<b'' ==c== %%e", markdown.Options{Fallback: markdown.FallbackText},
+			"`a<b` `c` ", "LITERAL-INPUT LITERAL-OUTPUT LITERAL-COMMENT"},
+		{"cite-html", "[@key p. 7]", markdown.Options{}, "<cite>key, p. 7</cite>", "CITE"},
+		{"cite-text", "[@key]", markdown.Options{Fallback: markdown.FallbackText}, `\[key\]`, "CITE"},
+		{"mark", "[!m|a]", markdown.Options{}, `<a id="m"></a>a`, "MARK"},
+		{"link-invalid", "[[a|:b]]", markdown.Options{}, "a", "LINK"},
+		{"endnote", "a[^b[^c]]", markdown.Options{},
+			"a\\[1\\]\n\n\\[1\\] b\\[2\\]\n\n\\[2\\] c", "ENDNOTE ENDNOTE"},
+		{"endnote-gfm", "a[^b\nc]", markdown.Options{GFM: true}, "a[^1]\n\n[^1]: b\n    c", ""},
+		{"heading", "=== a {id=b}", markdown.Options{}, "# a", "HEADING"},
+		{"description-html", "; a\n: b", markdown.Options{},
+			"<dl><dt>a</dt><dd><p>b</p></dd></dl>", "DESCRIPTION"},
+		{"description-text", "; a\n: b\n: c\n; d", markdown.Options{Fallback: markdown.FallbackText},
+			"a\n\nb\n\nc\n\nd", "DESCRIPTION"},
+		{"table-text", "|=a|=b\n|c|d", markdown.Options{Fallback: markdown.FallbackText}, "a | b\n\nc | d", "TABLE"},
+		{"table-no-header", "|a|b", markdown.Options{GFM: true}, "|  |  |\n| --- | --- |\n| a | b |", "TABLE"},
+		{"table-hard", "|a\\\nb|>c\n|d|<e", markdown.Options{GFM: true},
+			"|  |  |\n| --- | --: |\n| a<br>b | c |\n| d | e |", "TABLE HARD CELL"},
+		{"region-quote", "<<<\na\n<<< b", markdown.Options{}, "> a\n>\n> — b", "REGION-QUOTE"},
+		{"region-verse", "\"\"\"\na\nb\n\"\"\"", markdown.Options{}, "a\\\nb", "REGION-VERSE"},
+		{"verbatim", "$$$\nx^2\n$$$\n\n%%%\na--b\n%%%", markdown.Options{}, "```math\nx^2\n```\n\n<!--\na- -b\n-->",
+			"VERBATIM-MATH VERBATIM-COMMENT"},
+		{"quotation", "> a\n> b", markdown.Options{}, "> a\n>\n> b", "QUOTATION"},
+		{"embed", "{{a|img.png}}{=png}", markdown.Options{}, "![a](img.png)", "EMBED"},
+		{"transclude", "{{{ref}}}", markdown.Options{}, "[ref](ref)", "TRANSCLUDE"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := zmk.ParseBlocks(input.NewInput([]byte(tc.src)))
+			got, lossy := encode(t, tree, tc.opts)
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %q\ngot: %q", tc.src, tc.exp, got)
+			}
+			var syms []string
+			for _, node := range lossy {
+				syms = append(syms, zsx.NodeSymbol(node).GetValue())
+			}
+			if got := strings.Join(syms, " "); got != tc.lossy {
+				t.Errorf("\nexp lossy: %q\ngot lossy: %q", tc.lossy, got)
+			}
+		})
+	}
+}
+
+func TestEncodeBLOB(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeBlock(zsx.MakeBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), sx.MakeList(zsx.MakeText("a"))))
+	got, lossy := encode(t, node, markdown.Options{})
+	if exp := "![a](data:image/svg+xml;base64,PHN2Zy8+)"; got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+	if len(lossy) != 1 || lossy[0] != zsx.GetBlock(node).Head() {
+		t.Errorf("lossy nodes: %v", lossy)
+	}
+}
+
+func TestEncodeOther(t *testing.T) {
+	t.Parallel()
+	layouttest.Register(t)
+	text := zsx.MakeText
+	aside := layouttest.MakeAside(sx.MakeList(text("t")), zsx.MakePara(text("s")), zsx.MakePara(text("a")), zsx.MakePara(text("b")))
+	badge := layouttest.MakeBadge(text("i"), text("c"), zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text("d"))))
+	unknown := sx.MakeList(sx.MakeSymbol("UNKNOWN"), text("e"))
+	testcases := []struct {
+		name  string
+		node  *sx.Pair
+		opts  markdown.Options
+		exp   string
+		lossy string
+	}{
+		{"custom-html", zsx.MakeBlock(aside), markdown.Options{}, "t<p>s</p><p>a</p><p>b</p>", "ASIDE"},
+		{"custom-text", zsx.MakeBlock(aside, zsx.MakePara(text("x"), badge)), markdown.Options{Fallback: markdown.FallbackText},
+			"t\n\ns\n\na\n\nb\n\nxic*d*", "ASIDE BADGE"},
+		{"unknown-html", zsx.MakeBlock(zsx.MakePara(text("x"), unknown)), markdown.Options{}, "x", "UNKNOWN"},
+		{"unknown-text", zsx.MakeBlock(zsx.MakePara(text("x"), unknown)), markdown.Options{Fallback: markdown.FallbackText},
+			"x", "UNKNOWN"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, lossy := encode(t, tc.node, tc.opts)
+			if got != tc.exp {
+				t.Errorf("\nnode: %v\nexp:  %q\ngot:  %q", tc.node, tc.exp, got)
+			}
+			var syms []string
+			for _, node := range lossy {
+				syms = append(syms, zsx.NodeSymbol(node).GetValue())
+			}
+			if got := strings.Join(syms, " "); got != tc.lossy {
+				t.Errorf("\nexp lossy: %q\ngot lossy: %q", tc.lossy, got)
+			}
+		})
+	}
+}
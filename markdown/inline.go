@@ -0,0 +1,888 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// inlineKind specifies the type of an inline node.
+type inlineKind uint8
+
+// Constants for inlineKind.
+const (
+	inlineRoot inlineKind = iota
+	inlineText
+	inlineSoft
+	inlineHard
+	inlineCode
+	inlineHTML
+	inlineEmph
+	inlineStrong
+	inlineDelete
+	inlineLink
+	inlineImage
+)
+
+// inline is a node of the inline structure. Inline nodes form a doubly
+// linked tree, because emphasis processing and link detection must rearrange
+// already parsed nodes.
+type inline struct {
+	kind    inlineKind
+	literal string
+	dest    string
+	title   string
+
+	parent, first, last, prev, next *inline
+}
+
+func (n *inline) appendChild(child *inline) {
+	child.unlink()
+	child.parent = n
+	if n.last != nil {
+		n.last.next = child
+		child.prev = n.last
+	} else {
+		n.first = child
+	}
+	n.last = child
+}
+
+func (n *inline) insertAfter(sibling *inline) {
+	sibling.unlink()
+	sibling.next = n.next
+	if sibling.next != nil {
+		sibling.next.prev = sibling
+	}
+	sibling.prev = n
+	n.next = sibling
+	sibling.parent = n.parent
+	if sibling.next == nil && sibling.parent != nil {
+		sibling.parent.last = sibling
+	}
+}
+
+func (n *inline) unlink() {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else if n.parent != nil {
+		n.parent.first = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else if n.parent != nil {
+		n.parent.last = n.prev
+	}
+	n.parent, n.next, n.prev = nil, nil, nil
+}
+
+func makeText(s string) *inline { return &inline{kind: inlineText, literal: s} }
+
+// delimiter is an entry of the delimiter stack, used for emphasis and
+// strikethrough.
+type delimiter struct {
+	cc         byte
+	numdelims  int
+	origdelims int
+	node       *inline
+	previous   *delimiter
+	next       *delimiter
+	canOpen    bool
+	canClose   bool
+}
+
+// bracket is an entry of the bracket stack, used for links and images.
+type bracket struct {
+	node              *inline
+	previous          *bracket
+	previousDelimiter *delimiter
+	index             int
+	image             bool
+	active            bool
+	bracketAfter      bool
+}
+
+// inlineParser parses the inline content of a block.
+type inlineParser struct {
+	subject    string
+	pos        int
+	delimiters *delimiter
+	brackets   *bracket
+	refmap     map[string]linkRef
+}
+
+// parseInlines parses the given content into a tree of inline nodes.
+func parseInlines(content string, refmap map[string]linkRef) *inline {
+	p := inlineParser{subject: strings.TrimSpace(content), refmap: refmap}
+	root := &inline{kind: inlineRoot}
+	for p.parseInline(root) {
+	}
+	p.processEmphasis(nil)
+	return root
+}
+
+func (p *inlineParser) peek() int { return peek(p.subject, p.pos) }
+
+func (p *inlineParser) parseInline(block *inline) bool {
+	c := p.peek()
+	if c == -1 {
+		return false
+	}
+	res := false
+	switch c {
+	case '\n':
+		res = p.parseNewline(block)
+	case '\\':
+		res = p.parseBackslash(block)
+	case '`':
+		res = p.parseBackticks(block)
+	case '*', '_', '~':
+		res = p.handleDelim(byte(c), block)
+	case '[':
+		res = p.parseOpenBracket(block)
+	case '!':
+		res = p.parseBang(block)
+	case ']':
+		res = p.parseCloseBracket(block)
+	case '<':
+		res = p.parseAutolink(block) || p.parseHTMLTag(block)
+	case '&':
+		res = p.parseEntity(block)
+	default:
+		res = p.parseString(block)
+	}
+	if !res {
+		_, size := utf8.DecodeRuneInString(p.subject[p.pos:])
+		block.appendChild(makeText(p.subject[p.pos : p.pos+size]))
+		p.pos += size
+	}
+	return true
+}
+
+func isSpecial(c byte) bool {
+	switch c {
+	case '\n', '`', '[', ']', '\\', '!', '<', '&', '*', '_', '~':
+		return true
+	}
+	return false
+}
+
+func (p *inlineParser) parseString(block *inline) bool {
+	start := p.pos
+	for p.pos < len(p.subject) && !isSpecial(p.subject[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return false
+	}
+	block.appendChild(makeText(p.subject[start:p.pos]))
+	return true
+}
+
+func (p *inlineParser) parseNewline(block *inline) bool {
+	p.pos++
+	if last := block.last; last != nil && last.kind == inlineText && strings.HasSuffix(last.literal, " ") {
+		hard := strings.HasSuffix(last.literal, "  ")
+		last.literal = strings.TrimRight(last.literal, " ")
+		if hard {
+			block.appendChild(&inline{kind: inlineHard})
+		} else {
+			block.appendChild(&inline{kind: inlineSoft})
+		}
+	} else {
+		block.appendChild(&inline{kind: inlineSoft})
+	}
+	for p.peek() == ' ' {
+		p.pos++
+	}
+	return true
+}
+
+func isASCIIPunct(c int) bool {
+	return (c >= '!' && c <= '/') || (c >= ':' && c <= '@') || (c >= '[' && c <= '`') || (c >= '{' && c <= '~')
+}
+
+func (p *inlineParser) parseBackslash(block *inline) bool {
+	p.pos++
+	if c := p.peek(); c == '\n' {
+		p.pos++
+		block.appendChild(&inline{kind: inlineHard})
+	} else if isASCIIPunct(c) {
+		block.appendChild(makeText(string(rune(c))))
+		p.pos++
+	} else {
+		block.appendChild(makeText("\\"))
+	}
+	return true
+}
+
+func (p *inlineParser) parseBackticks(block *inline) bool {
+	start := p.pos
+	for p.peek() == '`' {
+		p.pos++
+	}
+	ticks := p.pos - start
+	afterOpenTicks := p.pos
+	for p.pos < len(p.subject) {
+		if p.subject[p.pos] != '`' {
+			p.pos++
+			continue
+		}
+		runStart := p.pos
+		for p.peek() == '`' {
+			p.pos++
+		}
+		if p.pos-runStart == ticks {
+			contents := strings.ReplaceAll(p.subject[afterOpenTicks:runStart], "\n", " ")
+			if len(contents) > 1 && contents[0] == ' ' && contents[len(contents)-1] == ' ' &&
+				strings.Trim(contents, " ") != "" {
+				contents = contents[1 : len(contents)-1]
+			}
+			block.appendChild(&inline{kind: inlineCode, literal: contents})
+			return true
+		}
+	}
+	// No matching closing backtick sequence.
+	p.pos = afterOpenTicks
+	block.appendChild(makeText(p.subject[start:afterOpenTicks]))
+	return true
+}
+
+func isUnicodeWhitespace(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\f' || r == '\r' || unicode.Is(unicode.Zs, r)
+}
+
+func isUnicodePunct(r rune) bool {
+	return (r < utf8.RuneSelf && isASCIIPunct(int(r))) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// scanDelims determines the number of delimiter characters and whether they
+// can open or close emphasis.
+func (p *inlineParser) scanDelims(cc byte) (numdelims int, canOpen, canClose bool) {
+	start := p.pos
+	for p.peek() == int(cc) {
+		numdelims++
+		p.pos++
+	}
+	charBefore, charAfter := '\n', '\n'
+	if start > 0 {
+		charBefore, _ = utf8.DecodeLastRuneInString(p.subject[:start])
+	}
+	if p.pos < len(p.subject) {
+		charAfter, _ = utf8.DecodeRuneInString(p.subject[p.pos:])
+	}
+	p.pos = start
+
+	afterIsWhitespace, afterIsPunct := isUnicodeWhitespace(charAfter), isUnicodePunct(charAfter)
+	beforeIsWhitespace, beforeIsPunct := isUnicodeWhitespace(charBefore), isUnicodePunct(charBefore)
+	leftFlanking := !afterIsWhitespace && (!afterIsPunct || beforeIsWhitespace || beforeIsPunct)
+	rightFlanking := !beforeIsWhitespace && (!beforeIsPunct || afterIsWhitespace || afterIsPunct)
+	if cc == '_' {
+		canOpen = leftFlanking && (!rightFlanking || beforeIsPunct)
+		canClose = rightFlanking && (!leftFlanking || afterIsPunct)
+	} else {
+		canOpen, canClose = leftFlanking, rightFlanking
+	}
+	return numdelims, canOpen, canClose
+}
+
+func (p *inlineParser) handleDelim(cc byte, block *inline) bool {
+	numdelims, canOpen, canClose := p.scanDelims(cc)
+	start := p.pos
+	p.pos += numdelims
+	node := makeText(p.subject[start:p.pos])
+	block.appendChild(node)
+
+	// Strikethrough uses one or two tildes only.
+	if (canOpen || canClose) && (cc != '~' || numdelims <= 2) {
+		p.delimiters = &delimiter{
+			cc:         cc,
+			numdelims:  numdelims,
+			origdelims: numdelims,
+			node:       node,
+			previous:   p.delimiters,
+			canOpen:    canOpen,
+			canClose:   canClose,
+		}
+		if p.delimiters.previous != nil {
+			p.delimiters.previous.next = p.delimiters
+		}
+	}
+	return true
+}
+
+func (p *inlineParser) removeDelimiter(delim *delimiter) {
+	if delim.previous != nil {
+		delim.previous.next = delim.next
+	}
+	if delim.next == nil {
+		p.delimiters = delim.previous // top of stack
+	} else {
+		delim.next.previous = delim.previous
+	}
+}
+
+func removeDelimitersBetween(bottom, top *delimiter) {
+	if bottom.next != top {
+		bottom.next = top
+		top.previous = bottom
+	}
+}
+
+// openersBottomIndex returns the index into the list of lower bounds for
+// opener searches.
+func openersBottomIndex(closer *delimiter) int {
+	idx := closer.origdelims % 3
+	if closer.canOpen {
+		idx += 3
+	}
+	switch closer.cc {
+	case '_':
+		return idx
+	case '*':
+		return 6 + idx
+	}
+	return 12 + idx
+}
+
+// processEmphasis resolves the delimiter stack above stackBottom into
+// emphasis, strong emphasis, and strikethrough nodes.
+func (p *inlineParser) processEmphasis(stackBottom *delimiter) {
+	var openersBottom [18]*delimiter
+	for i := range openersBottom {
+		openersBottom[i] = stackBottom
+	}
+
+	// find first closer above stackBottom
+	closer := p.delimiters
+	for closer != nil && closer.previous != stackBottom {
+		closer = closer.previous
+	}
+
+	// move forward, looking for closers, and handling each
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		// found a closer, now look back for first matching opener
+		bottomIndex := openersBottomIndex(closer)
+		opener := closer.previous
+		openerFound := false
+		for opener != nil && opener != stackBottom && opener != openersBottom[bottomIndex] {
+			if opener.cc == closer.cc && opener.canOpen {
+				if closer.cc == '~' {
+					if opener.numdelims == closer.numdelims {
+						openerFound = true
+						break
+					}
+				} else {
+					oddMatch := (closer.canOpen || opener.canClose) && closer.origdelims%3 != 0 &&
+						(opener.origdelims+closer.origdelims)%3 == 0
+					if !oddMatch {
+						openerFound = true
+						break
+					}
+				}
+			}
+			opener = opener.previous
+		}
+
+		oldCloser := closer
+		if !openerFound {
+			closer = closer.next
+
+			// Set lower bound for future searches for openers
+			openersBottom[bottomIndex] = oldCloser.previous
+			if !oldCloser.canOpen {
+				// A closer that can't be an opener is not needed any more.
+				p.removeDelimiter(oldCloser)
+			}
+			continue
+		}
+
+		// calculate actual number of delimiters used from closer
+		useDelims := 1
+		if closer.cc == '~' {
+			useDelims = closer.numdelims
+		} else if closer.numdelims >= 2 && opener.numdelims >= 2 {
+			useDelims = 2
+		}
+
+		openerInl, closerInl := opener.node, closer.node
+		opener.numdelims -= useDelims
+		closer.numdelims -= useDelims
+		openerInl.literal = openerInl.literal[:len(openerInl.literal)-useDelims]
+		closerInl.literal = closerInl.literal[:len(closerInl.literal)-useDelims]
+
+		kind := inlineEmph
+		if closer.cc == '~' {
+			kind = inlineDelete
+		} else if useDelims == 2 {
+			kind = inlineStrong
+		}
+		emph := &inline{kind: kind}
+		for tmp := openerInl.next; tmp != nil && tmp != closerInl; {
+			next := tmp.next
+			emph.appendChild(tmp)
+			tmp = next
+		}
+		openerInl.insertAfter(emph)
+
+		removeDelimitersBetween(opener, closer)
+
+		if opener.numdelims == 0 {
+			openerInl.unlink()
+			p.removeDelimiter(opener)
+		}
+		if closer.numdelims == 0 {
+			closerInl.unlink()
+			next := closer.next
+			p.removeDelimiter(closer)
+			closer = next
+		}
+	}
+
+	// remove all delimiters
+	for p.delimiters != nil && p.delimiters != stackBottom {
+		p.removeDelimiter(p.delimiters)
+	}
+}
+
+func (p *inlineParser) addBracket(node *inline, index int, image bool) {
+	if p.brackets != nil {
+		p.brackets.bracketAfter = true
+	}
+	p.brackets = &bracket{
+		node:              node,
+		previous:          p.brackets,
+		previousDelimiter: p.delimiters,
+		index:             index,
+		image:             image,
+		active:            true,
+	}
+}
+
+func (p *inlineParser) removeBracket() { p.brackets = p.brackets.previous }
+
+func (p *inlineParser) parseOpenBracket(block *inline) bool {
+	start := p.pos
+	p.pos++
+	node := makeText("[")
+	block.appendChild(node)
+	p.addBracket(node, start, false)
+	return true
+}
+
+func (p *inlineParser) parseBang(block *inline) bool {
+	start := p.pos
+	p.pos++
+	if p.peek() == '[' {
+		p.pos++
+		node := makeText("![")
+		block.appendChild(node)
+		p.addBracket(node, start+1, true)
+	} else {
+		block.appendChild(makeText("!"))
+	}
+	return true
+}
+
+func (p *inlineParser) parseCloseBracket(block *inline) bool {
+	p.pos++
+	start := p.pos
+
+	// get last [ or ![
+	opener := p.brackets
+	if opener == nil {
+		block.appendChild(makeText("]"))
+		return true
+	}
+	if !opener.active {
+		block.appendChild(makeText("]"))
+		p.removeBracket()
+		return true
+	}
+
+	// Check to see if we have a link / image
+	var dest, title string
+	matched := false
+	savepos := p.pos
+
+	// Inline link?
+	if p.peek() == '(' {
+		p.pos++
+		if p.spnl() {
+			if d, ok := p.parseLinkDestination(); ok {
+				dest = d
+				if p.spnl() {
+					// make sure there's a space before the title
+					if isUnicodeWhitespace(rune(p.subject[p.pos-1])) {
+						if t, ok2 := p.parseLinkTitle(); ok2 {
+							title = t
+						}
+					}
+					if p.spnl() && p.peek() == ')' {
+						p.pos++
+						matched = true
+					}
+				}
+			}
+		}
+		if !matched {
+			p.pos = savepos
+		}
+	}
+
+	if !matched {
+		// Next, see if there's a link label
+		beforeLabel := p.pos
+		n := p.parseLinkLabel()
+		var reflabel string
+		if n > 2 {
+			reflabel = p.subject[beforeLabel : beforeLabel+n]
+		} else if !opener.bracketAfter {
+			// Empty or missing second label means to use the first label
+			// as the reference.
+			reflabel = p.subject[opener.index:start]
+		}
+		if n == 0 {
+			// If shortcut reference link, rewind before spaces we skipped.
+			p.pos = savepos
+		}
+		if reflabel != "" {
+			if ref, found := p.refmap[normalizeReference(reflabel)]; found {
+				dest, title = ref.dest, ref.title
+				matched = true
+			}
+		}
+	}
+
+	if !matched {
+		p.removeBracket()
+		p.pos = start
+		block.appendChild(makeText("]"))
+		return true
+	}
+
+	kind := inlineLink
+	if opener.image {
+		kind = inlineImage
+	}
+	node := &inline{kind: kind, dest: dest, title: title}
+	for tmp := opener.node.next; tmp != nil; {
+		next := tmp.next
+		node.appendChild(tmp)
+		tmp = next
+	}
+	block.appendChild(node)
+	p.processEmphasis(opener.previousDelimiter)
+	p.removeBracket()
+	opener.node.unlink()
+
+	// No links in links: deactivate earlier link openers.
+	if !opener.image {
+		for o := p.brackets; o != nil; o = o.previous {
+			if !o.image {
+				o.active = false
+			}
+		}
+	}
+	return true
+}
+
+// spnl skips spaces and tabs, with at most one newline.
+func (p *inlineParser) spnl() bool {
+	p.skipSpaceTab()
+	if p.peek() == '\n' {
+		p.pos++
+		p.skipSpaceTab()
+	}
+	return true
+}
+
+func (p *inlineParser) skipSpaceTab() {
+	for isSpaceOrTab(p.peek()) {
+		p.pos++
+	}
+}
+
+// parseLinkLabel returns the length of a link label, or 0 if there is none.
+func (p *inlineParser) parseLinkLabel() int {
+	s := p.subject
+	if p.peek() != '[' {
+		return 0
+	}
+	count := 0
+	for i := p.pos + 1; i < len(s) && count <= 999; count++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i < len(s) {
+				i++
+			}
+		case '[':
+			return 0
+		case ']':
+			n := i + 1 - p.pos
+			p.pos = i + 1
+			return n
+		default:
+			i++
+		}
+	}
+	return 0
+}
+
+func (p *inlineParser) parseLinkDestination() (string, bool) {
+	s := p.subject
+	if p.peek() == '<' {
+		for i := p.pos + 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 < len(s) {
+					i++
+				}
+			case '>':
+				dest := unescapeString(s[p.pos+1 : i])
+				p.pos = i + 1
+				return dest, true
+			case '<', '\n':
+				return "", false
+			}
+		}
+		return "", false
+	}
+
+	start := p.pos
+	openParens := 0
+	c := p.peek()
+loop:
+	for ; c != -1; c = p.peek() {
+		switch {
+		case c == '\\' && isASCIIPunct(peek(s, p.pos+1)):
+			p.pos += 2
+		case c == '(':
+			p.pos++
+			openParens++
+		case c == ')':
+			if openParens < 1 {
+				break loop
+			}
+			p.pos++
+			openParens--
+		case c <= ' ' || c == 0x7f:
+			break loop
+		default:
+			p.pos++
+		}
+	}
+	if (p.pos == start && c != ')') || openParens != 0 {
+		p.pos = start
+		return "", false
+	}
+	return unescapeString(s[start:p.pos]), true
+}
+
+func (p *inlineParser) parseLinkTitle() (string, bool) {
+	s := p.subject
+	var closeCh byte
+	switch p.peek() {
+	case '"':
+		closeCh = '"'
+	case '\'':
+		closeCh = '\''
+	case '(':
+		closeCh = ')'
+	default:
+		return "", false
+	}
+	for i := p.pos + 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && isASCIIPunct(peek(s, i+1)):
+			i++
+		case c == closeCh:
+			title := unescapeString(s[p.pos+1 : i])
+			p.pos = i + 1
+			return title, true
+		case closeCh == ')' && c == '(':
+			return "", false
+		}
+	}
+	return "", false
+}
+
+var (
+	reEmailAutolink = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	reAutolink      = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*)>`)
+	reHTMLTag       = regexp.MustCompile(`^` + htmlTag)
+	reEntity        = regexp.MustCompile(`^&(?:#[xX][a-fA-F0-9]{1,6}|#[0-9]{1,7}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+)
+
+func (p *inlineParser) parseAutolink(block *inline) bool {
+	rest := p.subject[p.pos:]
+	if m := reEmailAutolink.FindStringSubmatch(rest); m != nil {
+		p.pos += len(m[0])
+		node := &inline{kind: inlineLink, dest: "mailto:" + m[1]}
+		node.appendChild(makeText(m[1]))
+		block.appendChild(node)
+		return true
+	}
+	if m := reAutolink.FindStringSubmatch(rest); m != nil {
+		p.pos += len(m[0])
+		node := &inline{kind: inlineLink, dest: m[1]}
+		node.appendChild(makeText(m[1]))
+		block.appendChild(node)
+		return true
+	}
+	return false
+}
+
+func (p *inlineParser) parseHTMLTag(block *inline) bool {
+	m := reHTMLTag.FindString(p.subject[p.pos:])
+	if m == "" {
+		return false
+	}
+	p.pos += len(m)
+	block.appendChild(&inline{kind: inlineHTML, literal: m})
+	return true
+}
+
+func (p *inlineParser) parseEntity(block *inline) bool {
+	m := reEntity.FindString(p.subject[p.pos:])
+	if m == "" {
+		return false
+	}
+	s := html.UnescapeString(m)
+	if s == m {
+		return false
+	}
+	p.pos += len(m)
+	block.appendChild(makeText(s))
+	return true
+}
+
+// unescapeString replaces backslash escapes and entities.
+func unescapeString(s string) string {
+	if !strings.ContainsAny(s, "\\&") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if isASCIIPunct(peek(s, i+1)) {
+				i++
+				sb.WriteByte(s[i])
+			} else {
+				sb.WriteByte(c)
+			}
+		case '&':
+			if m := reEntity.FindString(s[i:]); m != "" {
+				if u := html.UnescapeString(m); u != m {
+					sb.WriteString(u)
+					i += len(m) - 1
+					continue
+				}
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+var reWhitespace = regexp.MustCompile(`[ \t\r\n]+`)
+
+// normalizeReference normalizes a link label, including its brackets, for
+// matching link references.
+func normalizeReference(label string) string {
+	label = strings.TrimSpace(label[1 : len(label)-1])
+	label = reWhitespace.ReplaceAllString(label, " ")
+	label = strings.ReplaceAll(strings.ToLower(label), "ß", "ss")
+	return strings.ToUpper(label)
+}
+
+// parseReference parses a link reference definition at the beginning of the
+// given string and stores it into refmap. It returns the number of bytes
+// consumed, or 0 if there was no definition.
+func parseReference(s string, refmap map[string]linkRef) int {
+	p := inlineParser{subject: s}
+	n := p.parseLinkLabel()
+	if n == 0 || p.peek() != ':' {
+		return 0
+	}
+	rawLabel := s[:n]
+	p.pos++
+
+	p.spnl()
+	dest, ok := p.parseLinkDestination()
+	if !ok {
+		return 0
+	}
+
+	beforeTitle := p.pos
+	p.spnl()
+	title, hasTitle := "", false
+	if p.pos != beforeTitle {
+		title, hasTitle = p.parseLinkTitle()
+	}
+	if !hasTitle {
+		p.pos = beforeTitle
+	}
+
+	// make sure we're at line end
+	if !p.atLineEnd() {
+		if !hasTitle {
+			return 0
+		}
+		// The potential title is not at the line end, but it could still be
+		// a legal link reference if the title is discarded.
+		title = ""
+		p.pos = beforeTitle
+		if !p.atLineEnd() {
+			return 0
+		}
+	}
+
+	normLabel := normalizeReference(rawLabel)
+	if normLabel == "" {
+		return 0
+	}
+	if _, found := refmap[normLabel]; !found {
+		refmap[normLabel] = linkRef{dest: dest, title: title}
+	}
+	return p.pos
+}
+
+// atLineEnd skips spaces and tabs and an optional newline. It returns true,
+// if the end of a line was found.
+func (p *inlineParser) atLineEnd() bool {
+	pos := p.pos
+	p.skipSpaceTab()
+	if c := p.peek(); c == '\n' {
+		p.pos++
+		return true
+	} else if c == -1 {
+		return true
+	}
+	p.pos = pos
+	return false
+}
@@ -0,0 +1,199 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package markdown provides a parser for CommonMark, extended by tables and
+// strikethrough of GitHub Flavored Markdown. The parser produces a BLOCK node.
+//
+// Block quotes are translated into a QUOTATION list with one item, fenced
+// code blocks into VERBATIM-CODE with the first word of the info string as
+// its syntax, HTML blocks into VERBATIM-HTML, and raw inline HTML into
+// LITERAL-CODE with syntax "html". Link destinations are classified into
+// external, hosted, and self references.
+//
+// The encoder writes zsx nodes as CommonMark, optionally using the extensions
+// of GitHub Flavored Markdown. Nodes without a Markdown equivalent are
+// written according to a fallback and reported to the caller.
+package markdown
+
+import (
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// ParseBlocks parses the remaining input as a Markdown document and returns
+// a BLOCK node.
+func ParseBlocks(inp *input.Input) *sx.Pair {
+	src := string(inp.Src[inp.Pos:])
+	inp.SetPos(len(inp.Src))
+	doc, refmap := parseDocument(src)
+	cv := converter{refmap: refmap}
+	return zsx.MakeBlockList(cv.blocks(doc.children))
+}
+
+// converter translates the block structure into zsx nodes.
+type converter struct {
+	refmap map[string]linkRef
+}
+
+func (cv *converter) blocks(bs []*block) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, b := range bs {
+		if node := cv.block(b); node != nil {
+			lb.Add(node)
+		}
+	}
+	return lb.List()
+}
+
+func (cv *converter) block(b *block) *sx.Pair {
+	switch b.kind {
+	case kindParagraph:
+		return zsx.MakeParaList(cv.inlines(strings.Join(b.lines, "\n")))
+	case kindHeading:
+		return zsx.MakeHeading(nil, b.level, cv.inlines(b.content))
+	case kindThematic:
+		return zsx.MakeThematic(nil)
+	case kindCode:
+		var attrs *sx.Pair
+		if b.isFenced {
+			if lang, _, _ := strings.Cut(b.info, " "); lang != "" {
+				attrs = zsx.Attributes{"": lang}.AsAssoc()
+			}
+		}
+		return zsx.MakeVerbatim(zsx.SymVerbatimCode, attrs, b.literal)
+	case kindHTML:
+		return zsx.MakeVerbatim(zsx.SymVerbatimHTML, nil, b.literal)
+	case kindBlockQuote:
+		return zsx.MakeList(zsx.SymListQuote, nil, sx.MakeList(cv.item(b.children)))
+	case kindList:
+		return cv.list(b)
+	case kindTable:
+		return cv.table(b)
+	}
+	return nil
+}
+
+func (cv *converter) item(bs []*block) *sx.Pair {
+	return zsx.MakeListItem(nil, cv.blocks(bs))
+}
+
+func (cv *converter) list(b *block) *sx.Pair {
+	sym := zsx.SymListUnordered
+	var attrs *sx.Pair
+	if b.list.ordered {
+		sym = zsx.SymListOrdered
+		if start := b.list.start; start != 1 {
+			attrs = zsx.Attributes{"start": strconv.Itoa(start)}.AsAssoc()
+		}
+	}
+	var lb sx.ListBuilder
+	for _, item := range b.children {
+		lb.Add(cv.item(item.children))
+	}
+	return zsx.MakeList(sym, attrs, lb.List())
+}
+
+func (cv *converter) table(b *block) *sx.Pair {
+	header := cv.row(b.header, b.aligns)
+	var lb sx.ListBuilder
+	for _, line := range b.lines {
+		lb.Add(cv.row(splitTableRow(line), b.aligns))
+	}
+	return zsx.MakeTable(nil, header, lb.List())
+}
+
+// row builds a row with exactly one cell per column.
+func (cv *converter) row(cells []string, aligns []sx.Object) *sx.Pair {
+	var lb sx.ListBuilder
+	for i, align := range aligns {
+		var attrs *sx.Pair
+		if align != nil {
+			attrs = sx.MakeList(sx.Cons(zsx.SymAttrAlign, align))
+		}
+		var ins *sx.Pair
+		if i < len(cells) {
+			ins = cv.inlines(cells[i])
+		}
+		lb.Add(zsx.MakeCell(attrs, ins))
+	}
+	return zsx.MakeRow(nil, lb.List())
+}
+
+func (cv *converter) inlines(content string) *sx.Pair {
+	var lb sx.ListBuilder
+	cv.addInlines(&lb, parseInlines(content, cv.refmap))
+	return lb.List()
+}
+
+// addInlines adds the children of the given inline node. Adjacent text
+// nodes are merged.
+func (cv *converter) addInlines(lb *sx.ListBuilder, parent *inline) {
+	for n := parent.first; n != nil; n = n.next {
+		if n.kind == inlineText {
+			var sb strings.Builder
+			for ; n.next != nil && n.next.kind == inlineText; n = n.next {
+				sb.WriteString(n.literal)
+			}
+			sb.WriteString(n.literal)
+			if s := sb.String(); s != "" {
+				lb.Add(zsx.MakeText(s))
+			}
+			continue
+		}
+		if node := cv.inline(n); node != nil {
+			lb.Add(node)
+		}
+	}
+}
+
+func (cv *converter) inline(n *inline) *sx.Pair {
+	switch n.kind {
+	case inlineSoft:
+		return zsx.MakeSoft()
+	case inlineHard:
+		return zsx.MakeHard()
+	case inlineCode:
+		return zsx.MakeLiteral(zsx.SymLiteralCode, nil, n.literal)
+	case inlineHTML:
+		return zsx.MakeLiteral(zsx.SymLiteralCode,
+			zsx.Attributes{"": "html"}.AsAssoc(), n.literal)
+	case inlineEmph:
+		return zsx.MakeFormat(zsx.SymFormatEmph, nil, cv.children(n))
+	case inlineStrong:
+		return zsx.MakeFormat(zsx.SymFormatStrong, nil, cv.children(n))
+	case inlineDelete:
+		return zsx.MakeFormat(zsx.SymFormatDelete, nil, cv.children(n))
+	case inlineLink:
+		return zsx.MakeLink(titleAttrs(n.title), zsx.ParseReference(n.dest), cv.children(n))
+	case inlineImage:
+		return zsx.MakeEmbed(titleAttrs(n.title), zsx.ParseReference(n.dest), "", cv.children(n))
+	}
+	return nil
+}
+
+func (cv *converter) children(n *inline) *sx.Pair {
+	var lb sx.ListBuilder
+	cv.addInlines(&lb, n)
+	return lb.List()
+}
+
+func titleAttrs(title string) *sx.Pair {
+	if title == "" {
+		return nil
+	}
+	return zsx.Attributes{"title": title}.AsAssoc()
+}
@@ -0,0 +1,230 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package markdown_test
+
+import (
+	"testing"
+
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/markdown"
+)
+
+type testCase struct {
+	name string
+	src  string
+	exp  string
+}
+
+func checkBlocks(t *testing.T, testcases []testCase) {
+	t.Helper()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := markdown.ParseBlocks(input.NewInput([]byte(tc.src))).String()
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %s\ngot: %s", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestLeafBlocks(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"empty", "", "(BLOCK)"},
+		{"para", "a  \nb\nc", `(BLOCK (PARA (TEXT "a") (HARD) (TEXT "b") (SOFT) (TEXT "c")))`},
+		{"atx", "## A *b* ##", `(BLOCK (HEADING () 2 (TEXT "A ") (FORMAT-EMPH () (TEXT "b"))))`},
+		{"setext", "A\nb\n---", `(BLOCK (HEADING () 2 (TEXT "A") (SOFT) (TEXT "b")))`},
+		{"thematic", "* * *", `(BLOCK (THEMATIC ()))`},
+		{"fenced", "~~~ go extra\ncode\n\n~~~", `(BLOCK (VERBATIM-CODE (("" . "go")) "code\n"))`},
+		{"indented", "    a\n\n    b\n", `(BLOCK (VERBATIM-CODE () "a\n\nb"))`},
+		{"no-interrupt", "a\n    b", `(BLOCK (PARA (TEXT "a") (SOFT) (TEXT "b")))`},
+		{"html", "<div>\n*a*\n\nb", `(BLOCK (VERBATIM-HTML () "<div>\n*a*") (PARA (TEXT "b")))`},
+		{"html-comment", "<!-- a\n\nb -->", `(BLOCK (VERBATIM-HTML () "<!-- a\n\nb -->"))`},
+		{"refdef-only", "[a]: /url", "(BLOCK)"},
+	})
+}
+
+func TestContainerBlocks(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"quote", "> a\nb\n> - c",
+			`(BLOCK (QUOTATION () (ITEM () (PARA (TEXT "a") (SOFT) (TEXT "b")) (UNORDERED () (ITEM () (PARA (TEXT "c")))))))`},
+		{"bullet", "- a\n- b\n+ c",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a"))) (ITEM () (PARA (TEXT "b")))) (UNORDERED () (ITEM () (PARA (TEXT "c")))))`},
+		{"ordered", "3. a\n4. b",
+			`(BLOCK (ORDERED (("start" . "3")) (ITEM () (PARA (TEXT "a"))) (ITEM () (PARA (TEXT "b")))))`},
+		{"nested", "- a\n  - b\n\n  c",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a")) (UNORDERED () (ITEM () (PARA (TEXT "b")))) (PARA (TEXT "c")))))`},
+		{"empty-item", "- a\n-\n- c",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a"))) (ITEM ()) (ITEM () (PARA (TEXT "c")))))`},
+		{"tab", "-\tfoo\n\n\tbar", `(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "foo")) (PARA (TEXT "bar")))))`},
+		{"no-interrupt", "a\n2. b", `(BLOCK (PARA (TEXT "a") (SOFT) (TEXT "2. b")))`},
+	})
+}
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"simple", "| a | b |\n| - | - |\n| c | d |",
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b"))) (ROW () (CELL () (TEXT "c")) (CELL () (TEXT "d")))))`},
+		{"align", "a | b | c\n:- | :-: | -:",
+			`(BLOCK (TABLE () (ROW () (CELL ((align . "left")) (TEXT "a")) (CELL ((align . "center")) (TEXT "b")) (CELL ((align . "right")) (TEXT "c")))))`},
+		{"pad-cut", "|a|b|\n|-|-|\n|c|\n|d|e|f|",
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b"))) (ROW () (CELL () (TEXT "c")) (CELL ())) (ROW () (CELL () (TEXT "d")) (CELL () (TEXT "e")))))`},
+		{"escaped-pipe", "|a|\n|-|\n|`b\\|c`|",
+			"(BLOCK (TABLE () (ROW () (CELL () (TEXT \"a\"))) (ROW () (CELL () (LITERAL-CODE () \"b|c\")))))"},
+		{"after-para", "x\n|a|\n|-|\n\ny",
+			`(BLOCK (PARA (TEXT "x")) (TABLE () (ROW () (CELL () (TEXT "a")))) (PARA (TEXT "y")))`},
+		{"mismatch", "|a|b|\n|-|", `(BLOCK (PARA (TEXT "|a|b|") (SOFT) (TEXT "|-|")))`},
+		{"setext", "a|b\n---", `(BLOCK (HEADING () 2 (TEXT "a|b")))`},
+	})
+}
+
+func TestEmphasis(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"emph", "*a* _b_", `(BLOCK (PARA (FORMAT-EMPH () (TEXT "a")) (TEXT " ") (FORMAT-EMPH () (TEXT "b"))))`},
+		{"strong", "**a**", `(BLOCK (PARA (FORMAT-STRONG () (TEXT "a"))))`},
+		{"nested", "*a **b** c*",
+			`(BLOCK (PARA (FORMAT-EMPH () (TEXT "a ") (FORMAT-STRONG () (TEXT "b")) (TEXT " c"))))`},
+		{"rule-of-3", "*foo**bar**baz*",
+			`(BLOCK (PARA (FORMAT-EMPH () (TEXT "foo") (FORMAT-STRONG () (TEXT "bar")) (TEXT "baz"))))`},
+		{"intraword", "_a_b_ a*b*", `(BLOCK (PARA (FORMAT-EMPH () (TEXT "a_b")) (TEXT " a") (FORMAT-EMPH () (TEXT "b"))))`},
+		{"not-flanking", "a * b *", `(BLOCK (PARA (TEXT "a * b *")))`},
+		{"strikethrough", "~a~ ~~b~~ ~~~c~~~",
+			`(BLOCK (PARA (FORMAT-DELETE () (TEXT "a")) (TEXT " ") (FORMAT-DELETE () (TEXT "b")) (TEXT " ~~~c~~~")))`},
+		{"strike-mismatch", "~~a~", `(BLOCK (PARA (TEXT "~~a~")))`},
+	})
+}
+
+func TestInlines(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"code", "``a ` b`` ` c `", "(BLOCK (PARA (LITERAL-CODE () \"a ` b\") (TEXT \" \") (LITERAL-CODE () \"c\")))"},
+		{"code-unclosed", "``a`", "(BLOCK (PARA (TEXT \"``a`\")))"},
+		{"escape", `\*a\* \q`, `(BLOCK (PARA (TEXT "*a* \\q")))`},
+		{"entity", "&amp; &#65; &nope;", `(BLOCK (PARA (TEXT "& A &nope;")))`},
+		{"html", `a <b class="x">c</b>`,
+			`(BLOCK (PARA (TEXT "a ") (LITERAL-CODE (("" . "html")) "<b class=\"x\">") (TEXT "c") (LITERAL-CODE (("" . "html")) "</b>")))`},
+		{"hard-backslash", "a\\\nb", `(BLOCK (PARA (TEXT "a") (HARD) (TEXT "b")))`},
+	})
+}
+
+func TestLink(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"external", `[a](https://t73f.de "T")`,
+			`(BLOCK (PARA (LINK (("title" . "T")) (EXTERNAL "https://t73f.de") (TEXT "a"))))`},
+		{"hosted", "[a](./b)", `(BLOCK (PARA (LINK () (HOSTED "./b") (TEXT "a"))))`},
+		{"self", "[a](#b)", `(BLOCK (PARA (LINK () (SELF "#b") (TEXT "a"))))`},
+		{"pointy", "[a](<b c>)", `(BLOCK (PARA (LINK () (HOSTED "b c") (TEXT "a"))))`},
+		{"parens", "[a](b(c))", `(BLOCK (PARA (LINK () (HOSTED "b(c)") (TEXT "a"))))`},
+		{"reference", "[a][B] [b] [b][]\n\n[b]: /u",
+			`(BLOCK (PARA (LINK () (HOSTED "/u") (TEXT "a")) (TEXT " ") (LINK () (HOSTED "/u") (TEXT "b")) (TEXT " ") (LINK () (HOSTED "/u") (TEXT "b"))))`},
+		{"undefined", "[a][b]", `(BLOCK (PARA (TEXT "[a][b]")))`},
+		{"no-nesting", "[a [b](c)](d)", `(BLOCK (PARA (TEXT "[a ") (LINK () (HOSTED "c") (TEXT "b")) (TEXT "](d)")))`},
+		{"image", `![a *b*](c.png "t")`,
+			`(BLOCK (PARA (EMBED (("title" . "t")) (HOSTED "c.png") "" (TEXT "a ") (FORMAT-EMPH () (TEXT "b")))))`},
+		{"autolink", "<https://t73f.de> <a@b.de>",
+			`(BLOCK (PARA (LINK () (EXTERNAL "https://t73f.de") (TEXT "https://t73f.de")) (TEXT " ") (LINK () (EXTERNAL "mailto:a@b.de") (TEXT "a@b.de"))))`},
+	})
+}
+
+// TestSpec checks some examples of the CommonMark specification, version
+// 0.31.2. The name of a test case is the number of the example.
+func TestSpec(t *testing.T) {
+	t.Parallel()
+	const (
+		html = `(("" . "html"))`
+		para = `(PARA (TEXT "[foo]"))`
+	)
+	checkBlocks(t, []testCase{
+		// Lazy continuation lines
+		{"233", "> # Foo\n> bar\nbaz", `(BLOCK (QUOTATION () (ITEM () (HEADING () 1 (TEXT "Foo")) (PARA (TEXT "bar") (SOFT) (TEXT "baz")))))`},
+		{"234", "> bar\nbaz\n> foo", `(BLOCK (QUOTATION () (ITEM () (PARA (TEXT "bar") (SOFT) (TEXT "baz") (SOFT) (TEXT "foo")))))`},
+		{"235", "> foo\n---", `(BLOCK (QUOTATION () (ITEM () (PARA (TEXT "foo")))) (THEMATIC ()))`},
+		{"236", "> - foo\n- bar",
+			`(BLOCK (QUOTATION () (ITEM () (UNORDERED () (ITEM () (PARA (TEXT "foo")))))) (UNORDERED () (ITEM () (PARA (TEXT "bar")))))`},
+		{"237", ">     foo\n    bar", `(BLOCK (QUOTATION () (ITEM () (VERBATIM-CODE () "foo"))) (VERBATIM-CODE () "bar"))`},
+		{"238", "> ```\nfoo\n```", `(BLOCK (QUOTATION () (ITEM () (VERBATIM-CODE () ""))) (PARA (TEXT "foo")) (VERBATIM-CODE () ""))`},
+		{"239", "> foo\n    - bar", `(BLOCK (QUOTATION () (ITEM () (PARA (TEXT "foo") (SOFT) (TEXT "- bar")))))`},
+		{"290", "1.  A paragraph\nwith two lines.",
+			`(BLOCK (ORDERED () (ITEM () (PARA (TEXT "A paragraph") (SOFT) (TEXT "with two lines.")))))`},
+		{"292", "> 1. > Blockquote\ncontinued here.",
+			`(BLOCK (QUOTATION () (ITEM () (ORDERED () (ITEM () (QUOTATION () (ITEM () (PARA (TEXT "Blockquote") (SOFT) (TEXT "continued here.")))))))))`},
+
+		// Link reference definitions
+		{"192", "[foo]: /url \"title\"\n\n[foo]", `(BLOCK (PARA (LINK (("title" . "title")) (HOSTED "/url") (TEXT "foo"))))`},
+		{"193", "   [foo]: \n      /url  \n           'the title'  \n\n[foo]",
+			`(BLOCK (PARA (LINK (("title" . "the title")) (HOSTED "/url") (TEXT "foo"))))`},
+		{"196", "[foo]: /url '\ntitle\nline1\nline2\n'\n\n[foo]",
+			`(BLOCK (PARA (LINK (("title" . "\ntitle\nline1\nline2\n")) (HOSTED "/url") (TEXT "foo"))))`},
+		{"197", "[foo]: /url 'title\n\nwith blank line'\n\n[foo]",
+			`(BLOCK (PARA (TEXT "[foo]: /url 'title")) (PARA (TEXT "with blank line'")) ` + para + `)`},
+		{"198", "[foo]:\n/url\n\n[foo]", `(BLOCK (PARA (LINK () (HOSTED "/url") (TEXT "foo"))))`},
+		{"199", "[foo]:\n\n[foo]", `(BLOCK (PARA (TEXT "[foo]:")) ` + para + `)`},
+		{"204", "[foo]\n\n[foo]: url", `(BLOCK (PARA (LINK () (HOSTED "url") (TEXT "foo"))))`},
+		{"205", "[foo]\n\n[foo]: first\n[foo]: second", `(BLOCK (PARA (LINK () (HOSTED "first") (TEXT "foo"))))`},
+		{"206", "[FOO]: /url\n\n[Foo]", `(BLOCK (PARA (LINK () (HOSTED "/url") (TEXT "Foo"))))`},
+		{"209", "[foo]: /url \"title\" ok", `(BLOCK (PARA (TEXT "[foo]: /url \"title\" ok")))`},
+		{"211", "    [foo]: /url \"title\"\n\n[foo]", `(BLOCK (VERBATIM-CODE () "[foo]: /url \"title\"") ` + para + `)`},
+		{"213", "Foo\n[bar]: /baz\n\n[bar]", `(BLOCK (PARA (TEXT "Foo") (SOFT) (TEXT "[bar]: /baz")) (PARA (TEXT "[bar]")))`},
+		{"214", "# [Foo]\n[foo]: /url\n> bar",
+			`(BLOCK (HEADING () 1 (LINK () (HOSTED "/url") (TEXT "Foo"))) (QUOTATION () (ITEM () (PARA (TEXT "bar")))))`},
+		{"218", "[foo]: /foo-url \"foo\"\n[bar]: /bar-url\n  \"bar\"\n[baz]: /baz-url\n\n[foo],\n[bar],\n[baz]",
+			`(BLOCK (PARA (LINK (("title" . "foo")) (HOSTED "/foo-url") (TEXT "foo")) (TEXT ",") (SOFT) ` +
+				`(LINK (("title" . "bar")) (HOSTED "/bar-url") (TEXT "bar")) (TEXT ",") (SOFT) (LINK () (HOSTED "/baz-url") (TEXT "baz"))))`},
+
+		// Emphasis and strong emphasis
+		{"351", "a * foo bar*", `(BLOCK (PARA (TEXT "a * foo bar*")))`},
+		{"352", "a*\"foo\"*", `(BLOCK (PARA (TEXT "a*\"foo\"*")))`},
+		{"355", "foo*bar*", `(BLOCK (PARA (TEXT "foo") (FORMAT-EMPH () (TEXT "bar"))))`},
+		{"359", "foo_bar_", `(BLOCK (PARA (TEXT "foo_bar_")))`},
+		{"360", "5_6_78", `(BLOCK (PARA (TEXT "5_6_78")))`},
+		{"363", "foo-_(bar)_", `(BLOCK (PARA (TEXT "foo-") (FORMAT-EMPH () (TEXT "(bar)"))))`},
+		{"366", "*foo bar *", `(BLOCK (PARA (TEXT "*foo bar *")))`},
+		{"372", "*(*foo*)*", `(BLOCK (PARA (FORMAT-EMPH () (TEXT "(") (FORMAT-EMPH () (TEXT "foo")) (TEXT ")"))))`},
+		{"411", "*foo**bar*", `(BLOCK (PARA (FORMAT-EMPH () (TEXT "foo**bar"))))`},
+		{"413", "*foo**bar***", `(BLOCK (PARA (FORMAT-EMPH () (TEXT "foo") (FORMAT-STRONG () (TEXT "bar")))))`},
+		{"415", "foo***bar***baz",
+			`(BLOCK (PARA (TEXT "foo") (FORMAT-EMPH () (FORMAT-STRONG () (TEXT "bar"))) (TEXT "baz")))`},
+		{"416", "foo******bar*********baz",
+			`(BLOCK (PARA (TEXT "foo") (FORMAT-STRONG () (FORMAT-STRONG () (FORMAT-STRONG () (TEXT "bar")))) (TEXT "***baz")))`},
+		{"445", "**foo*", `(BLOCK (PARA (TEXT "*") (FORMAT-EMPH () (TEXT "foo"))))`},
+		{"446", "*foo**", `(BLOCK (PARA (FORMAT-EMPH () (TEXT "foo")) (TEXT "*")))`},
+		{"448", "***foo**", `(BLOCK (PARA (TEXT "*") (FORMAT-STRONG () (TEXT "foo"))))`},
+		{"468", "*[bar*](/url)", `(BLOCK (PARA (TEXT "*") (LINK () (HOSTED "/url") (TEXT "bar*"))))`},
+		{"476", "*a `*`*", "(BLOCK (PARA (FORMAT-EMPH () (TEXT \"a \") (LITERAL-CODE () \"*\"))))"},
+
+		// HTML blocks
+		{"148", "<table><tr><td>\n<pre>\n**Hello**,\n\n_world_.\n</pre>\n</td></tr></table>",
+			`(BLOCK (VERBATIM-HTML () "<table><tr><td>\n<pre>\n**Hello**,") ` +
+				`(PARA (FORMAT-EMPH () (TEXT "world")) (TEXT ".") (SOFT) (LITERAL-CODE ` + html + ` "</pre>")) ` +
+				`(VERBATIM-HTML () "</td></tr></table>"))`},
+		{"161", "<div *???-&&&-<---\n*foo*", `(BLOCK (VERBATIM-HTML () "<div *???-&&&-<---\n*foo*"))`},
+		{"164", "<a href=\"foo\">\n*bar*\n</a>", `(BLOCK (VERBATIM-HTML () "<a href=\"foo\">\n*bar*\n</a>"))`},
+		{"168", "<del>\n\n*foo*\n\n</del>",
+			`(BLOCK (VERBATIM-HTML () "<del>") (PARA (FORMAT-EMPH () (TEXT "foo"))) (VERBATIM-HTML () "</del>"))`},
+		{"169", "<del>*foo*</del>",
+			`(BLOCK (PARA (LITERAL-CODE ` + html + ` "<del>") (FORMAT-EMPH () (TEXT "foo")) (LITERAL-CODE ` + html + ` "</del>")))`},
+		{"170", "<pre language=\"haskell\"><code>\nimport Text.HTML.TagSoup\n\nmain :: IO ()\n</code></pre>\nokay",
+			`(BLOCK (VERBATIM-HTML () "<pre language=\"haskell\"><code>\nimport Text.HTML.TagSoup\n\nmain :: IO ()\n</code></pre>") (PARA (TEXT "okay")))`},
+		{"175", "- <div>\n- foo", `(BLOCK (UNORDERED () (ITEM () (VERBATIM-HTML () "<div>")) (ITEM () (PARA (TEXT "foo")))))`},
+		{"178", "<!-- Foo\n\nbar\n   baz -->\nokay", `(BLOCK (VERBATIM-HTML () "<!-- Foo\n\nbar\n   baz -->") (PARA (TEXT "okay")))`},
+		{"180", "<?php\n\n  echo '>';\n\n?>\nokay", `(BLOCK (VERBATIM-HTML () "<?php\n\n  echo '>';\n\n?>") (PARA (TEXT "okay")))`},
+		{"185", "Foo\n<div>\nbar\n</div>", `(BLOCK (PARA (TEXT "Foo")) (VERBATIM-HTML () "<div>\nbar\n</div>"))`},
+		{"187", "Foo\n<a href=\"bar\">\nbaz",
+			`(BLOCK (PARA (TEXT "Foo") (SOFT) (LITERAL-CODE ` + html + ` "<a href=\"bar\">") (SOFT) (TEXT "baz")))`},
+	})
+}
@@ -0,0 +1,172 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx
+
+import (
+	"slices"
+	"strings"
+
+	"t73f.de/r/sx"
+)
+
+// Normalize returns the canonical form of the given node, so that trees can
+// be compared, hashed, and cached reliably. The given node is not modified.
+//
+// In the canonical form,
+//
+//   - attribute lists are assoc lists with sorted keys, and an empty
+//     attribute list is nil,
+//   - adjacent TEXT nodes are merged into one TEXT node, and empty TEXT
+//     nodes are removed,
+//   - FORMAT-* nodes without inline content are removed,
+//   - SOFT nodes at the beginning and at the end of a paragraph are removed,
+//   - splice nodes are resolved.
+//
+// If the node itself is removed, nil is returned.
+func Normalize(node *sx.Pair) *sx.Pair {
+	result, _ := sx.GetPair(Walk(normalizer{}, node, nil))
+	return result
+}
+
+type normalizer struct{}
+
+func (v normalizer) VisitBefore(node *sx.Pair, alst *sx.Pair) (sx.Object, bool) {
+	if SymSpecialSplice.IsEqualSymbol(NodeSymbol(node)) {
+		return v.normalizeSplice(node, alst), true
+	}
+	return sx.Nil(), false
+}
+
+func (normalizer) VisitAfter(node *sx.Pair, _ *sx.Pair) sx.Object {
+	sym := NodeSymbol(node)
+	if sym == nil {
+		return node
+	}
+	layout, found := GetLayout(sym)
+	if !found || !conformsLayout(node, layout) {
+		return node
+	}
+
+	var lb sx.ListBuilder
+	lb.Add(sym)
+	elems := node.Tail()
+	for _, elem := range layout.Elements {
+		switch elem.Kind {
+		case ElementAttrs:
+			attrs, _ := sx.GetPair(elems.Car())
+			lb.Add(GetAttributes(attrs).AsAssoc())
+		case ElementList:
+			lst, _ := sx.GetPair(elems.Car())
+			if elem.Context == ContextInline {
+				lst = normalizeInlines(lst)
+			}
+			lb.Add(lst)
+		case ElementRest:
+			if elem.Context == ContextInline {
+				elems = normalizeInlines(elems)
+			}
+			if SymPara.IsEqualSymbol(sym) {
+				elems = trimSoft(elems)
+			} else if elems == nil && isFormat(sym) {
+				return sx.Nil()
+			}
+			for obj := range elems.Values() {
+				lb.Add(obj)
+			}
+			return lb.List()
+		default:
+			lb.Add(elems.Car())
+		}
+		elems = elems.Tail()
+	}
+	return lb.List()
+}
+
+// conformsLayout returns true, if the node has the elements of the layout.
+func conformsLayout(node *sx.Pair, layout Layout) bool {
+	length, numElems := node.Length()-1, len(layout.Elements)
+	if numElems > 0 && layout.Elements[numElems-1].Kind == ElementRest {
+		return length >= numElems-1
+	}
+	return length == numElems
+}
+
+// normalizeSplice normalizes the elements of a splice node. They are
+// flattened into the list of the parent node, which is normalized afterwards.
+// Only the root node may remain a splice node.
+func (v normalizer) normalizeSplice(node *sx.Pair, alst *sx.Pair) sx.Object {
+	elems := walkChildrenList(v, node.Tail(), alst)
+	if elems == nil {
+		return sx.Nil()
+	}
+	return elems.Cons(node.Car())
+}
+
+// normalizeInlines merges adjacent TEXT nodes, and removes empty TEXT nodes.
+func normalizeInlines(inlines *sx.Pair) *sx.Pair {
+	var lb sx.ListBuilder
+	var sb strings.Builder
+	flushText := func() {
+		if sb.Len() > 0 {
+			lb.Add(MakeText(sb.String()))
+			sb.Reset()
+		}
+	}
+	for obj := range inlines.Values() {
+		if node, isPair := sx.GetPair(obj); isPair && SymText.IsEqualSymbol(NodeSymbol(node)) {
+			sb.WriteString(GetText(node))
+			continue
+		}
+		flushText()
+		lb.Add(obj)
+	}
+	flushText()
+	return lb.List()
+}
+
+// trimSoft removes SOFT nodes at the beginning and at the end of the list.
+func trimSoft(inlines *sx.Pair) *sx.Pair {
+	for inlines != nil && isSoft(inlines.Car()) {
+		inlines = inlines.Tail()
+	}
+	var lb sx.ListBuilder
+	var softs []sx.Object
+	for obj := range inlines.Values() {
+		if isSoft(obj) {
+			softs = append(softs, obj)
+			continue
+		}
+		for _, soft := range softs {
+			lb.Add(soft)
+		}
+		softs = softs[:0]
+		lb.Add(obj)
+	}
+	return lb.List()
+}
+
+func isSoft(obj sx.Object) bool {
+	node, isPair := sx.GetPair(obj)
+	return isPair && SymSoft.IsEqualSymbol(NodeSymbol(node))
+}
+
+// formatSymbols contains the symbols of all FORMAT-* nodes.
+var formatSymbols = []*sx.Symbol{
+	SymFormatDelete, SymFormatEmph, SymFormatInsert, SymFormatMark, SymFormatQuote,
+	SymFormatSpan, SymFormatStrong, SymFormatSub, SymFormatSuper,
+}
+
+func isFormat(sym *sx.Symbol) bool {
+	return slices.ContainsFunc(formatSymbols, sym.IsEqualSymbol)
+}
@@ -0,0 +1,85 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx_test
+
+import (
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	text := zsx.MakeText
+	attrs := func(kv ...string) *sx.Pair {
+		var lb sx.ListBuilder
+		for i := 0; i < len(kv); i += 2 {
+			lb.Add(sx.MakeList(sx.MakeString(kv[i]), sx.MakeString(kv[i+1])))
+		}
+		return lb.List()
+	}
+	testcases := []struct {
+		name string
+		node *sx.Pair
+		exp  string
+	}{
+		{"text", zsx.MakePara(text("a"), text(""), text("b"), zsx.MakeSoft(), text("c"), text("d")),
+			`(PARA (TEXT "ab") (SOFT) (TEXT "cd"))`},
+		{"empty-text", zsx.MakePara(text("")), `(PARA)`},
+		{"soft", zsx.MakePara(zsx.MakeSoft(), text("a"), zsx.MakeSoft(), zsx.MakeSoft(), text("b"), zsx.MakeSoft(), zsx.MakeSoft()),
+			`(PARA (TEXT "a") (SOFT) (SOFT) (TEXT "b"))`},
+		{"soft-only", zsx.MakePara(zsx.MakeSoft()), `(PARA)`},
+		{"soft-inline", zsx.MakeInline(zsx.MakeSoft(), text("a"), zsx.MakeSoft()),
+			`(INLINE (SOFT) (TEXT "a") (SOFT))`},
+		{"format", zsx.MakePara(text("a"), zsx.MakeFormat(zsx.SymFormatEmph, attrs("class", "x"), nil), text("b")),
+			`(PARA (TEXT "ab"))`},
+		{"format-nested", zsx.MakePara(zsx.MakeFormat(zsx.SymFormatStrong, nil,
+			sx.MakeList(zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text(""))), text("")))),
+			`(PARA)`},
+		{"format-root", zsx.MakeFormat(zsx.SymFormatSub, nil, nil), `()`},
+		{"attrs", zsx.MakeHeading(attrs("b", "2", "a", "1", "c", "3"), 1, sx.MakeList(text("a"))),
+			`(HEADING (("a" . "1") ("b" . "2") ("c" . "3")) 1 (TEXT "a"))`},
+		{"attrs-empty", zsx.MakeThematic(sx.MakeList(sx.MakeString("invalid"))), `(THEMATIC ())`},
+		{"region", zsx.MakeRegion(zsx.SymRegionQuote, nil,
+			sx.MakeList(zsx.MakePara(text("a"), text("b"))), sx.MakeList(text("c"), text("d"))),
+			`(REGION-QUOTE () ((PARA (TEXT "ab"))) (TEXT "cd"))`},
+		{"splice", zsx.MakePara(text("a"),
+			sx.MakeList(zsx.SymSpecialSplice, text("b"), zsx.MakeFormat(zsx.SymFormatEmph, nil, nil)),
+			sx.MakeList(zsx.SymSpecialSplice),
+			text("c")),
+			`(PARA (TEXT "abc"))`},
+		{"table", zsx.MakeTable(nil,
+			zsx.MakeRow(attrs("x", "y"), sx.MakeList(zsx.MakeCell(nil, sx.MakeList(text("a"), text("b"))))),
+			sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(nil, sx.MakeList(zsx.MakeFormat(zsx.SymFormatInsert, nil, nil))))))),
+			`(TABLE () (ROW (("x" . "y")) (CELL () (TEXT "ab"))) (ROW () (CELL ())))`},
+		{"canonical", zsx.MakeBlock(zsx.MakePara(text("a"))), `(BLOCK (PARA (TEXT "a")))`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			orig := tc.node.String()
+			got := zsx.Normalize(tc.node)
+			if s := got.String(); s != tc.exp {
+				t.Errorf("expected:\n%s\nbut got:\n%s", tc.exp, s)
+			}
+			if again := zsx.Normalize(got); !again.IsEqual(got) {
+				t.Errorf("not idempotent: %v", again)
+			}
+			if s := tc.node.String(); s != orig {
+				t.Errorf("node was modified: %v, but was %v", s, orig)
+			}
+		})
+	}
+}
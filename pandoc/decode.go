@@ -0,0 +1,625 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package pandoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Decode reads a Pandoc JSON document from the reader and returns its
+// blocks as a BLOCK node. The metadata of the document is ignored.
+//
+// The tags of all Pandoc elements that could not be converted losslessly are
+// returned, in the order they were encountered. An error is returned, if the
+// document is not valid JSON, or does not conform to the Pandoc API.
+func Decode(r io.Reader) (*sx.Pair, []string, error) {
+	jd := json.NewDecoder(r)
+	jd.UseNumber()
+	var doc struct {
+		Version []int `json:"pandoc-api-version"`
+		Blocks  []any `json:"blocks"`
+	}
+	if err := jd.Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Version) < 2 || doc.Version[0] != minMajorVersion || doc.Version[1] < minMinorVersion {
+		return nil, nil, fmt.Errorf("unsupported Pandoc API version: %v", doc.Version)
+	}
+	var dec decoder
+	blocks := dec.blocks(doc.Blocks)
+	if dec.err != nil {
+		return nil, nil, dec.err
+	}
+	return zsx.MakeBlockList(blocks), dec.lossy, nil
+}
+
+type decoder struct {
+	err   error
+	lossy []string // Tags of elements that are not converted losslessly
+}
+
+type decodeFunc func(*decoder, *sx.ListBuilder, any)
+
+var blockDecoders, inlineDecoders map[string]decodeFunc
+
+func init() {
+	blockDecoders = map[string]decodeFunc{
+		"Plain":          (*decoder).decodePara,
+		"Para":           (*decoder).decodePara,
+		"LineBlock":      (*decoder).decodeLineBlock,
+		"CodeBlock":      (*decoder).decodeCodeBlock,
+		"RawBlock":       (*decoder).decodeRawBlock,
+		"BlockQuote":     (*decoder).decodeBlockQuote,
+		"OrderedList":    (*decoder).decodeOrderedList,
+		"BulletList":     (*decoder).decodeBulletList,
+		"DefinitionList": (*decoder).decodeDefinitionList,
+		"Header":         (*decoder).decodeHeader,
+		"HorizontalRule": (*decoder).decodeHorizontalRule,
+		"Table":          (*decoder).decodeTable,
+		"Figure":         (*decoder).decodeFigure,
+		"Div":            (*decoder).decodeDiv,
+		"Null":           func(*decoder, *sx.ListBuilder, any) {},
+	}
+	inlineDecoders = map[string]decodeFunc{
+		"Emph":        decodeFormat(zsx.SymFormatEmph),
+		"Underline":   decodeFormat(zsx.SymFormatInsert),
+		"Strong":      decodeFormat(zsx.SymFormatStrong),
+		"Strikeout":   decodeFormat(zsx.SymFormatDelete),
+		"Superscript": decodeFormat(zsx.SymFormatSuper),
+		"Subscript":   decodeFormat(zsx.SymFormatSub),
+		"SmallCaps":   (*decoder).decodeSmallCaps,
+		"Quoted":      (*decoder).decodeQuoted,
+		"Cite":        (*decoder).decodeCite,
+		"Code":        (*decoder).decodeCode,
+		"SoftBreak":   func(_ *decoder, lb *sx.ListBuilder, _ any) { lb.Add(zsx.MakeSoft()) },
+		"LineBreak":   func(_ *decoder, lb *sx.ListBuilder, _ any) { lb.Add(zsx.MakeHard()) },
+		"Math":        (*decoder).decodeMath,
+		"RawInline":   (*decoder).decodeRawInline,
+		"Link":        (*decoder).decodeLink,
+		"Image":       (*decoder).decodeImage,
+		"Note":        (*decoder).decodeNote,
+		"Span":        (*decoder).decodeSpan,
+	}
+}
+
+// fail records the first error.
+func (dec *decoder) fail(format string, args ...any) {
+	if dec.err == nil {
+		dec.err = fmt.Errorf(format, args...)
+	}
+}
+
+// addLossy records the tag of an element that is not converted losslessly.
+func (dec *decoder) addLossy(tag string) { dec.lossy = append(dec.lossy, tag) }
+
+// element returns the tag and the content of a Pandoc element.
+func (dec *decoder) element(val any) (string, any) {
+	if obj, isObject := val.(map[string]any); isObject {
+		if tag, isString := obj["t"].(string); isString {
+			return tag, obj["c"]
+		}
+	}
+	dec.fail("not a Pandoc element: %v", val)
+	return "", nil
+}
+
+// tag returns the tag of a Pandoc element without content.
+func (dec *decoder) tag(val any) string {
+	tag, _ := dec.element(val)
+	return tag
+}
+
+// args returns the n arguments of the content of an element.
+func (dec *decoder) args(val any, n int) []any {
+	if arr, isArray := val.([]any); isArray && len(arr) == n {
+		return arr
+	}
+	dec.fail("%d arguments expected, but got: %v", n, val)
+	return make([]any, n)
+}
+
+func (dec *decoder) array(val any) []any {
+	if arr, isArray := val.([]any); isArray {
+		return arr
+	}
+	dec.fail("not an array: %v", val)
+	return nil
+}
+
+func (dec *decoder) str(val any) string {
+	if s, isString := val.(string); isString {
+		return s
+	}
+	dec.fail("not a string: %v", val)
+	return ""
+}
+
+func (dec *decoder) int(val any) int {
+	if num, isNumber := val.(json.Number); isNumber {
+		if i, err := strconv.Atoi(num.String()); err == nil {
+			return i
+		}
+	}
+	dec.fail("not an integer: %v", val)
+	return 0
+}
+
+// attributes returns the attributes of a Pandoc Attr. For code, the first
+// class is the syntax.
+func (dec *decoder) attributes(val any, code bool) zsx.Attributes {
+	args := dec.args(val, 3)
+	var a zsx.Attributes
+	if id := dec.str(args[0]); id != "" {
+		a = a.Set(keyID, id)
+	}
+	for i, class := range dec.array(args[1]) {
+		if code && i == 0 {
+			a = a.Set("", dec.str(class))
+		} else {
+			a = a.AddClass(dec.str(class))
+		}
+	}
+	for _, kv := range dec.array(args[2]) {
+		pair := dec.args(kv, 2)
+		a = a.Set(dec.str(pair[0]), dec.str(pair[1]))
+	}
+	return a
+}
+
+// blocks returns the block nodes of a list of Pandoc block elements.
+func (dec *decoder) blocks(vals []any) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, val := range vals {
+		tag, c := dec.element(val)
+		if dec.err != nil {
+			break
+		}
+		if fn, found := blockDecoders[tag]; found {
+			fn(dec, &lb, c)
+		} else {
+			dec.addLossy(tag)
+		}
+	}
+	return lb.List()
+}
+
+// inlines returns the inline nodes of a list of Pandoc inline elements.
+// Adjacent words and spaces are combined into one TEXT node.
+func (dec *decoder) inlines(vals []any) *sx.Pair {
+	var lb sx.ListBuilder
+	var sb strings.Builder
+	for _, val := range vals {
+		tag, c := dec.element(val)
+		if dec.err != nil {
+			break
+		}
+		switch tag {
+		case "Str":
+			sb.WriteString(dec.str(c))
+			continue
+		case "Space":
+			sb.WriteByte(' ')
+			continue
+		}
+		if sb.Len() > 0 {
+			lb.Add(zsx.MakeText(sb.String()))
+			sb.Reset()
+		}
+		if fn, found := inlineDecoders[tag]; found {
+			fn(dec, &lb, c)
+		} else {
+			dec.addLossy(tag)
+		}
+	}
+	if sb.Len() > 0 {
+		lb.Add(zsx.MakeText(sb.String()))
+	}
+	return lb.List()
+}
+
+// blockInlines returns the inline nodes of a list of blocks, which should
+// contain at most one Plain or Para element. Otherwise, the element with the
+// given tag is recorded as lossy, and the paragraphs are separated by a soft
+// line break.
+func (dec *decoder) blockInlines(tag string, blocks []any) *sx.Pair {
+	lossy := len(blocks) > 1
+	var lb sx.ListBuilder
+	first := true
+	for _, block := range blocks {
+		btag, c := dec.element(block)
+		if btag != "Plain" && btag != "Para" {
+			lossy = true
+			continue
+		}
+		if !first {
+			lb.Add(zsx.MakeSoft())
+		}
+		for node := range dec.inlines(dec.array(c)).Values() {
+			lb.Add(node)
+		}
+		first = false
+	}
+	if lossy {
+		dec.addLossy(tag)
+	}
+	return lb.List()
+}
+
+// decodePara decodes a paragraph. A paragraph with display math only is
+// decoded as VERBATIM-MATH.
+func (dec *decoder) decodePara(lb *sx.ListBuilder, c any) {
+	ins := dec.array(c)
+	if len(ins) == 1 {
+		if tag, mc := dec.element(ins[0]); tag == "Math" {
+			if args := dec.args(mc, 2); dec.tag(args[0]) == "DisplayMath" {
+				lb.Add(zsx.MakeVerbatim(zsx.SymVerbatimMath, nil, dec.str(args[1])))
+				return
+			}
+		}
+	}
+	lb.Add(zsx.MakeParaList(dec.inlines(ins)))
+}
+
+// decodeLineBlock decodes a line block as a verse region. Lines are
+// separated by a hard line break, empty lines separate paragraphs.
+func (dec *decoder) decodeLineBlock(lb *sx.ListBuilder, c any) {
+	var blocks, para sx.ListBuilder
+	empty := true
+	for _, line := range dec.array(c) {
+		ins := dec.array(line)
+		if len(ins) == 0 {
+			if !empty {
+				blocks.Add(zsx.MakeParaList(para.List()))
+				para, empty = sx.ListBuilder{}, true
+			}
+			continue
+		}
+		if !empty {
+			para.Add(zsx.MakeHard())
+		}
+		for node := range dec.inlines(ins).Values() {
+			para.Add(node)
+		}
+		empty = false
+	}
+	if !empty {
+		blocks.Add(zsx.MakeParaList(para.List()))
+	}
+	lb.Add(zsx.MakeRegion(zsx.SymRegionVerse, nil, blocks.List(), nil))
+}
+
+func (dec *decoder) decodeCodeBlock(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	lb.Add(zsx.MakeVerbatim(zsx.SymVerbatimCode, dec.attributes(args[0], true).AsAssoc(), dec.str(args[1])))
+}
+
+// decodeRawBlock decodes raw HTML as VERBATIM-HTML. Other formats are
+// decoded as code with the format as its syntax.
+func (dec *decoder) decodeRawBlock(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	format, content := dec.str(args[0]), dec.str(args[1])
+	if format == "html" {
+		lb.Add(zsx.MakeVerbatim(zsx.SymVerbatimHTML, nil, content))
+		return
+	}
+	dec.addLossy("RawBlock")
+	lb.Add(zsx.MakeVerbatim(zsx.SymVerbatimCode, zsx.Attributes{"": format}.AsAssoc(), content))
+}
+
+func (dec *decoder) decodeBlockQuote(lb *sx.ListBuilder, c any) {
+	item := zsx.MakeListItem(nil, dec.blocks(dec.array(c)))
+	lb.Add(zsx.MakeList(zsx.SymListQuote, nil, sx.MakeList(item)))
+}
+
+func (dec *decoder) decodeOrderedList(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	listAttrs := dec.args(args[0], 3)
+	if style := dec.tag(listAttrs[1]); style != "DefaultStyle" && style != "Decimal" {
+		dec.addLossy("OrderedList")
+	} else if delim := dec.tag(listAttrs[2]); delim != "DefaultDelim" && delim != "Period" {
+		dec.addLossy("OrderedList")
+	}
+	var attrs *sx.Pair
+	if start := dec.int(listAttrs[0]); start != 1 {
+		attrs = zsx.Attributes{keyStart: strconv.Itoa(start)}.AsAssoc()
+	}
+	lb.Add(zsx.MakeList(zsx.SymListOrdered, attrs, dec.items(args[1])))
+}
+
+func (dec *decoder) decodeBulletList(lb *sx.ListBuilder, c any) {
+	lb.Add(zsx.MakeList(zsx.SymListUnordered, nil, dec.items(c)))
+}
+
+// items returns the list items of a Pandoc list.
+func (dec *decoder) items(val any) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, item := range dec.array(val) {
+		lb.Add(zsx.MakeListItem(nil, dec.blocks(dec.array(item))))
+	}
+	return lb.List()
+}
+
+func (dec *decoder) decodeDefinitionList(lb *sx.ListBuilder, c any) {
+	var elems sx.ListBuilder
+	for _, def := range dec.array(c) {
+		args := dec.args(def, 2)
+		elems.Add(zsx.MakeTerm(nil, dec.inlines(dec.array(args[0]))))
+		var entries sx.ListBuilder
+		for _, blocks := range dec.array(args[1]) {
+			entries.Add(zsx.MakeEntry(nil, dec.blocks(dec.array(blocks))))
+		}
+		elems.Add(zsx.MakeDetail(entries.List()))
+	}
+	lb.Add(zsx.MakeDescription(nil, elems.List()))
+}
+
+func (dec *decoder) decodeHeader(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 3)
+	level, attrs := dec.int(args[0]), dec.attributes(args[1], false).AsAssoc()
+	lb.Add(zsx.MakeHeading(attrs, level, dec.inlines(dec.array(args[2]))))
+}
+
+func (*decoder) decodeHorizontalRule(lb *sx.ListBuilder, _ any) {
+	lb.Add(zsx.MakeThematic(nil))
+}
+
+// decodeTable decodes a table. The first row of the table head is the
+// header of the table, all other rows are appended to the table rows.
+func (dec *decoder) decodeTable(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 6)
+	lossy := false
+	if caption := dec.args(args[1], 2); len(dec.array(caption[1])) > 0 {
+		lossy = true
+	}
+	var aligns []sx.Object
+	for _, spec := range dec.array(args[2]) {
+		aligns = append(aligns, dec.alignment(dec.args(spec, 2)[0]))
+	}
+
+	head := dec.args(args[3], 2)
+	rows := dec.array(head[1])
+	lossy = lossy || len(rows) > 1 || !dec.attributes(head[0], false).IsEmpty()
+	for _, body := range dec.array(args[4]) {
+		bodyArgs := dec.args(body, 4)
+		headRows, bodyRows := dec.array(bodyArgs[2]), dec.array(bodyArgs[3])
+		lossy = lossy || len(headRows) > 0 || dec.int(bodyArgs[1]) != 0 || !dec.attributes(bodyArgs[0], false).IsEmpty()
+		rows = append(append(rows, headRows...), bodyRows...)
+	}
+	foot := dec.args(args[5], 2)
+	footRows := dec.array(foot[1])
+	lossy = lossy || len(footRows) > 0 || !dec.attributes(foot[0], false).IsEmpty()
+	rows = append(rows, footRows...)
+
+	var header *sx.Pair
+	var lbRows sx.ListBuilder
+	for i, row := range rows {
+		rowNode := dec.row(row, aligns)
+		if i == 0 && len(dec.array(head[1])) > 0 {
+			header = rowNode
+		} else {
+			lbRows.Add(rowNode)
+		}
+	}
+	if lossy {
+		dec.addLossy("Table")
+	}
+	lb.Add(zsx.MakeTable(dec.attributes(args[0], false).AsAssoc(), header, lbRows.List()))
+}
+
+// alignment returns the value of the align attribute of a Pandoc alignment,
+// or nil for the default alignment.
+func (dec *decoder) alignment(val any) sx.Object {
+	switch dec.tag(val) {
+	case "AlignLeft":
+		return zsx.AttrAlignLeft
+	case "AlignCenter":
+		return zsx.AttrAlignCenter
+	case "AlignRight":
+		return zsx.AttrAlignRight
+	}
+	return nil
+}
+
+// row decodes a table row. A cell with the default alignment gets the
+// alignment of its column.
+func (dec *decoder) row(val any, aligns []sx.Object) *sx.Pair {
+	args := dec.args(val, 2)
+	var lb sx.ListBuilder
+	for i, cell := range dec.array(args[1]) {
+		cellArgs := dec.args(cell, 5)
+		if dec.int(cellArgs[2]) != 1 || dec.int(cellArgs[3]) != 1 {
+			dec.addLossy("Cell")
+		}
+		attrs := dec.attributes(cellArgs[0], false).AsAssoc()
+		align := dec.alignment(cellArgs[1])
+		if align == nil && i < len(aligns) {
+			align = aligns[i]
+		}
+		if align != nil {
+			attrs = attrs.Cons(sx.Cons(zsx.SymAttrAlign, align))
+		}
+		lb.Add(zsx.MakeCell(attrs, dec.blockInlines("Cell", dec.array(cellArgs[4]))))
+	}
+	return zsx.MakeRow(dec.attributes(args[0], false).AsAssoc(), lb.List())
+}
+
+// decodeFigure decodes a figure with a data URL image as a BLOB. Its
+// description is the caption, or the alternative text of the image. All other
+// figures are decoded as a block region with the caption as its inlines.
+func (dec *decoder) decodeFigure(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 3)
+	attrs := dec.attributes(args[0], false).AsAssoc()
+	captionBlocks := dec.array(dec.args(args[1], 2)[1])
+	blocks := dec.array(args[2])
+	if len(blocks) == 1 {
+		if tag, pc := dec.element(blocks[0]); tag == "Plain" || tag == "Para" {
+			if ins := dec.array(pc); len(ins) == 1 {
+				if tag, ic := dec.element(ins[0]); tag == "Image" {
+					imageArgs := dec.args(ic, 3)
+					target := dec.args(imageArgs[2], 2)
+					if syntax, data, isData := parseDataURL(dec.str(target[0])); isData {
+						description := dec.blockInlines("Figure", captionBlocks)
+						if description == nil {
+							description = dec.inlines(dec.array(imageArgs[1]))
+						}
+						lb.Add(zsx.MakeBLOBuncode(attrs, syntax, data, description))
+						return
+					}
+				}
+			}
+		}
+	}
+	dec.addLossy("Figure")
+	lb.Add(zsx.MakeRegion(zsx.SymRegionBlock, attrs, dec.blocks(blocks), dec.blockInlines("Figure", captionBlocks)))
+}
+
+func (dec *decoder) decodeDiv(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	attrs := dec.attributes(args[0], false).AsAssoc()
+	lb.Add(zsx.MakeRegion(zsx.SymRegionBlock, attrs, dec.blocks(dec.array(args[1])), nil))
+}
+
+func decodeFormat(sym *sx.Symbol) decodeFunc {
+	return func(dec *decoder, lb *sx.ListBuilder, c any) {
+		lb.Add(zsx.MakeFormat(sym, nil, dec.inlines(dec.array(c))))
+	}
+}
+
+func (dec *decoder) decodeSmallCaps(lb *sx.ListBuilder, c any) {
+	dec.addLossy("SmallCaps")
+	attrs := zsx.Attributes{keyClass: "smallcaps"}.AsAssoc()
+	lb.Add(zsx.MakeFormat(zsx.SymFormatSpan, attrs, dec.inlines(dec.array(c))))
+}
+
+func (dec *decoder) decodeQuoted(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	if dec.tag(args[0]) != "DoubleQuote" {
+		dec.addLossy("Quoted")
+	}
+	lb.Add(zsx.MakeFormat(zsx.SymFormatQuote, nil, dec.inlines(dec.array(args[1]))))
+}
+
+// decodeCite decodes each citation as a CITE node with the suffix of the
+// citation as its inline nodes. The text of the citation is ignored.
+func (dec *decoder) decodeCite(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	citations := dec.array(args[0])
+	if len(citations) == 0 {
+		dec.addLossy("Cite")
+		for node := range dec.inlines(dec.array(args[1])).Values() {
+			lb.Add(node)
+		}
+		return
+	}
+	for _, citation := range citations {
+		obj, isObject := citation.(map[string]any)
+		if !isObject {
+			dec.fail("not a citation: %v", citation)
+			return
+		}
+		if len(dec.array(obj["citationPrefix"])) > 0 || dec.tag(obj["citationMode"]) != "NormalCitation" {
+			dec.addLossy("Cite")
+		}
+		suffix := dec.inlines(dec.array(obj["citationSuffix"]))
+		lb.Add(zsx.MakeCite(nil, dec.str(obj["citationId"]), suffix))
+	}
+}
+
+func (dec *decoder) decodeCode(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	lb.Add(zsx.MakeLiteral(zsx.SymLiteralCode, dec.attributes(args[0], true).AsAssoc(), dec.str(args[1])))
+}
+
+func (dec *decoder) decodeMath(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	if dec.tag(args[0]) != "InlineMath" {
+		dec.addLossy("Math")
+	}
+	lb.Add(zsx.MakeLiteral(zsx.SymLiteralMath, nil, dec.str(args[1])))
+}
+
+// decodeRawInline decodes raw content as code with the format as its
+// syntax. Only raw HTML is converted losslessly.
+func (dec *decoder) decodeRawInline(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	format := dec.str(args[0])
+	if format != "html" {
+		dec.addLossy("RawInline")
+	}
+	lb.Add(zsx.MakeLiteral(zsx.SymLiteralCode, zsx.Attributes{"": format}.AsAssoc(), dec.str(args[1])))
+}
+
+// target returns the attributes of a link or an image, including its title,
+// and the URL of its target.
+func (dec *decoder) target(attrVal, targetVal any) (zsx.Attributes, string) {
+	a := dec.attributes(attrVal, false)
+	target := dec.args(targetVal, 2)
+	if title := dec.str(target[1]); title != "" {
+		a = a.Set(keyTitle, title)
+	}
+	return a, dec.str(target[0])
+}
+
+func (dec *decoder) decodeLink(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 3)
+	a, u := dec.target(args[0], args[2])
+	lb.Add(zsx.MakeLink(a.AsAssoc(), zsx.ParseReference(u), dec.inlines(dec.array(args[1]))))
+}
+
+// decodeImage decodes an image with a data URL as EMBED-BLOB, and all other
+// images as EMBED.
+func (dec *decoder) decodeImage(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 3)
+	a, u := dec.target(args[0], args[2])
+	ins := dec.inlines(dec.array(args[1]))
+	if syntax, data, isData := parseDataURL(u); isData {
+		lb.Add(zsx.MakeEmbedBLOBuncode(a.AsAssoc(), syntax, data, ins))
+		return
+	}
+	lb.Add(zsx.MakeEmbed(a.AsAssoc(), zsx.ParseReference(u), "", ins))
+}
+
+func (dec *decoder) decodeNote(lb *sx.ListBuilder, c any) {
+	lb.Add(zsx.MakeEndnote(nil, dec.blockInlines("Note", dec.array(c))))
+}
+
+// decodeSpan decodes a span. A span with the class "mark" is decoded as
+// FORMAT-MARK.
+func (dec *decoder) decodeSpan(lb *sx.ListBuilder, c any) {
+	args := dec.args(c, 2)
+	a := dec.attributes(args[0], false)
+	sym := zsx.SymFormatSpan
+	if a.HasClass(classMark) {
+		sym = zsx.SymFormatMark
+		var classes []string
+		for _, class := range a.GetClasses() {
+			if class != classMark {
+				classes = append(classes, class)
+			}
+		}
+		if len(classes) == 0 {
+			a = a.Remove(keyClass)
+		} else {
+			a = a.Set(keyClass, strings.Join(classes, " "))
+		}
+	}
+	lb.Add(zsx.MakeFormat(sym, a.AsAssoc(), dec.inlines(dec.array(args[1]))))
+}
@@ -0,0 +1,656 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package pandoc
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Encode writes the given node as a Pandoc JSON document to the writer.
+// Typically, the node is a BLOCK or an INLINE node.
+//
+// All nodes that could not be represented losslessly are returned, in the
+// order they were encountered. This includes nodes that are dropped, like
+// comments, and nodes where some information, like attributes, is lost.
+func Encode(w io.Writer, node *sx.Pair) ([]*sx.Pair, error) {
+	var enc encoder
+	doc := map[string]any{
+		"pandoc-api-version": apiVersion,
+		"meta":               map[string]any{},
+		"blocks":             enc.blocks(sx.MakeList(node)),
+	}
+	je := json.NewEncoder(w)
+	je.SetEscapeHTML(false)
+	return enc.lossy, je.Encode(doc)
+}
+
+type encoder struct {
+	lossy []*sx.Pair // Nodes that are not represented losslessly
+}
+
+type encodeFunc func(*encoder, []any, *sx.Pair) []any
+
+var blockFuncs, inlineFuncs map[*sx.Symbol]encodeFunc
+
+func init() {
+	blockFuncs = map[*sx.Symbol]encodeFunc{
+		zsx.SymBlock:           (*encoder).encodeBlock,
+		zsx.SymPara:            (*encoder).encodePara,
+		zsx.SymHeading:         (*encoder).encodeHeading,
+		zsx.SymThematic:        (*encoder).encodeThematic,
+		zsx.SymListOrdered:     (*encoder).encodeList,
+		zsx.SymListUnordered:   (*encoder).encodeList,
+		zsx.SymListQuote:       (*encoder).encodeQuotation,
+		zsx.SymDescription:     (*encoder).encodeDescription,
+		zsx.SymTable:           (*encoder).encodeTable,
+		zsx.SymRegionBlock:     (*encoder).encodeRegion,
+		zsx.SymRegionQuote:     (*encoder).encodeRegion,
+		zsx.SymRegionVerse:     (*encoder).encodeVerse,
+		zsx.SymVerbatimCode:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimComment: (*encoder).encodeComment,
+		zsx.SymVerbatimEval:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimHTML:    (*encoder).encodeVerbatimHTML,
+		zsx.SymVerbatimMath:    (*encoder).encodeVerbatimMath,
+		zsx.SymVerbatimZettel:  (*encoder).encodeVerbatim,
+		zsx.SymTransclude:      (*encoder).encodeTransclusion,
+		zsx.SymBLOB:            (*encoder).encodeBLOB,
+		zsx.SymSpecialSplice:   (*encoder).encodeBlock,
+	}
+	inlineFuncs = map[*sx.Symbol]encodeFunc{
+		zsx.SymInline:         (*encoder).encodeInline,
+		zsx.SymText:           (*encoder).encodeText,
+		zsx.SymSoft:           (*encoder).encodeSoft,
+		zsx.SymHard:           (*encoder).encodeHard,
+		zsx.SymLink:           (*encoder).encodeLink,
+		zsx.SymEmbed:          (*encoder).encodeEmbed,
+		zsx.SymEmbedBLOB:      (*encoder).encodeEmbedBLOB,
+		zsx.SymCite:           (*encoder).encodeCite,
+		zsx.SymEndnote:        (*encoder).encodeEndnote,
+		zsx.SymMark:           (*encoder).encodeMark,
+		zsx.SymFormatDelete:   (*encoder).encodeFormat,
+		zsx.SymFormatEmph:     (*encoder).encodeFormat,
+		zsx.SymFormatInsert:   (*encoder).encodeFormat,
+		zsx.SymFormatMark:     (*encoder).encodeFormatMark,
+		zsx.SymFormatQuote:    (*encoder).encodeFormatQuote,
+		zsx.SymFormatSpan:     (*encoder).encodeFormatSpan,
+		zsx.SymFormatStrong:   (*encoder).encodeFormat,
+		zsx.SymFormatSub:      (*encoder).encodeFormat,
+		zsx.SymFormatSuper:    (*encoder).encodeFormat,
+		zsx.SymLiteralCode:    (*encoder).encodeLiteral,
+		zsx.SymLiteralComment: (*encoder).encodeComment,
+		zsx.SymLiteralInput:   (*encoder).encodeLiteral,
+		zsx.SymLiteralMath:    (*encoder).encodeLiteralMath,
+		zsx.SymLiteralOutput:  (*encoder).encodeLiteral,
+		zsx.SymSpecialSplice:  (*encoder).encodeInline,
+	}
+}
+
+// element builds a Pandoc element. A single argument is the content of the
+// element, multiple arguments are stored as an array.
+func element(tag string, args ...any) map[string]any {
+	switch len(args) {
+	case 0:
+		return map[string]any{"t": tag}
+	case 1:
+		return map[string]any{"t": tag, "c": args[0]}
+	}
+	return map[string]any{"t": tag, "c": args}
+}
+
+// nullAttr returns an empty Pandoc Attr.
+func nullAttr() []any { return []any{"", []string{}, [][]string{}} }
+
+// attr returns the Pandoc Attr of the attributes, without the given keys.
+func attr(attrs *sx.Pair, omit ...string) []any {
+	return makeAttr(zsx.GetAttributes(attrs), false, omit...)
+}
+
+// codeAttr returns the Pandoc Attr of code, where the syntax is the first
+// class.
+func codeAttr(attrs *sx.Pair) []any { return makeAttr(zsx.GetAttributes(attrs), true) }
+
+func makeAttr(a zsx.Attributes, code bool, omit ...string) []any {
+	a.CleanSpecial()
+	id, _ := a.Get(keyID)
+	classes := []string{}
+	if code {
+		if syntax, _ := a.Get(""); syntax != "" {
+			classes = append(classes, syntax)
+		}
+	}
+	classes = append(classes, a.GetClasses()...)
+	kvs := [][]string{}
+	for _, key := range a.Keys() {
+		if key == keyID || key == keyClass || (code && key == "") || slices.Contains(omit, key) {
+			continue
+		}
+		kvs = append(kvs, []string{key, a[key]})
+	}
+	return []any{id, classes, kvs}
+}
+
+// addLossy records a node that is not represented losslessly.
+func (enc *encoder) addLossy(node *sx.Pair) { enc.lossy = append(enc.lossy, node) }
+
+// checkAttributes records the node as lossy, if Pandoc cannot represent some
+// of its attributes, i.e. other attributes than the given keys.
+func (enc *encoder) checkAttributes(node, attrs *sx.Pair, keys ...string) {
+	if zsx.GetAttributes(attrs).HasOtherKeys(keys...) {
+		enc.addLossy(node)
+	}
+}
+
+// blocks returns the Pandoc elements of a list of block nodes. Inline nodes
+// in a block context are collected into a Plain element.
+func (enc *encoder) blocks(lst *sx.Pair) []any {
+	result := []any{}
+	var plain []any
+	for obj := range lst.Values() {
+		node, isPair := sx.GetPair(obj)
+		if !isPair || node == nil {
+			continue
+		}
+		if sym := zsx.NodeSymbol(node); sym != zsx.SymSpecialSplice && inlineFuncs[sym] != nil {
+			plain = enc.appendInline(plain, node)
+			continue
+		}
+		if plain != nil {
+			result = append(result, element("Plain", plain))
+			plain = nil
+		}
+		if fn, found := blockFuncs[zsx.NodeSymbol(node)]; found {
+			result = fn(enc, result, node)
+		} else {
+			enc.addLossy(node)
+		}
+	}
+	if plain != nil {
+		result = append(result, element("Plain", plain))
+	}
+	return result
+}
+
+// inlines returns the Pandoc elements of a list of inline nodes.
+func (enc *encoder) inlines(lst *sx.Pair) []any {
+	result := []any{}
+	for obj := range lst.Values() {
+		if node, isPair := sx.GetPair(obj); isPair && node != nil {
+			result = enc.appendInline(result, node)
+		}
+	}
+	return result
+}
+
+func (enc *encoder) appendInline(result []any, node *sx.Pair) []any {
+	if fn, found := inlineFuncs[zsx.NodeSymbol(node)]; found {
+		return fn(enc, result, node)
+	}
+	enc.addLossy(node)
+	return result
+}
+
+func (enc *encoder) encodeBlock(result []any, node *sx.Pair) []any {
+	return append(result, enc.blocks(node.Tail())...)
+}
+
+func (enc *encoder) encodePara(result []any, node *sx.Pair) []any {
+	return append(result, element("Para", enc.inlines(zsx.GetPara(node))))
+}
+
+// maxHeadingLevel is the highest heading level of Pandoc.
+const maxHeadingLevel = 6
+
+func (enc *encoder) encodeHeading(result []any, node *sx.Pair) []any {
+	attrs, level, ins := zsx.GetHeading(node)
+	if level < 1 || level > maxHeadingLevel {
+		enc.addLossy(node)
+	}
+	return append(result, element("Header", min(max(level, 1), maxHeadingLevel), attr(attrs), enc.inlines(ins)))
+}
+
+func (enc *encoder) encodeThematic(result []any, node *sx.Pair) []any {
+	enc.checkAttributes(node, zsx.GetThematic(node))
+	return append(result, element("HorizontalRule"))
+}
+
+func (enc *encoder) encodeList(result []any, node *sx.Pair) []any {
+	sym, attrs, items := zsx.GetList(node)
+	if sym == zsx.SymListUnordered {
+		enc.checkAttributes(node, attrs)
+		return append(result, element("BulletList", enc.items(items)))
+	}
+	enc.checkAttributes(node, attrs, keyStart)
+	start := 1
+	if val, found := zsx.GetAttributes(attrs).Get(keyStart); found {
+		if num, err := strconv.Atoi(val); err == nil {
+			start = num
+		} else {
+			enc.addLossy(node)
+		}
+	}
+	listAttrs := []any{start, element("Decimal"), element("Period")}
+	return append(result, element("OrderedList", listAttrs, enc.items(items)))
+}
+
+// items returns the blocks of each list item.
+func (enc *encoder) items(items *sx.Pair) []any {
+	result := []any{}
+	for item := range items.Values() {
+		if itemNode, isPair := sx.GetPair(item); isPair && itemNode != nil {
+			attrs, elems := zsx.GetListItem(itemNode)
+			enc.checkAttributes(itemNode, attrs)
+			result = append(result, enc.blocks(elems))
+		}
+	}
+	return result
+}
+
+func (enc *encoder) encodeQuotation(result []any, node *sx.Pair) []any {
+	_, attrs, items := zsx.GetList(node)
+	enc.checkAttributes(node, attrs)
+	blocks := []any{}
+	for i, itemBlocks := range enc.items(items) {
+		if i == 1 {
+			// Items of a block quote cannot be separated in Pandoc.
+			enc.addLossy(node)
+		}
+		blocks = append(blocks, itemBlocks.([]any)...)
+	}
+	return append(result, element("BlockQuote", blocks))
+}
+
+func (enc *encoder) encodeDescription(result []any, node *sx.Pair) []any {
+	attrs, elems := zsx.GetDescription(node)
+	enc.checkAttributes(node, attrs)
+	defs := []any{}
+	var term, details []any
+	for elem := range elems.Values() {
+		elemNode, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		switch zsx.NodeSymbol(elemNode) {
+		case zsx.SymTerm:
+			if term != nil {
+				defs = append(defs, []any{term, details})
+			}
+			attrs, ins := zsx.GetTerm(elemNode)
+			enc.checkAttributes(elemNode, attrs)
+			term, details = enc.inlines(ins), []any{}
+		case zsx.SymDetail:
+			if term == nil {
+				// A definition always has a term.
+				enc.addLossy(elemNode)
+				term, details = []any{}, []any{}
+			}
+			for entry := range zsx.GetDetail(elemNode).Values() {
+				if entryNode, isEntry := sx.GetPair(entry); isEntry && entryNode != nil {
+					attrs, blocks := zsx.GetEntry(entryNode)
+					enc.checkAttributes(entryNode, attrs)
+					details = append(details, enc.blocks(blocks))
+				}
+			}
+		}
+	}
+	if term != nil {
+		defs = append(defs, []any{term, details})
+	}
+	return append(result, element("DefinitionList", defs))
+}
+
+// alignments maps the values of the align attribute to Pandoc alignments.
+var alignments = map[string]string{
+	"":                             "AlignDefault",
+	zsx.AttrAlignLeft.GetValue():   "AlignLeft",
+	zsx.AttrAlignCenter.GetValue(): "AlignCenter",
+	zsx.AttrAlignRight.GetValue():  "AlignRight",
+}
+
+func (enc *encoder) encodeTable(result []any, node *sx.Pair) []any {
+	attrs, header, rows := zsx.GetTable(node)
+	var allRows []*sx.Pair
+	if header != nil {
+		allRows = append(allRows, header)
+	}
+	for row := range rows.Values() {
+		if rowNode, isPair := sx.GetPair(row); isPair && rowNode != nil {
+			allRows = append(allRows, rowNode)
+		}
+	}
+
+	// The alignment of a column is taken from its first cell with an
+	// alignment.
+	var aligns []string
+	for _, row := range allRows {
+		_, cells := zsx.GetRow(row)
+		i := 0
+		for cell := range cells.Values() {
+			if cellNode, isPair := sx.GetPair(cell); isPair && cellNode != nil {
+				cellAttrs, _ := zsx.GetCell(cellNode)
+				align, _ := zsx.GetAttributes(cellAttrs).Get(zsx.SymAttrAlign.GetValue())
+				if i >= len(aligns) {
+					aligns = append(aligns, align)
+				} else if aligns[i] == "" {
+					aligns[i] = align
+				}
+				i++
+			}
+		}
+	}
+	colSpecs := []any{}
+	for _, align := range aligns {
+		colSpecs = append(colSpecs, []any{element(alignments[align]), element("ColWidthDefault")})
+	}
+
+	headRows, bodyRows := []any{}, []any{}
+	for _, row := range allRows {
+		if row == header {
+			headRows = append(headRows, enc.row(row, aligns))
+		} else {
+			bodyRows = append(bodyRows, enc.row(row, aligns))
+		}
+	}
+	caption := []any{nil, []any{}}
+	head := []any{nullAttr(), headRows}
+	bodies := []any{[]any{nullAttr(), 0, []any{}, bodyRows}}
+	foot := []any{nullAttr(), []any{}}
+	return append(result, element("Table", attr(attrs), caption, colSpecs, head, bodies, foot))
+}
+
+// row returns a Pandoc table row with exactly one cell per column.
+func (enc *encoder) row(row *sx.Pair, aligns []string) []any {
+	attrs, cells := zsx.GetRow(row)
+	result := []any{}
+	for cell := range cells.Values() {
+		cellNode, isPair := sx.GetPair(cell)
+		if !isPair || cellNode == nil {
+			continue
+		}
+		cellAttrs, ins := zsx.GetCell(cellNode)
+		val, _ := zsx.GetAttributes(cellAttrs).Get(zsx.SymAttrAlign.GetValue())
+		align, found := alignments[val]
+		if !found {
+			enc.addLossy(cellNode)
+			align = alignments[""]
+		} else if align == alignments[""] && aligns[len(result)] != "" {
+			// The cell would inherit the alignment of its column.
+			enc.addLossy(cellNode)
+		}
+		blocks := []any{}
+		if ins != nil {
+			blocks = append(blocks, element("Plain", enc.inlines(ins)))
+		}
+		result = append(result, []any{attr(cellAttrs, zsx.SymAttrAlign.GetValue()), element(align), 1, 1, blocks})
+	}
+	for len(result) < len(aligns) {
+		result = append(result, []any{nullAttr(), element(alignments[""]), 1, 1, []any{}})
+	}
+	return []any{attr(attrs), result}
+}
+
+func (enc *encoder) encodeRegion(result []any, node *sx.Pair) []any {
+	sym, attrs, blocks, ins := zsx.GetRegion(node)
+	content := enc.blocks(blocks)
+	if ins != nil {
+		content = append(content, element("Para", enc.attribution(ins)))
+	}
+	if sym == zsx.SymRegionQuote {
+		// A block quote is decoded as a QUOTATION list.
+		enc.addLossy(node)
+		return append(result, element("BlockQuote", content))
+	}
+	if ins != nil {
+		enc.addLossy(node)
+	}
+	return append(result, element("Div", attr(attrs), content))
+}
+
+// attribution returns the inline elements of the attribution of a region.
+func (enc *encoder) attribution(ins *sx.Pair) []any {
+	return append([]any{element("Str", "—"), element("Space")}, enc.inlines(ins)...)
+}
+
+// encodeVerse writes each line of the paragraphs of a verse region as a
+// line of a line block. Paragraphs are separated by an empty line.
+func (enc *encoder) encodeVerse(result []any, node *sx.Pair) []any {
+	_, attrs, blocks, ins := zsx.GetRegion(node)
+	lossy := ins != nil || len(zsx.GetAttributes(attrs)) > 0
+	lines := []any{}
+	for block := range blocks.Values() {
+		blockNode, isPair := sx.GetPair(block)
+		if !isPair || zsx.NodeSymbol(blockNode) != zsx.SymPara {
+			lossy = true
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, []any{})
+		}
+		line := []any{}
+		for elem := range zsx.GetPara(blockNode).Values() {
+			if elemNode, isElem := sx.GetPair(elem); isElem && elemNode != nil {
+				if sym := zsx.NodeSymbol(elemNode); sym == zsx.SymSoft || sym == zsx.SymHard {
+					lossy = lossy || sym == zsx.SymSoft
+					lines, line = append(lines, line), []any{}
+					continue
+				}
+				line = enc.appendInline(line, elemNode)
+			}
+		}
+		lines = append(lines, line)
+	}
+	if ins != nil {
+		lines = append(lines, enc.attribution(ins))
+	}
+	if lossy {
+		enc.addLossy(node)
+	}
+	return append(result, element("LineBlock", lines))
+}
+
+func (enc *encoder) encodeVerbatim(result []any, node *sx.Pair) []any {
+	sym, attrs, content := zsx.GetVerbatim(node)
+	if sym != zsx.SymVerbatimCode {
+		enc.addLossy(node)
+	}
+	return append(result, element("CodeBlock", codeAttr(attrs), content))
+}
+
+func (enc *encoder) encodeVerbatimHTML(result []any, node *sx.Pair) []any {
+	_, attrs, content := zsx.GetVerbatim(node)
+	enc.checkAttributes(node, attrs)
+	return append(result, element("RawBlock", "html", content))
+}
+
+func (enc *encoder) encodeVerbatimMath(result []any, node *sx.Pair) []any {
+	_, attrs, content := zsx.GetVerbatim(node)
+	enc.checkAttributes(node, attrs)
+	return append(result, element("Para", []any{element("Math", element("DisplayMath"), content)}))
+}
+
+// encodeComment drops comments, because they are not part of the Pandoc AST.
+func (enc *encoder) encodeComment(result []any, node *sx.Pair) []any {
+	enc.addLossy(node)
+	return result
+}
+
+func (enc *encoder) encodeTransclusion(result []any, node *sx.Pair) []any {
+	enc.addLossy(node)
+	attrs, ref, ins := zsx.GetTransclusion(node)
+	_, val := zsx.GetReference(ref)
+	link := element("Link", attr(attrs), enc.inlines(ins), []any{val, ""})
+	return append(result, element("Para", []any{link}))
+}
+
+// encodeBLOB writes a BLOB as a figure with a data URL image. Its
+// description is both the caption and the alternative text of the image.
+func (enc *encoder) encodeBLOB(result []any, node *sx.Pair) []any {
+	attrs, syntax, data, ins := zsx.GetBLOBuncode(node)
+	description := enc.inlines(ins)
+	caption := []any{}
+	if len(description) > 0 {
+		caption = append(caption, element("Plain", description))
+	}
+	image := element("Image", nullAttr(), description, []any{zsx.DataURL(syntax, data), ""})
+	return append(result, element("Figure", attr(attrs), []any{nil, caption}, []any{element("Plain", []any{image})}))
+}
+
+func (enc *encoder) encodeInline(result []any, node *sx.Pair) []any {
+	return append(result, enc.inlines(node.Tail())...)
+}
+
+// encodeText splits the text into words, separated by Space elements.
+func (*encoder) encodeText(result []any, node *sx.Pair) []any {
+	for i, word := range strings.Split(zsx.GetText(node), " ") {
+		if i > 0 {
+			result = append(result, element("Space"))
+		}
+		if word != "" {
+			result = append(result, element("Str", word))
+		}
+	}
+	return result
+}
+
+func (*encoder) encodeSoft(result []any, _ *sx.Pair) []any {
+	return append(result, element("SoftBreak"))
+}
+
+func (*encoder) encodeHard(result []any, _ *sx.Pair) []any {
+	return append(result, element("LineBreak"))
+}
+
+func (enc *encoder) encodeLink(result []any, node *sx.Pair) []any {
+	attrs, ref, ins := zsx.GetLink(node)
+	return append(result, element("Link", attr(attrs, keyTitle), enc.inlines(ins), enc.target(node, attrs, ref)))
+}
+
+// target returns the Pandoc target of a reference. The state of the
+// reference is lost, if it is not the state of its value.
+func (enc *encoder) target(node, attrs, ref *sx.Pair) []any {
+	sym, val := zsx.GetReference(ref)
+	if sym != zsx.ReferenceState(val) {
+		enc.addLossy(node)
+	}
+	title, _ := zsx.GetAttributes(attrs).Get(keyTitle)
+	return []any{val, title}
+}
+
+func (enc *encoder) encodeEmbed(result []any, node *sx.Pair) []any {
+	attrs, ref, syntax, ins := zsx.GetEmbed(node)
+	if syntax != "" {
+		enc.addLossy(node)
+	}
+	return append(result, element("Image", attr(attrs, keyTitle), enc.inlines(ins), enc.target(node, attrs, ref)))
+}
+
+func (enc *encoder) encodeEmbedBLOB(result []any, node *sx.Pair) []any {
+	attrs, syntax, data, ins := zsx.GetEmbedBLOBuncode(node)
+	title, _ := zsx.GetAttributes(attrs).Get(keyTitle)
+	target := []any{zsx.DataURL(syntax, data), title}
+	return append(result, element("Image", attr(attrs, keyTitle), enc.inlines(ins), target))
+}
+
+// encodeCite writes a citation with the inline nodes as its suffix.
+func (enc *encoder) encodeCite(result []any, node *sx.Pair) []any {
+	attrs, key, ins := zsx.GetCite(node)
+	enc.checkAttributes(node, attrs)
+	suffix := enc.inlines(ins)
+	citation := map[string]any{
+		"citationId":      key,
+		"citationPrefix":  []any{},
+		"citationSuffix":  suffix,
+		"citationMode":    element("NormalCitation"),
+		"citationNoteNum": 0,
+		"citationHash":    0,
+	}
+	text := []any{element("Str", "[@"+key)}
+	if len(suffix) > 0 {
+		text = append(append(text, element("Space")), suffix...)
+	}
+	text = append(text, element("Str", "]"))
+	return append(result, element("Cite", []any{citation}, text))
+}
+
+func (enc *encoder) encodeEndnote(result []any, node *sx.Pair) []any {
+	attrs, ins := zsx.GetEndnote(node)
+	enc.checkAttributes(node, attrs)
+	return append(result, element("Note", []any{element("Para", enc.inlines(ins))}))
+}
+
+// encodeMark writes a mark as a span with the mark as its identifier.
+func (enc *encoder) encodeMark(result []any, node *sx.Pair) []any {
+	enc.addLossy(node)
+	attrs, mark, ins := zsx.GetMark(node)
+	spanAttr := attr(attrs)
+	spanAttr[0] = mark
+	return append(result, element("Span", spanAttr, enc.inlines(ins)))
+}
+
+// formatTags maps format symbols to the tags of Pandoc elements.
+var formatTags = map[*sx.Symbol]string{
+	zsx.SymFormatDelete: "Strikeout",
+	zsx.SymFormatEmph:   "Emph",
+	zsx.SymFormatInsert: "Underline",
+	zsx.SymFormatStrong: "Strong",
+	zsx.SymFormatSub:    "Subscript",
+	zsx.SymFormatSuper:  "Superscript",
+}
+
+func (enc *encoder) encodeFormat(result []any, node *sx.Pair) []any {
+	sym, attrs, ins := zsx.GetFormat(node)
+	enc.checkAttributes(node, attrs)
+	return append(result, element(formatTags[sym], enc.inlines(ins)))
+}
+
+func (enc *encoder) encodeFormatMark(result []any, node *sx.Pair) []any {
+	_, attrs, ins := zsx.GetFormat(node)
+	spanAttr := makeAttr(zsx.GetAttributes(attrs).AddClass(classMark), false)
+	return append(result, element("Span", spanAttr, enc.inlines(ins)))
+}
+
+func (enc *encoder) encodeFormatQuote(result []any, node *sx.Pair) []any {
+	_, attrs, ins := zsx.GetFormat(node)
+	enc.checkAttributes(node, attrs)
+	return append(result, element("Quoted", element("DoubleQuote"), enc.inlines(ins)))
+}
+
+func (enc *encoder) encodeFormatSpan(result []any, node *sx.Pair) []any {
+	_, attrs, ins := zsx.GetFormat(node)
+	return append(result, element("Span", attr(attrs), enc.inlines(ins)))
+}
+
+// encodeLiteral writes code, input, and output as Pandoc code. Code with
+// the syntax "html" and no other attribute is raw HTML.
+func (enc *encoder) encodeLiteral(result []any, node *sx.Pair) []any {
+	sym, attrs, content := zsx.GetLiteral(node)
+	if sym != zsx.SymLiteralCode {
+		enc.addLossy(node)
+	} else if a := zsx.GetAttributes(attrs); len(a) == 1 && a[""] == "html" {
+		return append(result, element("RawInline", "html", content))
+	}
+	return append(result, element("Code", codeAttr(attrs), content))
+}
+
+func (enc *encoder) encodeLiteralMath(result []any, node *sx.Pair) []any {
+	_, attrs, content := zsx.GetLiteral(node)
+	enc.checkAttributes(node, attrs)
+	return append(result, element("Math", element("InlineMath"), content))
+}
@@ -0,0 +1,83 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package pandoc converts between zsx nodes and the JSON representation of
+// the Pandoc AST, as produced by "pandoc -t json" and consumed by
+// "pandoc -f json". The encoder writes version 1.23 of the Pandoc API, the
+// decoder accepts all versions since 1.21.
+//
+// The Attr of a Pandoc element is mapped to the attributes of a zsx node:
+// its identifier to the key "id", its classes to the key "class", and its
+// key-value pairs to the other keys. For code, the first class is the syntax,
+// i.e. the value of the key "". A Note becomes an ENDNOTE, a Cite becomes one
+// CITE per citation, a Span becomes a FORMAT-SPAN, and a Div becomes a
+// REGION-BLOCK. A Span with the class "mark" becomes a FORMAT-MARK, a
+// BlockQuote becomes a QUOTATION list with one item, and a Figure that
+// contains a data URL image becomes a BLOB. Plain and Para are both mapped to
+// PARA.
+//
+// Both directions report all elements that could not be converted
+// losslessly.
+package pandoc
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"t73f.de/r/zsx"
+)
+
+// apiVersion is the version of the Pandoc API written by the encoder.
+var apiVersion = []int{1, 23, 1}
+
+// Minimum version of the Pandoc API accepted by the decoder. Version 1.21
+// introduced the current table structure.
+const (
+	minMajorVersion = 1
+	minMinorVersion = 21
+)
+
+// Attribute keys that are mapped to some element of Pandoc.
+const (
+	keyClass = "class"
+	keyID    = "id"
+	keyStart = "start"
+	keyTitle = "title"
+)
+
+// classMark is the class of a span that marks its text.
+const classMark = "mark"
+
+// parseDataURL returns the syntax and the BLOB data of a base64 encoded data
+// URL of an image.
+func parseDataURL(u string) (string, string, bool) {
+	rest, found := strings.CutPrefix(u, "data:image/")
+	if !found {
+		return "", "", false
+	}
+	mime, data, found := strings.Cut(rest, ";base64,")
+	if !found || mime == "" || strings.ContainsAny(mime, ";,") {
+		return "", "", false
+	}
+	content, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", "", false
+	}
+	switch mime {
+	case "svg+xml":
+		return zsx.SyntaxSVG, string(content), true
+	case "jpeg":
+		return "jpg", data, true
+	}
+	return mime, data, true
+}
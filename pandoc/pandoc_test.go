@@ -0,0 +1,224 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package pandoc_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/markdown"
+	"t73f.de/r/zsx/pandoc"
+	"t73f.de/r/zsx/zmk"
+)
+
+func encode(t *testing.T, node *sx.Pair) (string, []*sx.Pair) {
+	t.Helper()
+	var sb strings.Builder
+	lossy, err := pandoc.Encode(&sb, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sb.String(), lossy
+}
+
+// TestRoundTrip checks that decoding the encoded tree results in the same
+// tree, and that nothing is reported as lossy.
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name   string
+		src    string
+		parser func(*input.Input) *sx.Pair
+	}{
+		{"para", "a b  c\\\nd\ne", zmk.ParseBlocks},
+		{"heading", "=== A {id=x}\n==== B", zmk.ParseBlocks},
+		{"lists", "* a\n* b\n\n# c\n## d\n\n> e", zmk.ParseBlocks},
+		{"description", "; a\n: b\n: c\n; d", zmk.ParseBlocks},
+		{"table", "|=a|=b>\n|c|:d\n|e", zmk.ParseBlocks},
+		{"div", ":::{.x}\nd\n:::", zmk.ParseBlocks},
+		{"verse", "\"\"\"\na\nb\n\nc\n\"\"\"", zmk.ParseBlocks},
+		{"verbatim", "```go\nx\n```\n\n$$$\ny\n$$$\n\n```html\n<b>\n```", zmk.ParseBlocks},
+		{"formats", "__a__ **b** >>c>> ~~d~~ ##e##{.x} ,,f,, ^^g^^ \"\"h\"\" ::i::{.y}", zmk.ParseBlocks},
+		{"literals", "``a``{=go} $$b$$", zmk.ParseBlocks},
+		{"links", "[[a|https://t73f.de]] [[b|./c]] [[d|:e]] {{f|img.png}}", zmk.ParseBlocks},
+		{"inlines", "[@key p. 7] [^a] a&nbsp;b", zmk.ParseBlocks},
+		{"markdown", "# A\n\n1. a\n2. b\n\n3. `c` <d>\n\n```\ne\n```\n\n> f\n\n[g](/h \"t\") ![i](j)", markdown.ParseBlocks},
+		{"markdown-table", "| a | b |\n|:-|--:|\n| c | d |", markdown.ParseBlocks},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := tc.parser(input.NewInput([]byte(tc.src)))
+			enc, lossy := encode(t, tree)
+			if lossy != nil {
+				t.Errorf("lossy nodes: %v", lossy)
+			}
+			got, tags, err := pandoc.Decode(strings.NewReader(enc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tags != nil {
+				t.Errorf("lossy elements: %v", tags)
+			}
+			if s1, s2 := tree.String(), got.String(); s1 != s2 {
+				t.Errorf("\njson: %s\ntree: %s\ngot:  %s", enc, s1, s2)
+			}
+		})
+	}
+}
+
+func TestRoundTripBLOB(t *testing.T) {
+	t.Parallel()
+	attrs := sx.MakeList(sx.Cons(sx.MakeString("id"), sx.MakeString("x")))
+	tree := zsx.MakeBlock(
+		zsx.MakeBLOB(attrs, "png", []byte{0, 1, 2}, sx.MakeList(zsx.MakeText("a"))),
+		zsx.MakeBLOB(nil, "jpg", []byte{3, 4}, nil),
+		zsx.MakePara(zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), sx.MakeList(zsx.MakeText("b")))),
+	)
+	enc, lossy := encode(t, tree)
+	if lossy != nil {
+		t.Errorf("lossy nodes: %v", lossy)
+	}
+	if exp := `"data:image/jpeg;base64,AwQ="`; !strings.Contains(enc, exp) {
+		t.Errorf("%s not found in %s", exp, enc)
+	}
+	got, tags, err := pandoc.Decode(strings.NewReader(enc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags != nil {
+		t.Errorf("lossy elements: %v", tags)
+	}
+	if s1, s2 := tree.String(), got.String(); s1 != s2 {
+		t.Errorf("\njson: %s\ntree: %s\ngot:  %s", enc, s1, s2)
+	}
+}
+
+func TestEncodeLossy(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name  string
+		src   string
+		lossy string
+	}{
+		{"attributes", "---{a=b}\n\n__a__{lang=de}", "THEMATIC FORMAT-EMPH"},
+		{"quotation", "> a\n>\n> b", "QUOTATION"},
+		{"region-quote", "<<<\na\n<<< b", "REGION-QUOTE"},
+		{"region-block", ":::\na\n::: b", "REGION-BLOCK"},
+		{"region-verse", "\"\"\"{a=b}\na\n\"\"\"", "REGION-VERSE"},
+		{"verbatim", "%%%\na\n%%%\n\n@@@\nb\n@@@", "VERBATIM-COMMENT VERBATIM-ZETTEL"},
+		{"literals", "''a'' ==b== %% c", "LITERAL-INPUT LITERAL-OUTPUT LITERAL-COMMENT"},
+		{"mark", "[!m|a]", "MARK"},
+		{"cite", "[@key]{a=b}", "CITE"},
+		{"embed", "{{a|img.png}}{=png}", "EMBED"},
+		{"transclude", "{{{ref}}}", "TRANSCLUDE"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := zmk.ParseBlocks(input.NewInput([]byte(tc.src)))
+			_, lossy := encode(t, tree)
+			var syms []string
+			for _, node := range lossy {
+				syms = append(syms, zsx.NodeSymbol(node).GetValue())
+			}
+			if got := strings.Join(syms, " "); got != tc.lossy {
+				t.Errorf("\nexp lossy: %q\ngot lossy: %q\ntree: %v", tc.lossy, got, tree)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	attr := `["",[],[]]`
+	para := `{"t":"Para","c":[{"t":"Str","c":"a"}]}`
+	testcases := []struct {
+		name   string
+		blocks string
+		exp    string
+		lossy  string
+	}{
+		{"plain", `{"t":"Plain","c":[{"t":"Str","c":"a"},{"t":"Space"},{"t":"Str","c":"b"}]},{"t":"Null"}`,
+			`(BLOCK (PARA (TEXT "a b")))`, ""},
+		{"line-block", `{"t":"LineBlock","c":[[{"t":"Str","c":"a"}],[{"t":"Str","c":"b"}],[],[{"t":"Str","c":"c"}]]}`,
+			`(BLOCK (REGION-VERSE () ((PARA (TEXT "a") (HARD) (TEXT "b")) (PARA (TEXT "c")))))`, ""},
+		{"code", `{"t":"CodeBlock","c":[["x",["go","y"],[["k","v"]]],"a"]}`,
+			`(BLOCK (VERBATIM-CODE (("" . "go") ("class" . "y") ("id" . "x") ("k" . "v")) "a"))`, ""},
+		{"raw", `{"t":"RawBlock","c":["latex","\\a"]}`, `(BLOCK (VERBATIM-CODE (("" . "latex")) "\\a"))`, "RawBlock"},
+		{"ordered", `{"t":"OrderedList","c":[[3,{"t":"LowerRoman"},{"t":"Period"}],[[` + para + `]]]}`,
+			`(BLOCK (ORDERED (("start" . "3")) (ITEM () (PARA (TEXT "a")))))`, "OrderedList"},
+		{"div", `{"t":"Div","c":[["",["x"],[]],[` + para + `]]}`,
+			`(BLOCK (REGION-BLOCK (("class" . "x")) ((PARA (TEXT "a")))))`, ""},
+		{"figure", `{"t":"Figure","c":[` + attr + `,[null,[` + para + `]],[` + para + `]]}`,
+			`(BLOCK (REGION-BLOCK () ((PARA (TEXT "a"))) (TEXT "a")))`, "Figure"},
+		{"table", `{"t":"Table","c":[` + attr + `,[null,[` + para + `]],[[{"t":"AlignCenter"},{"t":"ColWidthDefault"}]],[` + attr + `,[]],` +
+			`[[` + attr + `,0,[],[[` + attr + `,[[` + attr + `,{"t":"AlignDefault"},1,2,[` + para + `,` + para + `]]]]]]],[` + attr + `,[]]]}`,
+			`(BLOCK (TABLE () () (ROW () (CELL ((align . "center")) (TEXT "a") (SOFT) (TEXT "a")))))`, "Cell Cell Table"},
+		{"inlines", `{"t":"Para","c":[{"t":"SmallCaps","c":[]},{"t":"Quoted","c":[{"t":"SingleQuote"},[]]},` +
+			`{"t":"Math","c":[{"t":"DisplayMath"},"x"]},{"t":"RawInline","c":["tex","y"]},{"t":"Unknown"}]}`,
+			`(BLOCK (PARA (FORMAT-SPAN (("class" . "smallcaps"))) (FORMAT-QUOTE ()) (LITERAL-MATH () "x") (LITERAL-CODE (("" . "tex")) "y")))`,
+			"SmallCaps Quoted Math RawInline Unknown"},
+		{"cite", `{"t":"Para","c":[{"t":"Cite","c":[[{"citationId":"a","citationPrefix":[{"t":"Str","c":"see"}],"citationSuffix":[],` +
+			`"citationMode":{"t":"NormalCitation"},"citationNoteNum":1,"citationHash":0},{"citationId":"b","citationPrefix":[],` +
+			`"citationSuffix":[{"t":"Str","c":"p.7"}],"citationMode":{"t":"AuthorInText"},"citationNoteNum":1,"citationHash":0}],[]]}]}`,
+			`(BLOCK (PARA (CITE () "a") (CITE () "b" (TEXT "p.7"))))`, "Cite Cite"},
+		{"note", `{"t":"Para","c":[{"t":"Note","c":[` + para + `,` + para + `]}]}`,
+			`(BLOCK (PARA (ENDNOTE () (TEXT "a") (SOFT) (TEXT "a"))))`, "Note"},
+		{"span", `{"t":"Para","c":[{"t":"Span","c":[["",["mark","x"],[]],[]]},{"t":"Span","c":[["m",[],[]],[]]}]}`,
+			`(BLOCK (PARA (FORMAT-MARK (("class" . "x"))) (FORMAT-SPAN (("id" . "m")))))`, ""},
+		{"link", `{"t":"Para","c":[{"t":"Link","c":[` + attr + `,[],["https://t73f.de","t"]]}]}`,
+			`(BLOCK (PARA (LINK (("title" . "t")) (EXTERNAL "https://t73f.de"))))`, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := `{"pandoc-api-version":[1,23,1],"meta":{},"blocks":[` + tc.blocks + `]}`
+			got, tags, err := pandoc.Decode(strings.NewReader(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := got.String(); s != tc.exp {
+				t.Errorf("\nexp: %s\ngot: %s", tc.exp, s)
+			}
+			if s := strings.Join(tags, " "); s != tc.lossy {
+				t.Errorf("\nexp lossy: %q\ngot lossy: %q", tc.lossy, s)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+	}{
+		{"syntax", `{"blocks":`},
+		{"version", `{"pandoc-api-version":[1,20],"blocks":[]}`},
+		{"no-version", `{"blocks":[]}`},
+		{"element", `{"pandoc-api-version":[1,23],"blocks":[1]}`},
+		{"args", `{"pandoc-api-version":[1,23],"blocks":[{"t":"CodeBlock","c":["a"]}]}`},
+		{"string", `{"pandoc-api-version":[1,23],"blocks":[{"t":"Para","c":[{"t":"Str","c":1}]}]}`},
+		{"integer", `{"pandoc-api-version":[1,23],"blocks":[{"t":"Header","c":["1",["",[],[]],[]]}]}`},
+		{"citation", `{"pandoc-api-version":[1,23],"blocks":[{"t":"Para","c":[{"t":"Cite","c":[[1],[]]}]}]}`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if node, _, err := pandoc.Decode(strings.NewReader(tc.src)); err == nil {
+				t.Errorf("error expected, but got: %v", node)
+			}
+		})
+	}
+}
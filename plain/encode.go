@@ -0,0 +1,523 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package plain
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Encode writes the given node as readable plain text to the writer.
+// Typically, the node is a BLOCK or an INLINE node.
+//
+// If width is positive, paragraphs are wrapped, so that no line is longer
+// than width characters, if possible. Hard line breaks and the line breaks
+// of a verse region are kept. The content of verbatim nodes and tables is
+// never wrapped.
+//
+// Headings are underlined, list items are indented, and tables are laid out
+// in columns. Links are written together with their target. Endnotes are
+// numbered and appended to the text.
+func Encode(w io.Writer, node *sx.Pair, width int) error {
+	var enc encoder
+	var lines []string
+	switch zsx.NodeSymbol(node) {
+	case zsx.SymBlock:
+		lines = enc.encodeBlocks(zsx.GetBlock(node), width)
+	case zsx.SymInline:
+		lines = wrap(enc.inlineText(zsx.GetInline(node)), width)
+	default:
+		lines = enc.encodeBlocks(sx.MakeList(node), width)
+	}
+	lines = enc.appendEndnotes(lines, width)
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type encoder struct {
+	endnotes  []*sx.Pair // Collected endnotes
+	inVerse   bool       // Soft line breaks are kept
+	listDepth int        // Nesting level of unordered lists
+}
+
+// encodeBlocks returns the lines of all block nodes, separated by an empty
+// line.
+func (enc *encoder) encodeBlocks(blocks *sx.Pair, width int) []string {
+	var result []string
+	for elem := range blocks.Values() {
+		node, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		lines := enc.encodeBlock(node, width)
+		if len(lines) == 0 {
+			continue
+		}
+		if len(result) > 0 {
+			result = append(result, "")
+		}
+		result = append(result, lines...)
+	}
+	return result
+}
+
+// headingUnderlines contains the characters to underline a heading, by level.
+const headingUnderlines = "=-~^\"."
+
+// defaultRuleWidth is the length of a thematic break, if lines are not
+// wrapped.
+const defaultRuleWidth = 40
+
+func (enc *encoder) encodeBlock(node *sx.Pair, width int) []string {
+	switch sym := zsx.NodeSymbol(node); sym {
+	case zsx.SymPara:
+		return wrap(enc.inlineText(zsx.GetPara(node)), width)
+	case zsx.SymHeading:
+		_, level, ins := zsx.GetHeading(node)
+		lines := wrap(enc.inlineText(ins), width)
+		length := 0
+		for _, line := range lines {
+			length = max(length, utf8.RuneCountInString(line))
+		}
+		ch := headingUnderlines[min(max(level, 1), len(headingUnderlines))-1:][:1]
+		return append(lines, strings.Repeat(ch, length))
+	case zsx.SymThematic:
+		if width <= 0 {
+			width = defaultRuleWidth
+		}
+		return []string{strings.Repeat("-", width)}
+	case zsx.SymListOrdered, zsx.SymListUnordered, zsx.SymListQuote:
+		return enc.encodeList(node, width)
+	case zsx.SymDescription:
+		return enc.encodeDescription(node, width)
+	case zsx.SymTable:
+		return enc.encodeTable(node)
+	case zsx.SymRegionBlock, zsx.SymRegionQuote, zsx.SymRegionVerse:
+		return enc.encodeRegion(node, width)
+	case zsx.SymVerbatimCode, zsx.SymVerbatimEval, zsx.SymVerbatimHTML, zsx.SymVerbatimMath, zsx.SymVerbatimZettel:
+		_, _, content := zsx.GetVerbatim(node)
+		return indent(strings.Split(content, "\n"), "    ", "    ")
+	case zsx.SymTransclude:
+		_, ref, ins := zsx.GetTransclusion(node)
+		return wrap(enc.linkText(ref, ins), width)
+	case zsx.SymBLOB:
+		_, _, _, description := zsx.GetBLOBuncode(node)
+		return wrap(enc.inlineText(description), width)
+	case zsx.SymSpecialSplice:
+		return enc.encodeBlocks(node.Tail(), width)
+	}
+	return enc.encodeOther(node, width)
+}
+
+// encodeOther returns the lines of the child nodes of a custom node,
+// separated by an empty line. Unknown nodes result in no lines.
+func (enc *encoder) encodeOther(node *sx.Pair, width int) []string {
+	var result []string
+	for ctx, lst := range zsx.LayoutChildren(node) {
+		var lines []string
+		if ctx == zsx.ContextInline {
+			lines = wrap(enc.inlineText(lst), width)
+		} else {
+			lines = enc.encodeBlocks(lst, width)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if len(result) > 0 {
+			result = append(result, "")
+		}
+		result = append(result, lines...)
+	}
+	return result
+}
+
+// unorderedBullets are used for unordered lists, depending on their nesting.
+var unorderedBullets = []string{"* ", "- ", "+ "}
+
+func (enc *encoder) encodeList(node *sx.Pair, width int) []string {
+	sym, attrs, items := zsx.GetList(node)
+	var markers []string
+	switch sym {
+	case zsx.SymListOrdered:
+		start := 1
+		if val, found := zsx.GetAttributes(attrs).Get("start"); found {
+			if num, err := strconv.Atoi(val); err == nil {
+				start = num
+			}
+		}
+		for range items.Values() {
+			markers = append(markers, strconv.Itoa(start+len(markers))+". ")
+		}
+	case zsx.SymListUnordered:
+		bullet := unorderedBullets[enc.listDepth%len(unorderedBullets)]
+		for range items.Values() {
+			markers = append(markers, bullet)
+		}
+		enc.listDepth++
+		defer func() { enc.listDepth-- }()
+	default:
+		for range items.Values() {
+			markers = append(markers, "> ")
+		}
+	}
+	markerWidth := 0
+	for _, marker := range markers {
+		markerWidth = max(markerWidth, len(marker))
+	}
+
+	var result []string
+	i := 0
+	for item := range items.Values() {
+		itemNode, isPair := sx.GetPair(item)
+		if !isPair {
+			continue
+		}
+		_, elems := zsx.GetListItem(itemNode)
+		lines := enc.encodeBlocks(elems, narrow(width, markerWidth))
+		if len(lines) == 0 {
+			lines = []string{""}
+		}
+		first := markers[i] + strings.Repeat(" ", markerWidth-len(markers[i]))
+		rest := strings.Repeat(" ", markerWidth)
+		if sym == zsx.SymListQuote {
+			rest = first
+		}
+		result = append(result, indent(lines, first, rest)...)
+		i++
+	}
+	return result
+}
+
+// descriptionIndent is the indentation of the entries of a description list.
+const descriptionIndent = "    "
+
+func (enc *encoder) encodeDescription(node *sx.Pair, width int) []string {
+	_, elems := zsx.GetDescription(node)
+	var result []string
+	for elem := range elems.Values() {
+		node, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		switch zsx.NodeSymbol(node) {
+		case zsx.SymTerm:
+			if len(result) > 0 {
+				result = append(result, "")
+			}
+			_, ins := zsx.GetTerm(node)
+			result = append(result, wrap(enc.inlineText(ins), width)...)
+		case zsx.SymDetail:
+			i := 0
+			for entry := range zsx.GetDetail(node).Values() {
+				if entryNode, isEntry := sx.GetPair(entry); isEntry {
+					if i > 0 {
+						result = append(result, "")
+					}
+					_, blocks := zsx.GetEntry(entryNode)
+					lines := enc.encodeBlocks(blocks, narrow(width, len(descriptionIndent)))
+					result = append(result, indent(lines, descriptionIndent, descriptionIndent)...)
+					i++
+				}
+			}
+		}
+	}
+	return result
+}
+
+// tableCell is the text of a table cell and its alignment.
+type tableCell struct {
+	text  string
+	align string
+}
+
+func (enc *encoder) encodeTable(node *sx.Pair) []string {
+	_, header, rows := zsx.GetTable(node)
+	var cells [][]tableCell
+	if header != nil {
+		cells = append(cells, enc.tableRow(header))
+	}
+	for row := range rows.Values() {
+		if rowNode, isPair := sx.GetPair(row); isPair {
+			cells = append(cells, enc.tableRow(rowNode))
+		}
+	}
+	var widths []int
+	for _, row := range cells {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell.text))
+		}
+	}
+
+	var result []string
+	for j, row := range cells {
+		last := len(row)
+		for last > 0 && row[last-1].text == "" {
+			last--
+		}
+		fields := make([]string, last)
+		for i, cell := range row[:last] {
+			fields[i] = pad(cell.text, cell.align, widths[i])
+		}
+		result = append(result, strings.TrimRight(strings.Join(fields, " | "), " "))
+		if j == 0 && header != nil {
+			rule := make([]string, len(widths))
+			for i, w := range widths {
+				rule[i] = strings.Repeat("-", w)
+			}
+			result = append(result, strings.Join(rule, "-+-"))
+		}
+	}
+	return result
+}
+
+// tableRow returns the text of all cells of a row. Line breaks are replaced
+// by spaces.
+func (enc *encoder) tableRow(row *sx.Pair) []tableCell {
+	_, cells := zsx.GetRow(row)
+	var result []tableCell
+	for cell := range cells.Values() {
+		if cellNode, isPair := sx.GetPair(cell); isPair {
+			attrs, ins := zsx.GetCell(cellNode)
+			align, _ := zsx.GetAttributes(attrs).Get(zsx.SymAttrAlign.GetValue())
+			text := strings.Join(splitWords(strings.ReplaceAll(enc.inlineText(ins), "\n", " ")), " ")
+			text = literalRestorer.Replace(text)
+			result = append(result, tableCell{text: text, align: align})
+		}
+	}
+	return result
+}
+
+// pad fills the text with spaces up to the given width, according to the
+// alignment.
+func pad(text, align string, width int) string {
+	fill := width - utf8.RuneCountInString(text)
+	switch align {
+	case zsx.AttrAlignRight.GetValue():
+		return strings.Repeat(" ", fill) + text
+	case zsx.AttrAlignCenter.GetValue():
+		left := fill / 2
+		return strings.Repeat(" ", left) + text + strings.Repeat(" ", fill-left)
+	}
+	return text + strings.Repeat(" ", fill)
+}
+
+func (enc *encoder) encodeRegion(node *sx.Pair, width int) []string {
+	sym, _, blocks, ins := zsx.GetRegion(node)
+	var lines []string
+	switch sym {
+	case zsx.SymRegionQuote:
+		lines = indent(enc.encodeBlocks(blocks, narrow(width, 2)), "> ", "> ")
+	case zsx.SymRegionVerse:
+		inVerse := enc.inVerse
+		enc.inVerse = true
+		lines = enc.encodeBlocks(blocks, width)
+		enc.inVerse = inVerse
+	default:
+		lines = enc.encodeBlocks(blocks, width)
+	}
+	if ins != nil {
+		lines = append(lines, "")
+		lines = append(lines, indent(wrap(enc.inlineText(ins), narrow(width, 3)), "-- ", "   ")...)
+	}
+	return lines
+}
+
+// appendEndnotes adds all collected endnotes to the lines, including those
+// that are referenced within an endnote.
+func (enc *encoder) appendEndnotes(lines []string, width int) []string {
+	for i := 0; i < len(enc.endnotes); i++ {
+		_, ins := zsx.GetEndnote(enc.endnotes[i])
+		marker := "[" + strconv.Itoa(i+1) + "] "
+		note := wrap(enc.inlineText(ins), narrow(width, len(marker)))
+		if i == 0 && len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, indent(note, marker, strings.Repeat(" ", len(marker)))...)
+	}
+	enc.endnotes = nil
+	return lines
+}
+
+// narrow reduces the width by the given amount of characters. A
+// non-positive width denotes unlimited line length.
+func narrow(width, amount int) int {
+	if width <= 0 {
+		return width
+	}
+	return max(width-amount, 1)
+}
+
+// indent prefixes the first line with first and all other lines with rest.
+// Trailing spaces are removed.
+func indent(lines []string, first, rest string) []string {
+	result := make([]string, len(lines))
+	prefix := first
+	for i, line := range lines {
+		result[i] = strings.TrimRight(prefix+line, " ")
+		prefix = rest
+	}
+	return result
+}
+
+// Literal text is written with its white space replaced by these runes of the
+// private use area, so that it is neither collapsed nor broken by wrap.
+const (
+	literalSpace = "\ue000"
+	literalTab   = "\ue001"
+)
+
+var (
+	literalProtector = strings.NewReplacer(" ", literalSpace, "\t", literalTab, "\n", literalSpace)
+	literalRestorer  = strings.NewReplacer(literalSpace, " ", literalTab, "\t")
+)
+
+// wrap splits the text into lines at line endings, and wraps the lines at
+// the given width, if it is positive. Spaces between words are collapsed,
+// except within literal text.
+func wrap(text string, width int) []string {
+	if text == "" {
+		return nil
+	}
+	var lines []string
+	for para := range strings.SplitSeq(text, "\n") {
+		var sb strings.Builder
+		length := 0
+		for _, word := range splitWords(para) {
+			wordLength := utf8.RuneCountInString(word)
+			if length > 0 && width > 0 && length+1+wordLength > width {
+				lines = append(lines, literalRestorer.Replace(sb.String()))
+				sb.Reset()
+				length = 0
+			}
+			if length > 0 {
+				sb.WriteByte(' ')
+				length++
+			}
+			sb.WriteString(word)
+			length += wordLength
+		}
+		lines = append(lines, literalRestorer.Replace(sb.String()))
+	}
+	return lines
+}
+
+// splitWords splits the text at spaces and tabs. In contrast to
+// strings.Fields, non-breaking spaces do not separate words.
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(ch rune) bool { return ch == ' ' || ch == '\t' })
+}
+
+// inlineText returns the text of the inline nodes. Hard line breaks are
+// represented as line endings.
+func (enc *encoder) inlineText(ins *sx.Pair) string {
+	v := inlineVisitor{enc: enc}
+	zsx.WalkItList(&v, ins, 0, nil)
+	return v.sb.String()
+}
+
+// linkText returns the text of a link and its target. If there is no text,
+// or if the text is the same as the target, only the target is returned.
+func (enc *encoder) linkText(ref *sx.Pair, ins *sx.Pair) string {
+	sym, val := zsx.GetReference(ref)
+	text := enc.inlineText(ins)
+	switch {
+	case sym == zsx.SymRefStateInvalid || val == "" || text == val:
+		return text
+	case text == "":
+		return val
+	}
+	return text + " (" + val + ")"
+}
+
+type inlineVisitor struct {
+	enc *encoder
+	sb  strings.Builder
+}
+
+func (v *inlineVisitor) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	switch sym := zsx.NodeSymbol(node); sym {
+	case zsx.SymText:
+		v.sb.WriteString(zsx.GetText(node))
+	case zsx.SymSoft:
+		if v.enc.inVerse {
+			v.sb.WriteByte('\n')
+		} else {
+			v.sb.WriteByte(' ')
+		}
+	case zsx.SymHard:
+		v.sb.WriteByte('\n')
+	case zsx.SymLink:
+		_, ref, ins := zsx.GetLink(node)
+		v.sb.WriteString(v.enc.linkText(ref, ins))
+	case zsx.SymEmbed:
+		_, ref, _, ins := zsx.GetEmbed(node)
+		text := v.enc.inlineText(ins)
+		if text == "" {
+			_, text = zsx.GetReference(ref)
+		}
+		v.sb.WriteString(text)
+	case zsx.SymEmbedBLOB:
+		_, _, _, ins := zsx.GetEmbedBLOBuncode(node)
+		v.sb.WriteString(v.enc.inlineText(ins))
+	case zsx.SymCite:
+		_, key, ins := zsx.GetCite(node)
+		v.sb.WriteString("[" + key)
+		if text := v.enc.inlineText(ins); text != "" {
+			v.sb.WriteString(", " + text)
+		}
+		v.sb.WriteByte(']')
+	case zsx.SymEndnote:
+		v.enc.endnotes = append(v.enc.endnotes, node)
+		v.sb.WriteString("[" + strconv.Itoa(len(v.enc.endnotes)) + "]")
+	case zsx.SymLiteralCode, zsx.SymLiteralInput, zsx.SymLiteralMath, zsx.SymLiteralOutput:
+		_, _, text := zsx.GetLiteral(node)
+		v.sb.WriteString(literalProtector.Replace(text))
+	case zsx.SymFormatQuote:
+		v.sb.WriteByte('"')
+		return false
+	case zsx.SymSpecialSplice:
+		zsx.WalkItList(v, node, 1, alst)
+		return true
+	case zsx.SymInline, zsx.SymMark,
+		zsx.SymFormatDelete, zsx.SymFormatEmph, zsx.SymFormatInsert, zsx.SymFormatMark,
+		zsx.SymFormatSpan, zsx.SymFormatStrong, zsx.SymFormatSub, zsx.SymFormatSuper:
+		return false
+	case zsx.SymLiteralComment:
+		// Comments are not written.
+	default:
+		// Of other nodes, e.g. custom nodes, the child nodes are written.
+		if _, found := zsx.GetLayout(sym); found {
+			return false
+		}
+	}
+	return true // Leaf nodes, or nodes that are not written at all
+}
+
+func (v *inlineVisitor) VisitItAfter(node *sx.Pair, _ *sx.Pair) {
+	if zsx.NodeSymbol(node) == zsx.SymFormatQuote {
+		v.sb.WriteByte('"')
+	}
+}
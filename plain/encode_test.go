@@ -0,0 +1,128 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package plain_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/internal/layouttest"
+	"t73f.de/r/zsx/plain"
+	"t73f.de/r/zsx/zmk"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name  string
+		src   string
+		width int
+		exp   string
+	}{
+		{"empty", "", 0, ""},
+		{"para", "a\nb\n\nc", 0, "a b\n\nc\n"},
+		{"wrap", "aaa bbb ccc ddd eee", 10, "aaa bbb\nccc ddd\neee\n"},
+		{"wrap-long-word", "a bbbbbbbbbbbb c", 5, "a\nbbbbbbbbbbbb\nc\n"},
+		{"wrap-hard", "aaa bbb\\\nccc", 20, "aaa bbb\nccc\n"},
+		{"no-wrap", "aaa bbb ccc ddd eee", 0, "aaa bbb ccc ddd eee\n"},
+		{"heading", "=== Title\n==== Sub __title__", 0, "Title\n=====\n\nSub title\n---------\n"},
+		{"thematic", "---", 0, strings.Repeat("-", 40) + "\n"},
+		{"thematic-width", "---", 5, "-----\n"},
+		{"unordered", "* a\n** b\n*** c\n* d", 0, "* a\n\n  - b\n\n    + c\n* d\n"},
+		{"ordered", "# a\n# b", 0, "1. a\n2. b\n"},
+		{"ordered-wide", "# a\n# b\n# c\n# d\n# e\n# f\n# g\n# h\n# i\n# j", 0,
+			"1.  a\n2.  b\n3.  c\n4.  d\n5.  e\n6.  f\n7.  g\n8.  h\n9.  i\n10. j\n"},
+		{"list-wrap", "* aaa bbb ccc", 6, "* aaa\n  bbb\n  ccc\n"},
+		{"quote-list", "> aaa bbb", 6, "> aaa\n> bbb\n"},
+		{"description", "; t\n: d1\n: d2\n; u", 0, "t\n    d1\n\n    d2\n\nu\n"},
+		{"table", "|=a|=bb>|=c:\n|ccc|d|e\n|f", 0,
+			"a   | bb | c\n----+----+--\nccc |  d | e\nf\n"},
+		{"table-no-header", "|a|bbbbbbbbbbbb", 5, "a | bbbbbbbbbbbb\n"},
+		{"region-quote", "<<<\naaa bbb\n<<< ccc", 6, "> aaa\n> bbb\n\n-- ccc\n"},
+		{"region-verse", "\"\"\"\naaa  bbb\nccc ddd\n\"\"\"", 5, "aaa\nbbb\nccc\nddd\n"},
+		{"region-verse-nowrap", "\"\"\"\naaa bbb\nccc\n\"\"\"", 0, "aaa bbb\nccc\n"},
+		{"verbatim", "```\nlong line  of code\n\n  x\n```", 5, "    long line  of code\n\n      x\n"},
+		{"comment", "%%%\nno\n%%%\n\na %% no", 0, "a\n"},
+		{"link", "[[a|https://t73f.de]] [[https://zettelstore.de]] [[c|c]]", 0,
+			"a (https://t73f.de) https://zettelstore.de c\n"},
+		{"embed", "{{alt|img.png}} {{img.png}}", 0, "alt img.png\n"},
+		{"formats", "__a__ **b** \"\"c\"\" ``d`` [@k e]", 0, "a b \"c\" d [k, e]\n"},
+		{"endnote", "a[^b] c[^d[^e]]", 0, "a[1] c[2]\n\n[1] b\n[2] d[3]\n[3] e\n"},
+		{"endnote-wrap", "a[^bbb ccc]", 7, "a[1]\n\n[1] bbb\n    ccc\n"},
+		{"transclude", "{{{ref}}}", 0, "ref\n"},
+		{"literal-wrap", "a ``b  c`` d", 5, "a\nb  c\nd\n"},
+		{"literal-table", "|''x  y''|z", 0, "x  y | z\n"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := zmk.ParseBlocks(input.NewInput([]byte(tc.src)))
+			var sb strings.Builder
+			if err := plain.Encode(&sb, tree, tc.width); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %q\ngot: %q", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestEncodeInline(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeInline(zsx.MakeText("a"), zsx.MakeSoft(), zsx.MakeText("b\u00a0c"), zsx.MakeHard(), zsx.MakeText("d"))
+	var sb strings.Builder
+	if err := plain.Encode(&sb, node, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "a\nb\u00a0c\nd\n"; got != exp {
+		t.Errorf("\nnode: %v\nexp:  %q\ngot:  %q", node, exp, got)
+	}
+	sb.Reset()
+	splice := sx.MakeList(zsx.SymSpecialSplice, zsx.MakeText("b"), zsx.MakeSoft(), zsx.MakeText("c"))
+	if err := plain.Encode(&sb, zsx.MakeInline(zsx.MakeText("a "), splice), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "a b c\n"; got != exp {
+		t.Errorf("exp: %q, got: %q", exp, got)
+	}
+	sb.Reset()
+	if err := plain.Encode(&sb, zsx.MakeBlock(zsx.MakeBLOB(nil, "png", []byte("x"), sx.MakeList(zsx.MakeText("pic")))), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "pic\n"; got != exp {
+		t.Errorf("exp: %q, got: %q", exp, got)
+	}
+}
+
+func TestEncodeCustom(t *testing.T) {
+	t.Parallel()
+	layouttest.Register(t)
+	text := zsx.MakeText
+	node := zsx.MakeBlock(
+		layouttest.MakeAside(sx.MakeList(text("t")), zsx.MakePara(text("s")), zsx.MakePara(text("a")), zsx.MakePara(text("b"))),
+		zsx.MakePara(text("x "), layouttest.MakeBadge(text("i"), zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text("c"))))),
+		zsx.MakePara(text("y"), sx.MakeList(sx.MakeSymbol("UNKNOWN"), text("z"))),
+		sx.MakeList(sx.MakeSymbol("UNKNOWN"), zsx.MakePara(text("z"))),
+	)
+	var sb strings.Builder
+	if err := plain.Encode(&sb, node, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "t\n\ns\n\na\n\nb\n\nx ic\n\ny\n"; got != exp {
+		t.Errorf("\nexp: %q\ngot: %q", exp, got)
+	}
+}
@@ -0,0 +1,116 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package plain provides a parser for plain text. It is the reference for
+// representing content without any markup as zsx nodes.
+//
+// Lines that contain only whitespace separate paragraphs. Each paragraph is
+// a PARA node, where each line is a TEXT node without leading and trailing
+// whitespace. Line endings within a paragraph are represented according to a
+// BreakPolicy.
+//
+// The encoder flattens zsx nodes to readable plain text, wrapping paragraphs
+// at a given width.
+package plain
+
+import (
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// BreakPolicy specifies how line endings within a paragraph are represented.
+type BreakPolicy uint8
+
+// Constants for BreakPolicy.
+const (
+	// SoftBreaks represents every line ending as a SOFT node.
+	SoftBreaks BreakPolicy = iota
+
+	// HardBreaks represents every line ending as a HARD node.
+	HardBreaks
+
+	// SpaceBreaks represents a line ending as a HARD node, if the line ends
+	// with at least two whitespace characters. Otherwise it is a SOFT node.
+	SpaceBreaks
+)
+
+// ParseBlocks parses the remaining input as plain text and returns a BLOCK
+// node.
+func ParseBlocks(inp *input.Input, policy BreakPolicy) *sx.Pair {
+	var lb sx.ListBuilder
+	for {
+		if para := parsePara(inp, policy); para != nil {
+			lb.Add(para)
+		}
+		if inp.Ch == input.EOS {
+			break
+		}
+	}
+	return zsx.MakeBlockList(lb.List())
+}
+
+// parsePara parses lines until a blank line or the end of input is found.
+// If there are no such lines, nil is returned.
+func parsePara(inp *input.Input, policy BreakPolicy) *sx.Pair {
+	var lb sx.ListBuilder
+	var lineBreak *sx.Pair
+	for {
+		inp.SkipSpace()
+		if input.IsEOLEOS(inp.Ch) {
+			inp.EatEOL()
+			break
+		}
+		text, spaces := scanLine(inp)
+		if lineBreak != nil {
+			lb.Add(lineBreak)
+		}
+		lb.Add(zsx.MakeText(text))
+		lineBreak = makeBreak(policy, spaces)
+		inp.EatEOL()
+	}
+	if ins := lb.List(); ins != nil {
+		return zsx.MakeParaList(ins)
+	}
+	return nil
+}
+
+// scanLine reads the rest of the line. It returns the line without trailing
+// whitespace, and the number of trailing whitespace characters.
+func scanLine(inp *input.Input) (string, int) {
+	start, end := inp.Pos, inp.Pos
+	spaces := 0
+	for !input.IsEOLEOS(inp.Ch) {
+		if inp.IsSpace() {
+			spaces++
+			inp.Next()
+		} else {
+			spaces = 0
+			inp.Next()
+			end = inp.Pos
+		}
+	}
+	return string(inp.Src[start:end]), spaces
+}
+
+func makeBreak(policy BreakPolicy, spaces int) *sx.Pair {
+	switch policy {
+	case HardBreaks:
+		return zsx.MakeHard()
+	case SpaceBreaks:
+		if spaces >= 2 {
+			return zsx.MakeHard()
+		}
+	}
+	return zsx.MakeSoft()
+}
@@ -0,0 +1,50 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package plain_test
+
+import (
+	"testing"
+
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/plain"
+)
+
+func TestParseBlocks(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name   string
+		src    string
+		policy plain.BreakPolicy
+		exp    string
+	}{
+		{"empty", "", plain.SoftBreaks, "(BLOCK)"},
+		{"blank", " \n\t\n", plain.SoftBreaks, "(BLOCK)"},
+		{"line", "  a  b \t", plain.SoftBreaks, `(BLOCK (PARA (TEXT "a  b")))`},
+		{"paras", "a\n \nb\r\n\r\n\r\nc\n", plain.SoftBreaks,
+			`(BLOCK (PARA (TEXT "a")) (PARA (TEXT "b")) (PARA (TEXT "c")))`},
+		{"soft", "a\r\nb\rc", plain.SoftBreaks, `(BLOCK (PARA (TEXT "a") (SOFT) (TEXT "b") (SOFT) (TEXT "c")))`},
+		{"hard", "a\nb\n", plain.HardBreaks, `(BLOCK (PARA (TEXT "a") (HARD) (TEXT "b")))`},
+		{"space", "a  \nb \nc  ", plain.SpaceBreaks,
+			`(BLOCK (PARA (TEXT "a") (HARD) (TEXT "b") (SOFT) (TEXT "c")))`},
+		{"no-markup", "**a** [[b]]", plain.SoftBreaks, `(BLOCK (PARA (TEXT "**a** [[b]]")))`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := plain.ParseBlocks(input.NewInput([]byte(tc.src)), tc.policy).String()
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %s\ngot: %s", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parser parses the source of a query.
+type parser struct {
+	src string
+	pos int
+}
+
+// errorf returns an error that describes a syntax error at the current
+// position.
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("query %q: position %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace skips white space and returns true, if some space was skipped.
+func (p *parser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *parser) parse() ([]selector, error) {
+	var result []selector
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sel)
+		if p.eof() {
+			return result, nil
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("unexpected character %q", p.peek())
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseSelector() (selector, error) {
+	var sel selector
+	comb := combDescendant
+	for {
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		c.comb = comb
+		sel = append(sel, c)
+
+		hasSpace := p.skipSpace()
+		switch ch := p.peek(); {
+		case ch == 0 || ch == ',':
+			return sel, nil
+		case ch == '>':
+			p.pos++
+			p.skipSpace()
+			comb = combChild
+		case hasSpace:
+			comb = combDescendant
+		default:
+			return nil, p.errorf("unexpected character %q", ch)
+		}
+	}
+}
+
+func (p *parser) parseCompound() (compound, error) {
+	var c compound
+	if p.peek() == '*' {
+		p.pos++
+		c.sym = "*"
+	} else if isIdent(p.peek()) {
+		c.sym = p.scanIdent()
+	}
+	for {
+		var f filter
+		var err error
+		switch p.peek() {
+		case '.':
+			p.pos++
+			f, err = p.parseClass()
+		case '[':
+			p.pos++
+			f, err = p.parseAttr()
+		case ':':
+			p.pos++
+			f, err = p.parsePseudo()
+		default:
+			if c.sym == "" && len(c.filters) == 0 {
+				if p.eof() {
+					return c, p.errorf("selector expected")
+				}
+				return c, p.errorf("unexpected character %q", p.peek())
+			}
+			if c.sym == "*" {
+				c.sym = ""
+			}
+			return c, nil
+		}
+		if err != nil {
+			return c, err
+		}
+		c.filters = append(c.filters, f)
+	}
+}
+
+func (p *parser) parseClass() (filter, error) {
+	if !isIdent(p.peek()) {
+		return nil, p.errorf("class name expected")
+	}
+	return &attrFilter{op: opContains, key: "class", value: p.scanIdent()}, nil
+}
+
+func (p *parser) parseAttr() (filter, error) {
+	p.skipSpace()
+	key, err := p.scanValue("attribute key")
+	if err != nil {
+		return nil, err
+	}
+	f := attrFilter{op: opExists, key: key}
+	p.skipSpace()
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "="):
+		f.op = opEqual
+		p.pos++
+	case strings.HasPrefix(p.src[p.pos:], "~="):
+		f.op = opContains
+		p.pos += 2
+	}
+	if f.op != opExists {
+		p.skipSpace()
+		if f.value, err = p.scanValue("attribute value"); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+	}
+	if p.peek() != ']' {
+		return nil, p.errorf("missing ']'")
+	}
+	p.pos++
+	return &f, nil
+}
+
+func (p *parser) parsePseudo() (filter, error) {
+	if name := p.scanIdent(); name != "level" {
+		return nil, p.errorf("unknown pseudo class %q", name)
+	}
+	if p.peek() != '(' {
+		return nil, p.errorf("missing '('")
+	}
+	p.pos++
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && '0' <= p.src[p.pos] && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	level, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil || level <= 0 {
+		return nil, p.errorf("positive level expected")
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf("missing ')'")
+	}
+	p.pos++
+	return &levelFilter{level: level}, nil
+}
+
+// scanValue scans an identifier or a quoted string.
+func (p *parser) scanValue(what string) (string, error) {
+	if isIdent(p.peek()) {
+		return p.scanIdent(), nil
+	}
+	if p.peek() != '"' {
+		return "", p.errorf("%s expected", what)
+	}
+	p.pos++
+	var sb strings.Builder
+	for !p.eof() {
+		ch := p.src[p.pos]
+		p.pos++
+		switch ch {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if p.eof() {
+				break
+			}
+			ch = p.src[p.pos]
+			p.pos++
+		}
+		sb.WriteByte(ch)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) scanIdent() string {
+	start := p.pos
+	for !p.eof() && isIdent(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func isIdent(ch byte) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9' ||
+		ch == '-' || ch == '_' || ch >= 0x80
+}
+
+func isSpace(ch byte) bool { return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' }
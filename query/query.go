@@ -0,0 +1,263 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package query provides a selector language to find nodes of a zsx tree.
+//
+// A query is similar to a CSS selector. It consists of one or more selectors,
+// separated by a comma. A node is selected, if one of the selectors matches.
+//
+// A selector is a sequence of compound selectors, separated by a combinator.
+// White space is the descendant combinator: "TABLE EMBED" selects all EMBED
+// nodes that are inside a TABLE node. The character ">" is the child
+// combinator: "ITEM > PARA" selects all PARA nodes whose parent is an ITEM
+// node.
+//
+// A compound selector is a node symbol, like "HEADING", or "*" for every node
+// symbol, followed by some filters. The node symbol may be omitted, if at
+// least one filter is given. Node symbols are not case sensitive. Filters
+// are:
+//
+//   - "[key]" matches a node that has an attribute with the given key.
+//   - "[key=value]" matches a node, where the attribute has the given value.
+//   - "[key~=value]" matches a node, where the attribute value is a list of
+//     space separated words, and one of these words is the given value.
+//   - ".value" is a shortcut for "[class~=value]".
+//   - ":level(n)" matches a node with the level n, e.g. a heading.
+//
+// Keys and values are either identifiers, i.e. a sequence of letters, digits,
+// "-", and "_", or they are quoted with '"'.
+//
+// For example, "HEADING:level(2).todo" selects all headings of level 2 with
+// the class "todo".
+package query
+
+import (
+	"iter"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Query is a compiled query.
+type Query struct {
+	src       string
+	selectors []selector
+}
+
+// Compile parses the query string and returns the compiled query.
+func Compile(s string) (*Query, error) {
+	p := parser{src: s}
+	selectors, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{src: s, selectors: selectors}, nil
+}
+
+// MustCompile is like [Compile], but panics on an error. It simplifies
+// the initialization of global variables.
+func MustCompile(s string) *Query {
+	q, err := Compile(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// String returns the source of the query.
+func (q *Query) String() string { return q.src }
+
+// Match returns true, if the node is selected by the query. The association
+// list is the one that is given while walking the tree, see
+// [zsx.GetWalkParents]. It is needed to match the ancestors of the node.
+func (q *Query) Match(node *sx.Pair, alst *sx.Pair) bool {
+	parents := zsx.GetWalkParents(alst)
+	for _, sel := range q.selectors {
+		if sel.match(node, parents) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns an iterator over all nodes of the tree that are selected by the
+// query, in pre-order. Besides the node, the association list of the walk
+// is yielded, as in [zsx.All].
+func (q *Query) All(root *sx.Pair) iter.Seq2[*sx.Pair, *sx.Pair] {
+	return func(yield func(*sx.Pair, *sx.Pair) bool) {
+		for node, alst := range zsx.All(root) {
+			if q.Match(node, alst) && !yield(node, alst) {
+				return
+			}
+		}
+	}
+}
+
+// Find returns all nodes of the tree that are selected by the query.
+func (q *Query) Find(root *sx.Pair) []*sx.Pair {
+	var result []*sx.Pair
+	for node := range q.All(root) {
+		result = append(result, node)
+	}
+	return result
+}
+
+// First returns the first node of the tree that is selected by the query, or
+// nil, if no node is selected.
+func (q *Query) First(root *sx.Pair) *sx.Pair {
+	for node := range q.All(root) {
+		return node
+	}
+	return nil
+}
+
+// selector is a sequence of compound selectors. The combinator of compounds[i]
+// specifies the relation to compounds[i-1].
+type selector []compound
+
+// match the last compound against the node, and the previous ones against
+// its ancestors.
+func (sel selector) match(node *sx.Pair, parents *sx.Pair) bool {
+	last := len(sel) - 1
+	if !sel[last].match(node) {
+		return false
+	}
+	return sel.matchAncestors(last, parents)
+}
+
+// matchAncestors checks, whether the compounds before sel[i] match the
+// ancestors, according to the combinator of sel[i].
+func (sel selector) matchAncestors(i int, parents *sx.Pair) bool {
+	if i == 0 {
+		return true
+	}
+	for p := range parents.Pairs() {
+		if node, isPair := sx.GetPair(p.Car()); isPair && sel[i-1].match(node) {
+			if sel.matchAncestors(i-1, p.Tail()) {
+				return true
+			}
+		}
+		if sel[i].comb == combChild {
+			return false
+		}
+	}
+	return false
+}
+
+// combinator specifies the relation between two compound selectors.
+type combinator uint8
+
+const (
+	combDescendant combinator = iota
+	combChild
+)
+
+// compound matches a single node.
+type compound struct {
+	comb    combinator
+	sym     string // node symbol, compared case-insensitively, or empty to match all symbols
+	filters []filter
+}
+
+func (c *compound) match(node *sx.Pair) bool {
+	sym := zsx.NodeSymbol(node)
+	if sym == nil || (c.sym != "" && !strings.EqualFold(c.sym, sym.GetValue())) {
+		return false
+	}
+	for _, f := range c.filters {
+		if !f.match(node, sym) {
+			return false
+		}
+	}
+	return true
+}
+
+type filter interface {
+	match(node *sx.Pair, sym *sx.Symbol) bool
+}
+
+// attrFilter matches an attribute.
+type attrFilter struct {
+	op    attrOp
+	key   string
+	value string
+}
+
+type attrOp uint8
+
+const (
+	opExists   attrOp = iota // [key]
+	opEqual                  // [key=value]
+	opContains               // [key~=value]
+)
+
+func (f *attrFilter) match(node *sx.Pair, sym *sx.Symbol) bool {
+	obj, found := getElement(node, sym, zsx.ElementAttrs)
+	if !found {
+		return false
+	}
+	attrs, isPair := sx.GetPair(obj)
+	if !isPair {
+		return false
+	}
+	a := zsx.GetAttributes(attrs)
+	switch f.op {
+	case opEqual:
+		val, found := a.Get(f.key)
+		return found && val == f.value
+	case opContains:
+		return a.Has(f.key, f.value)
+	default:
+		_, found := a.Get(f.key)
+		return found
+	}
+}
+
+// levelFilter matches the level of a node.
+type levelFilter struct {
+	level int
+}
+
+func (f *levelFilter) match(node *sx.Pair, sym *sx.Symbol) bool {
+	obj, found := getElement(node, sym, zsx.ElementInt)
+	if !found {
+		return false
+	}
+	if num, isNumber := sx.GetNumber(obj); isNumber {
+		if level, isInt := num.(sx.Int64); isInt {
+			return int(level) == f.level
+		}
+	}
+	return false
+}
+
+// getElement returns the first element of the node with the given kind, as
+// specified by the layout of the node.
+func getElement(node *sx.Pair, sym *sx.Symbol, kind zsx.ElementKind) (sx.Object, bool) {
+	layout, found := zsx.GetLayout(sym)
+	if !found {
+		return nil, false
+	}
+	elems := node.Tail()
+	for _, elem := range layout.Elements {
+		if elem.Kind == kind {
+			return elems.Car(), elems != nil
+		}
+		if elem.Kind == zsx.ElementRest {
+			break
+		}
+		elems = elems.Tail()
+	}
+	return nil, false
+}
@@ -0,0 +1,160 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package query_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/query"
+	"t73f.de/r/zsx/zmk"
+)
+
+const querySrc = `=== A {.todo}
+==== B {class=todo}
+==== C
+|{{x.png}}|b
+|c|{{y.png}}
+
+* {{z.png}} and [[l|x]]
+* ''d''
+
+{{w.png}}`
+
+func nodesString(nodes []*sx.Pair) string {
+	var result []string
+	for _, node := range nodes {
+		result = append(result, node.String())
+	}
+	return strings.Join(result, " ")
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+	const (
+		headingA = `(HEADING (("class" . "todo")) 1 (TEXT "A"))`
+		headingB = `(HEADING (("class" . "todo")) 2 (TEXT "B"))`
+		headingC = `(HEADING () 2 (TEXT "C"))`
+		embedX   = `(EMBED () (HOSTED "x.png") "")`
+		embedY   = `(EMBED () (HOSTED "y.png") "")`
+		embedZ   = `(EMBED () (HOSTED "z.png") "")`
+		embedW   = `(EMBED () (HOSTED "w.png") "")`
+	)
+	testcases := []struct {
+		query string
+		exp   string
+	}{
+		{"TABLE EMBED", embedX + " " + embedY},
+		{"table  embed", embedX + " " + embedY},
+		{"HEADING:level(2).todo", headingB},
+		{"HEADING.todo", headingA + " " + headingB},
+		{"HEADING:level( 2 )", headingB + " " + headingC},
+		{"[class=todo]", headingA + " " + headingB},
+		{`*[ class ~= "todo" ]`, headingA + " " + headingB},
+		{"[class]", headingA + " " + headingB},
+		{"[id]", ""},
+		{"ITEM > PARA > EMBED", embedZ},
+		{"ITEM>EMBED", ""},
+		{"ITEM EMBED", embedZ},
+		{"BLOCK > PARA > EMBED", embedW},
+		{"UNORDERED LITERAL-INPUT", `(LITERAL-INPUT () "d")`},
+		{"LINK, HEADING:level(1)", headingA + ` (LINK () (HOSTED "x") (TEXT "l"))`},
+	}
+	root := zmk.ParseBlocks(input.NewInput([]byte(querySrc)))
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+			q, err := query.Compile(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := nodesString(q.Find(root)); got != tc.exp {
+				t.Errorf("expected:\n%s\nbut got:\n%s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	t.Parallel()
+	root := zmk.ParseBlocks(input.NewInput([]byte(querySrc)))
+	q := query.MustCompile("*")
+	if got := q.First(root); got != root {
+		t.Errorf("root expected, but got %v", got)
+	}
+	count := 0
+	for node, alst := range q.All(root) {
+		if !q.Match(node, alst) {
+			t.Errorf("node %v does not match", node)
+		}
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("expected 3 nodes, but got %d", count)
+	}
+	if got := query.MustCompile("CITE").First(root); got != nil {
+		t.Errorf("nil expected, but got %v", got)
+	}
+}
+
+func TestQuerySplice(t *testing.T) {
+	t.Parallel()
+	root := zsx.MakePara(
+		zsx.MakeText("a"),
+		sx.MakeList(zsx.SymSpecialSplice, zsx.MakeText("b"), zsx.MakeText("c")),
+		zsx.MakeText("d"),
+	)
+	var got []string
+	for node, alst := range query.MustCompile("PARA > TEXT").All(root) {
+		got = append(got, fmt.Sprintf("%s@%d", zsx.GetText(node), zsx.GetWalkPos(alst)))
+	}
+	if exp := "a@0 b@1 c@2 d@3"; strings.Join(got, " ") != exp {
+		t.Errorf("exp=%q, got=%q", exp, got)
+	}
+}
+
+func TestQueryCustomSymbol(t *testing.T) {
+	t.Parallel()
+	note := sx.MakeList(sx.MakeSymbol("Side-Note"))
+	root := zsx.MakePara(zsx.MakeText("a"), note)
+	for _, src := range []string{"Side-Note", "side-note", "SIDE-NOTE", "PARA > side-Note"} {
+		if got := query.MustCompile(src).First(root); got != note {
+			t.Errorf("%q: exp=%v, got=%v", src, note, got)
+		}
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	t.Parallel()
+	testcases := []string{
+		"", " ", "HEADING,", "HEADING >", "> PARA", "HEADING)",
+		".", "[class", "[=x]", "[class=]", `[class="x`, "[class^=x]",
+		":level(0)", ":level(x)", ":level(1", ":level", ":first(1)",
+	}
+	for _, tc := range testcases {
+		t.Run(tc, func(t *testing.T) {
+			t.Parallel()
+			if q, err := query.Compile(tc); err == nil {
+				t.Errorf("error expected, but got %v", q)
+			}
+		})
+	}
+}
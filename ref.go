@@ -0,0 +1,54 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx
+
+import (
+	"net/url"
+	"strings"
+
+	"t73f.de/r/sx"
+)
+
+// ParseReference classifies the given string and returns a reference node
+// with the appropriate reference state, see [ReferenceState].
+func ParseReference(s string) *sx.Pair {
+	return MakeReference(ReferenceState(s), s)
+}
+
+// ReferenceState returns the symbol of the reference state of the given
+// reference value.
+//
+// An empty value or a value that is not an URL is invalid. A value that
+// refers to the current document, like "." or "#frag", is a self reference.
+// A value with a scheme, like "https:", "mailto:", or "query:", and a network
+// path reference, like "//t73f.de", are external. All other values are
+// relative URLs and therefore hosted, including values without any path
+// prefix, like a zettel identifier "20260101120000". Applications that give
+// such values a special meaning must classify them on their own.
+func ReferenceState(s string) *sx.Symbol {
+	if s == "" {
+		return SymRefStateInvalid
+	}
+	if s == "." || strings.HasPrefix(s, "#") || strings.HasPrefix(s, ".#") {
+		return SymRefStateSelf
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return SymRefStateInvalid
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return SymRefStateExternal
+	}
+	return SymRefStateHosted
+}
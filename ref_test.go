@@ -0,0 +1,60 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx_test
+
+import (
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+func TestReferenceState(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		ref string
+		exp *sx.Symbol
+	}{
+		{"", zsx.SymRefStateInvalid},
+		{":t73f.de/r/zsx", zsx.SymRefStateInvalid},
+		{"a\x7fb", zsx.SymRefStateInvalid},
+		{"https://t73f.de/links/software", zsx.SymRefStateExternal},
+		{"mailto:ds@example.com", zsx.SymRefStateExternal},
+		{"query:tags:zsx", zsx.SymRefStateExternal},
+		{"//t73f.de/r/zsx", zsx.SymRefStateExternal},
+		{"./foo", zsx.SymRefStateHosted},
+		{"../foo", zsx.SymRefStateHosted},
+		{"/foo/bar", zsx.SymRefStateHosted},
+		{"foo", zsx.SymRefStateHosted},
+		{"foo/bar.png", zsx.SymRefStateHosted},
+		{"20260101120000", zsx.SymRefStateHosted},
+		{"20260101120000#frag", zsx.SymRefStateHosted},
+		{"?q=zsx", zsx.SymRefStateHosted},
+		{".", zsx.SymRefStateSelf},
+		{"#", zsx.SymRefStateSelf},
+		{".#ext", zsx.SymRefStateSelf},
+		{"#ext", zsx.SymRefStateSelf},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.ref, func(t *testing.T) {
+			if got := zsx.ReferenceState(tc.ref); got != tc.exp {
+				t.Errorf("ReferenceState(%q) should be %v, but got %v", tc.ref, tc.exp, got)
+			}
+			ref := zsx.ParseReference(tc.ref)
+			if sym, val := zsx.GetReference(ref); sym != tc.exp || val != tc.ref {
+				t.Errorf("ParseReference(%q) should be (%v %q), but got %v", tc.ref, tc.exp, tc.ref, ref)
+			}
+		})
+	}
+}
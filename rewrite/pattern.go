@@ -0,0 +1,341 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Special symbols of patterns and templates.
+const (
+	varPrefix  = "?"      // Prefix of a variable
+	restSuffix = "..."    // Suffix of a rest variable
+	formAttrs  = "?attrs" // Pattern form to match attributes
+	formText   = "?text"  // Template form to retrieve text
+)
+
+// NewRule creates a rule from a pattern and a template, which are sx
+// expressions.
+//
+// A pattern is matched against a node:
+//
+//   - The symbol "?" matches everything.
+//   - A symbol that starts with "?" is a variable. It matches everything,
+//     and binds the matched object to the variable. If the variable is
+//     already bound, the object must be equal to the bound object.
+//   - Within a list, a variable that ends with "..." matches the rest of
+//     the list, e.g. "?inlines...". It must be the last element of the list.
+//   - The form "(?attrs VAR (KEY VALUE) ...)" matches an attribute list that
+//     contains all given keys, whose values match the given patterns. The
+//     optional variable is bound to the whole attribute list. If the key is
+//     "class" and the value is a string, the attribute list must contain the
+//     string as one of its classes.
+//   - Other lists match element-wise, and all other objects match equal
+//     objects.
+//
+// A template builds the replacement. All variables are replaced by their
+// bound objects, a rest variable is spliced into the enclosing list. The form
+// "(?text TEMPLATE)" results in a string that contains the text of all nodes
+// of the list built by the template.
+//
+// For example, the following pattern and template replace a span with the
+// class "kbd" by an input literal:
+//
+//	(FORMAT-SPAN (?attrs (class "kbd")) ?inlines...)
+//	(LITERAL-INPUT () (?text (?inlines...)))
+func NewRule(pattern, template sx.Object) (Rule, error) {
+	vars := map[string]bool{}
+	if err := checkPattern(pattern, vars); err != nil {
+		return nil, fmt.Errorf("pattern %v: %w", pattern, err)
+	}
+	if err := checkTemplate(template, vars); err != nil {
+		return nil, fmt.Errorf("template %v: %w", template, err)
+	}
+	return &patternRule{pattern: pattern, template: template}, nil
+}
+
+// ParseRule creates a rule from an sx expression "(PATTERN TEMPLATE)", e.g.
+// as read from a file.
+func ParseRule(obj sx.Object) (Rule, error) {
+	lst, isPair := sx.GetPair(obj)
+	if !isPair || lst.Length() != 2 {
+		return nil, fmt.Errorf("rule must be a list of a pattern and a template: %v", obj)
+	}
+	return NewRule(lst.Car(), lst.Tail().Car())
+}
+
+// ParseRules creates rules from a list of sx expressions, see [ParseRule].
+func ParseRules(lst *sx.Pair) ([]Rule, error) {
+	var rules []Rule
+	for obj := range lst.Values() {
+		rule, err := ParseRule(obj)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+type patternRule struct {
+	pattern  sx.Object
+	template sx.Object
+}
+
+func (r *patternRule) Rewrite(node *sx.Pair) (sx.Object, bool) {
+	b := bindings{}
+	if !b.match(r.pattern, node) {
+		return nil, false
+	}
+	return b.build(r.template), true
+}
+
+// varName returns the name of a variable and whether it is a rest variable.
+// If the symbol is no variable, an empty string is returned.
+func varName(obj sx.Object) (string, bool) {
+	sym, isSymbol := sx.GetSymbol(obj)
+	if !isSymbol {
+		return "", false
+	}
+	s := sym.GetValue()
+	if !strings.HasPrefix(s, varPrefix) || s == formAttrs || s == formText {
+		return "", false
+	}
+	if name, isRest := strings.CutSuffix(s, restSuffix); isRest {
+		return name, true
+	}
+	return s, false
+}
+
+// isForm returns true, if the list starts with the given symbol.
+func isForm(lst *sx.Pair, form string) bool {
+	sym, isSymbol := sx.GetSymbol(lst.Car())
+	return isSymbol && sym.GetValue() == form
+}
+
+func checkPattern(pattern sx.Object, vars map[string]bool) error {
+	if name, isRest := varName(pattern); name != "" {
+		if isRest {
+			return fmt.Errorf("rest variable %v outside of a list", pattern)
+		}
+		if name != varPrefix {
+			vars[name] = true
+		}
+		return nil
+	}
+	lst, isPair := sx.GetPair(pattern)
+	if !isPair || lst == nil {
+		return nil
+	}
+	if isForm(lst, formAttrs) {
+		return checkAttrsPattern(lst, vars)
+	}
+	for elem := range lst.Pairs() {
+		if name, isRest := varName(elem.Car()); isRest {
+			if elem.Tail() != nil {
+				return fmt.Errorf("rest variable %v is not the last element", elem.Car())
+			}
+			if name != varPrefix {
+				vars[name] = true
+			}
+			continue
+		}
+		if err := checkPattern(elem.Car(), vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkAttrsPattern(lst *sx.Pair, vars map[string]bool) error {
+	specs := lst.Tail()
+	if name, isRest := varName(specs.Car()); name != "" {
+		if isRest {
+			return fmt.Errorf("rest variable %v not allowed", specs.Car())
+		}
+		if name != varPrefix {
+			vars[name] = true
+		}
+		specs = specs.Tail()
+	}
+	for obj := range specs.Values() {
+		spec, isPair := sx.GetPair(obj)
+		if !isPair || spec.Length() != 2 {
+			return fmt.Errorf("attribute pattern must be a list of a key and a value: %v", obj)
+		}
+		switch spec.Car().(type) {
+		case *sx.Symbol, sx.String:
+		default:
+			return fmt.Errorf("attribute key must be a symbol or a string: %v", spec.Car())
+		}
+		if err := checkPattern(spec.Tail().Car(), vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkTemplate(template sx.Object, vars map[string]bool) error {
+	if name, isRest := varName(template); name != "" {
+		if isRest {
+			return fmt.Errorf("rest variable %v outside of a list", template)
+		}
+		if !vars[name] {
+			return fmt.Errorf("unbound variable %v", template)
+		}
+		return nil
+	}
+	lst, isPair := sx.GetPair(template)
+	if !isPair || lst == nil {
+		return nil
+	}
+	if isForm(lst, formText) {
+		if lst.Length() != 2 {
+			return fmt.Errorf("%s needs exactly one argument: %v", formText, lst)
+		}
+		return checkTemplate(lst.Tail().Car(), vars)
+	}
+	for elem := range lst.Values() {
+		if name, isRest := varName(elem); isRest {
+			if !vars[name] {
+				return fmt.Errorf("unbound variable %v", elem)
+			}
+			continue
+		}
+		if err := checkTemplate(elem, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindings maps the name of a variable to its bound object.
+type bindings map[string]sx.Object
+
+func (b bindings) match(pattern sx.Object, obj sx.Object) bool {
+	if name, _ := varName(pattern); name != "" {
+		if name == varPrefix {
+			return true
+		}
+		if bound, found := b[name]; found {
+			return bound.IsEqual(obj)
+		}
+		b[name] = obj
+		return true
+	}
+	lst, isPair := sx.GetPair(pattern)
+	if !isPair || lst == nil {
+		return pattern.IsEqual(obj)
+	}
+	if isForm(lst, formAttrs) {
+		return b.matchAttrs(lst.Tail(), obj)
+	}
+	objLst, isPair := sx.GetPair(obj)
+	if !isPair {
+		return false
+	}
+	for elem := range lst.Pairs() {
+		if name, isRest := varName(elem.Car()); isRest {
+			return b.match(sx.MakeSymbol(name), objLst)
+		}
+		if objLst == nil || !b.match(elem.Car(), objLst.Car()) {
+			return false
+		}
+		objLst = objLst.Tail()
+	}
+	return objLst == nil
+}
+
+func (b bindings) matchAttrs(specs *sx.Pair, obj sx.Object) bool {
+	attrsLst, isPair := sx.GetPair(obj)
+	if !isPair {
+		return false
+	}
+	if name, _ := varName(specs.Car()); name != "" {
+		if !b.match(specs.Car(), attrsLst) {
+			return false
+		}
+		specs = specs.Tail()
+	}
+	a := zsx.GetAttributes(attrsLst)
+	for spec := range specs.Values() {
+		specLst, _ := sx.GetPair(spec)
+		key := zsx.GoValue(specLst.Car())
+		valPattern := specLst.Tail().Car()
+		if class, isString := sx.GetString(valPattern); isString && key == "class" {
+			if !a.HasClass(class.GetValue()) {
+				return false
+			}
+			continue
+		}
+		val, found := a.Get(key)
+		if !found || !b.match(valPattern, sx.MakeString(val)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b bindings) build(template sx.Object) sx.Object {
+	if name, _ := varName(template); name != "" {
+		return b[name]
+	}
+	lst, isPair := sx.GetPair(template)
+	if !isPair || lst == nil {
+		return template
+	}
+	if isForm(lst, formText) {
+		var sb strings.Builder
+		if nodes, isNodes := sx.GetPair(b.build(lst.Tail().Car())); isNodes {
+			writeText(&sb, nodes)
+		}
+		return sx.MakeString(sb.String())
+	}
+	var lb sx.ListBuilder
+	for elem := range lst.Values() {
+		if name, isRest := varName(elem); isRest {
+			if rest, isList := sx.GetPair(b[name]); isList {
+				for obj := range rest.Values() {
+					lb.Add(obj)
+				}
+			}
+			continue
+		}
+		lb.Add(b.build(elem))
+	}
+	return lb.List()
+}
+
+// writeText writes the text of all nodes of the list. Line breaks are
+// written as a space.
+func writeText(sb *strings.Builder, nodes *sx.Pair) {
+	for obj := range nodes.Values() {
+		node, isPair := sx.GetPair(obj)
+		if !isPair || node == nil {
+			continue
+		}
+		for n := range zsx.All(node) {
+			switch zsx.NodeSymbol(n) {
+			case zsx.SymText:
+				sb.WriteString(zsx.GetText(n))
+			case zsx.SymSoft, zsx.SymHard:
+				sb.WriteByte(' ')
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package rewrite provides a rule engine to transform zsx trees.
+//
+// A [Rule] replaces a matching node by another object. Typically, it is
+// another node, but it may also be a splice node (see [zsx.SymSpecialSplice])
+// to replace a node by several nodes, or nil to remove the node. Rules are
+// either written in Go, e.g. as a [RuleFunc], or they are sx expressions
+// that consist of a pattern and a template, see [NewRule].
+//
+// [Apply] walks a tree and applies the rules to every node, until no rule
+// changes the tree any more.
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Rule rewrites a node.
+type Rule interface {
+	// Rewrite returns the replacement of the node and a true value, if the
+	// rule matches the node. Otherwise, it returns a false value.
+	Rewrite(node *sx.Pair) (sx.Object, bool)
+}
+
+// RuleFunc is a function that is a Rule.
+type RuleFunc func(node *sx.Pair) (sx.Object, bool)
+
+// Rewrite the node by calling the function.
+func (rf RuleFunc) Rewrite(node *sx.Pair) (sx.Object, bool) { return rf(node) }
+
+// MaxPasses is the maximum number of walks that [Apply] performs.
+const MaxPasses = 100
+
+// ErrNoFixpoint is returned by [Apply], if the rules still change the tree
+// after MaxPasses walks.
+var ErrNoFixpoint = errors.New("rewrite: rules do not reach a fixpoint")
+
+// Apply walks the tree and applies the rules to every node, after its child
+// nodes were rewritten. For every node, the first matching rule is applied.
+// The tree is walked again, until no rule changes a node. The given tree is
+// not modified.
+func Apply(node *sx.Pair, rules ...Rule) (*sx.Pair, error) {
+	for range MaxPasses {
+		v := visitor{rules: rules}
+		obj := zsx.Walk(&v, node, nil)
+		result, isPair := sx.GetPair(obj)
+		if !isPair {
+			return nil, fmt.Errorf("rewrite: root node is replaced by a non-node: %v", obj)
+		}
+		if !v.changed {
+			return result, nil
+		}
+		node = result
+	}
+	return nil, ErrNoFixpoint
+}
+
+type visitor struct {
+	rules   []Rule
+	changed bool
+}
+
+func (*visitor) VisitBefore(*sx.Pair, *sx.Pair) (sx.Object, bool) { return sx.Nil(), false }
+
+func (v *visitor) VisitAfter(node *sx.Pair, _ *sx.Pair) sx.Object {
+	for _, rule := range v.rules {
+		if obj, ok := rule.Rewrite(node); ok {
+			if obj == nil {
+				obj = sx.Nil()
+			}
+			if !node.IsEqual(obj) {
+				v.changed = true
+			}
+			return obj
+		}
+	}
+	return node
+}
@@ -0,0 +1,211 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package rewrite_test
+
+import (
+	"errors"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/rewrite"
+)
+
+func sym(s string) *sx.Symbol { return sx.MakeSymbol(s) }
+
+func mustRule(t *testing.T, pattern, template sx.Object) rewrite.Rule {
+	t.Helper()
+	rule, err := rewrite.NewRule(pattern, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+// kbdRule rewrites a span with class "kbd" into an input literal.
+func kbdRule(t *testing.T) rewrite.Rule {
+	return mustRule(t,
+		sx.MakeList(zsx.SymFormatSpan,
+			sx.MakeList(sym("?attrs"), sx.MakeList(sym("class"), sx.MakeString("kbd"))),
+			sym("?inlines...")),
+		sx.MakeList(zsx.SymLiteralInput, sx.Nil(),
+			sx.MakeList(sym("?text"), sx.MakeList(sym("?inlines...")))),
+	)
+}
+
+// unwrapRule replaces an emphasized text by its inline elements.
+func unwrapRule(t *testing.T) rewrite.Rule {
+	return mustRule(t,
+		sx.MakeList(zsx.SymFormatEmph, sym("?"), sym("?inlines...")),
+		sx.MakeList(zsx.SymSpecialSplice, sym("?inlines...")),
+	)
+}
+
+// removeRule removes all comments.
+func removeRule(t *testing.T) rewrite.Rule {
+	return mustRule(t, sx.MakeList(zsx.SymLiteralComment, sym("?...")), sx.Nil())
+}
+
+// upgradeRule increases the level of all headings up to level 3.
+var upgradeRule = rewrite.RuleFunc(func(node *sx.Pair) (sx.Object, bool) {
+	if zsx.NodeSymbol(node) != zsx.SymHeading {
+		return nil, false
+	}
+	attrs, level, inlines := zsx.GetHeading(node)
+	if level >= 3 {
+		return nil, false
+	}
+	return zsx.MakeHeading(attrs, level+1, inlines), true
+})
+
+// mergeRule merges two adjacent text nodes of a paragraph.
+func mergeRule(t *testing.T) rewrite.Rule {
+	return mustRule(t,
+		sx.MakeList(zsx.SymPara,
+			sx.MakeList(zsx.SymText, sym("?a")), sx.MakeList(zsx.SymText, sym("?b"))),
+		sx.MakeList(zsx.SymPara,
+			sx.MakeList(zsx.SymText, sx.MakeList(sym("?text"), sx.MakeList(
+				sx.MakeList(zsx.SymText, sym("?a")), sx.MakeList(zsx.SymText, sym("?b")))))),
+	)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	kbdAttrs := zsx.Attributes{"class": "kbd"}.AsAssoc()
+	testcases := []struct {
+		name  string
+		rules func(*testing.T) []rewrite.Rule
+		node  *sx.Pair
+		exp   *sx.Pair
+	}{
+		{"kbd", func(t *testing.T) []rewrite.Rule { return []rewrite.Rule{kbdRule(t)} },
+			zsx.MakePara(zsx.MakeFormat(zsx.SymFormatSpan, kbdAttrs,
+				sx.MakeList(zsx.MakeText("Ctrl"), zsx.MakeSoft(), zsx.MakeText("C")))),
+			zsx.MakePara(zsx.MakeLiteral(zsx.SymLiteralInput, nil, "Ctrl C"))},
+		{"kbd-no-class", func(t *testing.T) []rewrite.Rule { return []rewrite.Rule{kbdRule(t)} },
+			zsx.MakePara(zsx.MakeFormat(zsx.SymFormatSpan, nil, sx.MakeList(zsx.MakeText("C")))),
+			zsx.MakePara(zsx.MakeFormat(zsx.SymFormatSpan, nil, sx.MakeList(zsx.MakeText("C"))))},
+		{"splice", func(t *testing.T) []rewrite.Rule { return []rewrite.Rule{unwrapRule(t)} },
+			zsx.MakePara(zsx.MakeText("a"),
+				zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(zsx.MakeText("b"), zsx.MakeSoft())),
+				zsx.MakeText("c")),
+			zsx.MakePara(zsx.MakeText("a"), zsx.MakeText("b"), zsx.MakeSoft(), zsx.MakeText("c"))},
+		{"remove", func(t *testing.T) []rewrite.Rule { return []rewrite.Rule{removeRule(t)} },
+			zsx.MakePara(zsx.MakeText("a"), zsx.MakeLiteral(zsx.SymLiteralComment, nil, "b")),
+			zsx.MakePara(zsx.MakeText("a"))},
+		{"func", func(*testing.T) []rewrite.Rule { return []rewrite.Rule{upgradeRule} },
+			zsx.MakeBlock(zsx.MakeHeading(nil, 1, nil), zsx.MakeHeading(nil, 4, nil)),
+			zsx.MakeBlock(zsx.MakeHeading(nil, 3, nil), zsx.MakeHeading(nil, 4, nil))},
+		{"fixpoint", func(t *testing.T) []rewrite.Rule { return []rewrite.Rule{unwrapRule(t), mergeRule(t)} },
+			zsx.MakePara(
+				zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(zsx.MakeText("a"))),
+				zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(zsx.MakeText("b")))),
+			zsx.MakePara(zsx.MakeText("ab"))},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			orig := tc.node.String()
+			got, err := rewrite.Apply(tc.node, tc.rules(t)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.IsEqual(tc.exp) {
+				t.Errorf("expected:\n%v\nbut got:\n%v", tc.exp, got)
+			}
+			if s := tc.node.String(); s != orig {
+				t.Errorf("node was modified: %v, but was %v", s, orig)
+			}
+		})
+	}
+}
+
+func TestApplyError(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakePara(zsx.MakeText("a"))
+	swap := rewrite.RuleFunc(func(node *sx.Pair) (sx.Object, bool) {
+		if zsx.NodeSymbol(node) != zsx.SymText {
+			return nil, false
+		}
+		if zsx.GetText(node) == "a" {
+			return zsx.MakeText("b"), true
+		}
+		return zsx.MakeText("a"), true
+	})
+	if _, err := rewrite.Apply(node, swap); !errors.Is(err, rewrite.ErrNoFixpoint) {
+		t.Errorf("expected %v, but got %v", rewrite.ErrNoFixpoint, err)
+	}
+
+	toString := rewrite.RuleFunc(func(*sx.Pair) (sx.Object, bool) { return sx.MakeString("a"), true })
+	if got, err := rewrite.Apply(node, toString); err == nil {
+		t.Errorf("error expected, but got %v", got)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	t.Parallel()
+	rules, err := rewrite.ParseRules(sx.MakeList(
+		sx.MakeList(
+			sx.MakeList(zsx.SymText, sx.MakeString("a")),
+			sx.MakeList(zsx.SymText, sx.MakeString("b"))),
+		sx.MakeList(
+			sx.MakeList(zsx.SymHeading, sym("?"), sym("?level"), sym("?inlines...")),
+			sx.MakeList(zsx.SymPara, sym("?inlines..."))),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := zsx.MakeBlock(zsx.MakeHeading(nil, 1, sx.MakeList(zsx.MakeText("a"))))
+	got, err := rewrite.Apply(node, rules...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := zsx.MakeBlock(zsx.MakePara(zsx.MakeText("b"))); !got.IsEqual(exp) {
+		t.Errorf("expected:\n%v\nbut got:\n%v", exp, got)
+	}
+}
+
+func TestParseRuleError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		rule sx.Object
+	}{
+		{"atom", sx.MakeString("a")},
+		{"short", sx.MakeList(sx.MakeList(zsx.SymText, sym("?a")))},
+		{"long", sx.MakeList(sx.Nil(), sx.Nil(), sx.Nil())},
+		{"rest-not-last", sx.MakeList(
+			sx.MakeList(zsx.SymPara, sym("?a..."), sym("?b")), sx.Nil())},
+		{"rest-outside", sx.MakeList(sym("?a..."), sx.Nil())},
+		{"unbound", sx.MakeList(
+			sx.MakeList(zsx.SymText, sym("?a")), sx.MakeList(zsx.SymText, sym("?b")))},
+		{"unbound-rest", sx.MakeList(
+			sx.MakeList(zsx.SymPara, sym("?...")), sx.MakeList(zsx.SymPara, sym("?...")))},
+		{"attrs-spec", sx.MakeList(
+			sx.MakeList(zsx.SymThematic, sx.MakeList(sym("?attrs"), sym("class"))), sx.Nil())},
+		{"attrs-key", sx.MakeList(
+			sx.MakeList(zsx.SymThematic, sx.MakeList(sym("?attrs"), sx.MakeList(sx.Int64(1), sym("?")))), sx.Nil())},
+		{"text-args", sx.MakeList(
+			sx.MakeList(zsx.SymPara, sym("?a...")),
+			sx.MakeList(zsx.SymText, sx.MakeList(sym("?text"), sym("?a"), sym("?a"))))},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if rule, err := rewrite.ParseRule(tc.rule); err == nil {
+				t.Errorf("error expected, but got %v", rule)
+			}
+		})
+	}
+}
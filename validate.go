@@ -0,0 +1,195 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx
+
+import (
+	"fmt"
+	"slices"
+
+	"t73f.de/r/sx"
+)
+
+// Violation describes a part of a tree that does not conform to the structure
+// of zsx nodes.
+type Violation struct {
+	// Path lists the positions within the enclosing lists that lead from the
+	// root to the offending element. The symbol of a node has position 0, its
+	// first element position 1. The path of the root node is empty.
+	Path []int
+
+	// Node is the offending node, or the node that contains the offending
+	// element. It is nil, if the element is not a node at all.
+	Node *sx.Pair
+
+	// Message describes the violation. A violating element of a node is
+	// named after the element of its layout, e.g. "attrs" or "ref".
+	Message string
+}
+
+func (v Violation) String() string { return fmt.Sprintf("%v: %s", v.Path, v.Message) }
+
+// Validate checks the given node and all its descendants against the
+// structure that is built by the Make functions, or against the layout of a
+// registered custom node (see [RegisterLayout]). It returns all violations
+// found, or nil if the tree is valid.
+//
+// Besides the number and the types of the elements of each node, Validate
+// checks that nodes are placed in a suitable context, e.g. that there is no
+// block node within an inline node. Splice nodes are accepted in every
+// context; their elements must fit into that context.
+func Validate(node *sx.Pair) []Violation {
+	var v validator
+	v.validateNode(node, ContextAny)
+	return v.violations
+}
+
+type validator struct {
+	violations []Violation
+	path       []int
+}
+
+func (v *validator) report(node *sx.Pair, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		Path:    slices.Clone(v.path),
+		Node:    node,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateNode(node *sx.Pair, ctx Context) {
+	sym := NodeSymbol(node)
+	if sym == nil {
+		v.report(node, "not a node: %v", node)
+		return
+	}
+	if SymSpecialSplice.IsEqualSymbol(sym) {
+		v.validateNodes(node.Tail(), 1, ctx)
+		return
+	}
+	layout, found := GetLayout(sym)
+	if !found {
+		v.report(node, "unknown node type: %v", sym)
+		return
+	}
+	if ctx != ContextAny && ctx != layout.Context {
+		v.report(node, "%v not allowed in %s context", sym, contextNames[ctx])
+	}
+
+	elems, pos := node.Tail(), 1
+	for _, e := range layout.Elements {
+		if e.Kind == ElementRest {
+			v.validateNodes(elems, pos, e.Context)
+			return
+		}
+		if elems == nil {
+			v.report(node, "%v: missing %s", sym, e.Name)
+			return
+		}
+		v.path = append(v.path, pos)
+		v.validateElem(node, sym, e, elems.Car())
+		v.path = v.path[:len(v.path)-1]
+		elems, pos = elems.Tail(), pos+1
+	}
+	if elems != nil {
+		v.report(node, "%v: superfluous elements: %v", sym, elems)
+	}
+}
+
+func (v *validator) validateElem(node *sx.Pair, sym *sx.Symbol, e Element, obj sx.Object) {
+	switch e.Kind {
+	case ElementAttrs:
+		if msg := checkAttributes(obj); msg != "" {
+			v.report(node, "%v: %s: %s", sym, e.Name, msg)
+		}
+	case ElementInt:
+		if num, isNum := sx.GetNumber(obj); isNum {
+			if val, isInt := num.(sx.Int64); isInt {
+				if val <= 0 {
+					v.report(node, "%v: %s: not positive: %v", sym, e.Name, val)
+				}
+				return
+			}
+		}
+		v.report(node, "%v: %s: not an integer: %v", sym, e.Name, obj)
+	case ElementString:
+		if _, isString := sx.GetString(obj); !isString {
+			v.report(node, "%v: %s: not a string: %v", sym, e.Name, obj)
+		}
+	case ElementRef:
+		if ref, isPair := sx.GetPair(obj); isPair {
+			if refSym, _ := GetReference(ref); refSym != nil {
+				return
+			}
+		}
+		v.report(node, "%v: %s: not a reference: %v", sym, e.Name, obj)
+	case ElementList:
+		if lst, isPair := sx.GetPair(obj); isPair {
+			v.validateNodes(lst, 0, e.Context)
+			return
+		}
+		v.report(node, "%v: %s: not a list: %v", sym, e.Name, obj)
+	case ElementNode:
+		if sx.IsNil(obj) {
+			return
+		}
+		if child, isPair := sx.GetPair(obj); isPair {
+			v.validateNode(child, e.Context)
+			return
+		}
+		v.report(node, "%v: %s: not a node: %v", sym, e.Name, obj)
+	default:
+		panic(fmt.Sprintf("unknown element kind %d", e.Kind))
+	}
+}
+
+func (v *validator) validateNodes(lst *sx.Pair, pos int, ctx Context) {
+	for obj := range lst.Values() {
+		v.path = append(v.path, pos)
+		if child, isPair := sx.GetPair(obj); isPair {
+			v.validateNode(child, ctx)
+		} else {
+			v.report(nil, "not a node: %v", obj)
+		}
+		v.path = v.path[:len(v.path)-1]
+		pos++
+	}
+}
+
+// checkAttributes returns a message, if the given object is not an attribute
+// list. Keys must be symbols or strings, values must be strings.
+func checkAttributes(obj sx.Object) string {
+	attrs, isPair := sx.GetPair(obj)
+	if !isPair {
+		return fmt.Sprintf("not a list: %v", obj)
+	}
+	for elem := range attrs.Values() {
+		pair, isPair := sx.GetPair(elem)
+		if !isPair || pair == nil {
+			return fmt.Sprintf("not an attribute: %v", elem)
+		}
+		switch pair.Car().(type) {
+		case *sx.Symbol, sx.String:
+		default:
+			return fmt.Sprintf("not an attribute key: %v", pair.Car())
+		}
+		val := pair.Cdr()
+		if tail, isTail := sx.GetPair(val); isTail && tail != nil {
+			val = tail.Car() // list form (key value)
+		}
+		if _, isString := sx.GetString(val); !isString {
+			return fmt.Sprintf("not a string attribute value: %v", pair)
+		}
+	}
+	return ""
+}
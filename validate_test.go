@@ -0,0 +1,131 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zsx_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+func TestValidateValid(t *testing.T) {
+	t.Parallel()
+	text := func(s string) *sx.Pair { return sx.MakeList(zsx.MakeText(s)) }
+	para := func(s string) *sx.Pair { return sx.MakeList(zsx.MakePara(zsx.MakeText(s))) }
+	ref := zsx.MakeReference(zsx.SymRefStateExternal, "https://t73f.de")
+	attrs := zsx.Attributes{"id": "x"}.AsAssoc()
+	testcases := []struct {
+		name string
+		node *sx.Pair
+	}{
+		{"heading", zsx.MakeBlock(zsx.MakeHeading(attrs, 3, text("A")), zsx.MakeThematic(nil))},
+		{"inlines", zsx.MakePara(
+			zsx.MakeText("a"), zsx.MakeSoft(), zsx.MakeHard(),
+			zsx.MakeFormat(zsx.SymFormatEmph, nil, text("b")),
+			zsx.MakeLink(nil, ref, text("c")),
+			zsx.MakeEmbed(nil, zsx.MakeReference(zsx.SymRefStateHosted, "img.png"), "", text("d")),
+			zsx.MakeEndnote(nil, text("e")),
+			zsx.MakeCite(nil, "f", nil),
+			zsx.MakeMark(nil, "g", text("h")),
+			zsx.MakeLiteral(zsx.SymLiteralCode, attrs, "i"),
+			zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), nil),
+		)},
+		{"lists", zsx.MakeBlock(
+			zsx.MakeList(zsx.SymListUnordered, nil, sx.MakeList(
+				zsx.MakeListItem(nil, para("a")),
+				zsx.MakeListItem(nil, sx.MakeList(
+					zsx.MakeList(zsx.SymListOrdered, nil, sx.MakeList(zsx.MakeListItem(nil, para("b")))))),
+			)),
+			zsx.MakeList(zsx.SymListQuote, nil, sx.MakeList(zsx.MakeListItem(nil, para("c")))),
+			zsx.MakeDescription(nil, sx.MakeList(
+				zsx.MakeTerm(nil, text("d")),
+				zsx.MakeDetail(sx.MakeList(zsx.MakeEntry(nil, para("e")))),
+			)),
+		)},
+		{"table", zsx.MakeTable(nil,
+			zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(nil, text("a")), zsx.MakeCell(nil, text("b")))),
+			sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(nil, text("c")), zsx.MakeCell(nil, nil)))),
+		)},
+		{"table-empty", zsx.MakeTable(nil, nil, nil)},
+		{"regions", zsx.MakeBlock(
+			zsx.MakeRegion(zsx.SymRegionBlock, nil, para("a"), text("b")),
+			zsx.MakeRegion(zsx.SymRegionQuote, nil, para("c"), nil),
+			zsx.MakeVerbatim(zsx.SymVerbatimCode, zsx.Attributes{"": "go"}.AsAssoc(), "x"),
+			zsx.MakeTransclusion(nil, ref, nil),
+			zsx.MakeBLOB(nil, "png", []byte{1, 2}, text("z")),
+		)},
+		{"splice", zsx.MakeBlock(
+			sx.MakeList(zsx.SymSpecialSplice, zsx.MakePara(zsx.MakeText("a"))),
+			zsx.MakePara(sx.MakeList(zsx.SymSpecialSplice, zsx.MakeText("b"), zsx.MakeSoft())),
+		)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := zsx.Validate(tc.node); got != nil {
+				t.Errorf("%v\n%v", got, tc.node)
+			}
+		})
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		node *sx.Pair
+		exp  string
+	}{
+		{"no-node", sx.MakeList(sx.MakeString("a")), `[]: not a node: ("a")`},
+		{"unknown", sx.MakeList(sx.MakeSymbol("UNKNOWN")), `[]: unknown node type: UNKNOWN`},
+		{"missing", sx.MakeList(zsx.SymText), `[]: TEXT: missing text`},
+		{"superfluous", sx.MakeList(zsx.SymSoft, sx.MakeString("a")), `[]: SOFT: superfluous elements: ("a")`},
+		{"text", zsx.MakePara(sx.MakeList(zsx.SymText, sx.Int64(1))), `[1 1]: TEXT: text: not a string: 1`},
+		{"level", zsx.MakeBlock(sx.MakeList(zsx.SymHeading, sx.Nil(), sx.MakeString("1"))),
+			`[1 2]: HEADING: level: not an integer: "1"`},
+		{"level-zero", zsx.MakeHeading(nil, 0, nil), `[2]: HEADING: level: not positive: 0`},
+		{"ref", zsx.MakeLink(nil, sx.MakeList(sx.MakeString("a")), nil), `[2]: LINK: ref: not a reference: ("a")`},
+		{"attrs", zsx.MakeThematic(sx.MakeList(sx.MakeString("a"))), `[1]: THEMATIC: attrs: not an attribute: "a"`},
+		{"attr-value", zsx.MakeThematic(sx.MakeList(sx.Cons(sx.MakeString("a"), sx.Int64(1)))),
+			`[1]: THEMATIC: attrs: not a string attribute value: ("a" . 1)`},
+		{"child", zsx.MakePara(zsx.MakeText("a"), sx.MakeList(sx.MakeSymbol("UNKNOWN"))), `[2]: unknown node type: UNKNOWN`},
+		{"no-child", zsx.MakeBlockList(sx.MakeList(sx.MakeString("a"))), `[1]: not a node: "a"`},
+		{"para-in-emph", zsx.MakePara(zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(zsx.MakePara()))),
+			`[1 2]: PARA not allowed in inline context`},
+		{"text-in-block", zsx.MakeBlock(zsx.MakeText("a")), `[1]: TEXT not allowed in block context`},
+		{"item", zsx.MakeList(zsx.SymListOrdered, nil, sx.MakeList(zsx.MakePara())), `[2]: PARA not allowed in list context`},
+		{"header", zsx.MakeTable(nil, zsx.MakeCell(nil, nil), nil), `[2]: CELL not allowed in table context`},
+		{"region", zsx.MakeRegion(zsx.SymRegionBlock, nil, sx.MakeList(zsx.MakeText("a")), nil),
+			`[2 0]: TEXT not allowed in block context`},
+		{"splice", zsx.MakePara(sx.MakeList(zsx.SymSpecialSplice, zsx.MakeThematic(nil))),
+			`[1 1]: THEMATIC not allowed in inline context`},
+		{"many", zsx.MakePara(sx.MakeList(zsx.SymText), zsx.MakeText("a"), zsx.MakeBlock()),
+			`[1]: TEXT: missing text` + "\n" + `[3]: BLOCK not allowed in inline context`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			for i, v := range zsx.Validate(tc.node) {
+				if i > 0 {
+					sb.WriteByte('\n')
+				}
+				sb.WriteString(v.String())
+			}
+			if got := sb.String(); got != tc.exp {
+				t.Errorf("\nexp: %s\ngot: %s", tc.exp, got)
+			}
+		})
+	}
+}
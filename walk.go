@@ -14,6 +14,8 @@
 package zsx
 
 import (
+	"context"
+
 	"t73f.de/r/sx"
 )
 
@@ -51,7 +53,9 @@ func Walk(v Visitor, node *sx.Pair, alst *sx.Pair) sx.Object {
 
 	if sym := NodeSymbol(node); sym != nil {
 		if fn, found := mapWalkChildren[sym]; found {
-			node = fn(v, node, alst)
+			node = fn(v, node, walkChildAlist(node, alst))
+		} else if layout, isCustom := getCustomLayout(sym); isCustom {
+			node = walkLayoutChildren(v, node, walkChildAlist(node, alst), layout)
 		}
 	}
 	return v.VisitAfter(node, alst)
@@ -86,12 +90,143 @@ func WalkIt(v VisitorIt, node *sx.Pair, alst *sx.Pair) {
 
 	if sym := NodeSymbol(node); sym != nil {
 		if fn, found := mapWalkChildrenIt[sym]; found {
-			fn(v, node, alst)
+			fn(v, node, walkChildAlist(node, alst))
+		} else if layout, isCustom := getCustomLayout(sym); isCustom {
+			walkLayoutChildrenIt(v, node, walkChildAlist(node, alst), layout)
 		}
 	}
 	v.VisitItAfter(node, alst)
 }
 
+// VisitorErr is like [Visitor], but its methods may return an error, which
+// stops the walking process.
+type VisitorErr interface {
+	// VisitBefore is called before child nodes are traversed, like
+	// Visitor.VisitBefore.
+	VisitBefore(node *sx.Pair, alst *sx.Pair) (sx.Object, bool, error)
+
+	// VisitAfter is called after all child nodes were traversed, like
+	// Visitor.VisitAfter.
+	VisitAfter(node *sx.Pair, alst *sx.Pair) (sx.Object, error)
+}
+
+// WalkErr walks a sx-based AST through a VisitorErr, like [Walk].
+//
+// The walking process stops, if a method of the visitor returns an error, or
+// if the context is cancelled. In this case, no other method is called and
+// the first error is returned, together with a nil object.
+func WalkErr(ctx context.Context, v VisitorErr, node *sx.Pair, alst *sx.Pair) (sx.Object, error) {
+	ev := errVisitor{walkStop: makeWalkStop(ctx), v: v}
+	obj := Walk(&ev, node, alst)
+	if ev.err != nil {
+		return nil, ev.err
+	}
+	return obj, nil
+}
+
+// errVisitor adapts a VisitorErr to a Visitor. After an error, all nodes are
+// returned unchanged without traversing them.
+type errVisitor struct {
+	walkStop
+	v VisitorErr
+}
+
+func (ev *errVisitor) VisitBefore(node *sx.Pair, alst *sx.Pair) (sx.Object, bool) {
+	if ev.stopped() {
+		return node, true
+	}
+	obj, ok, err := ev.v.VisitBefore(node, alst)
+	if err != nil {
+		ev.err = err
+		return node, true
+	}
+	return obj, ok
+}
+
+func (ev *errVisitor) VisitAfter(node *sx.Pair, alst *sx.Pair) sx.Object {
+	if ev.stopped() {
+		return node
+	}
+	obj, err := ev.v.VisitAfter(node, alst)
+	if err != nil {
+		ev.err = err
+		return node
+	}
+	return obj
+}
+
+// walkStop records the first error of a walking process.
+type walkStop struct {
+	ctx  context.Context
+	done <-chan struct{}
+	err  error
+}
+
+func makeWalkStop(ctx context.Context) walkStop { return walkStop{ctx: ctx, done: ctx.Done()} }
+
+// stopped returns true, if an error occurred or the context was cancelled.
+func (ws *walkStop) stopped() bool {
+	if ws.err != nil {
+		return true
+	}
+	select {
+	case <-ws.done:
+		ws.err = ws.ctx.Err()
+		return true
+	default:
+		return false
+	}
+}
+
+// VisitorItErr is like [VisitorIt], but its methods may return an error,
+// which stops the walking process.
+type VisitorItErr interface {
+	// VisitItBefore is called before child nodes are traversed, like
+	// VisitorIt.VisitItBefore.
+	VisitItBefore(node *sx.Pair, alst *sx.Pair) (bool, error)
+
+	// VisitItAfter is called after all child nodes were traversed, like
+	// VisitorIt.VisitItAfter.
+	VisitItAfter(node *sx.Pair, alst *sx.Pair) error
+}
+
+// WalkItErr walks a sx-based AST with the guidance of a VisitorItErr, like
+// [WalkIt].
+//
+// The walking process stops, if a method of the visitor returns an error, or
+// if the context is cancelled. In this case, no other method is called and
+// the first error is returned.
+func WalkItErr(ctx context.Context, v VisitorItErr, node *sx.Pair, alst *sx.Pair) error {
+	ev := errVisitorIt{walkStop: makeWalkStop(ctx), v: v}
+	WalkIt(&ev, node, alst)
+	return ev.err
+}
+
+// errVisitorIt adapts a VisitorItErr to a VisitorIt.
+type errVisitorIt struct {
+	walkStop
+	v VisitorItErr
+}
+
+func (ev *errVisitorIt) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	if ev.stopped() {
+		return true
+	}
+	ok, err := ev.v.VisitItBefore(node, alst)
+	if err != nil {
+		ev.err = err
+		return true
+	}
+	return ok
+}
+
+func (ev *errVisitorIt) VisitItAfter(node *sx.Pair, alst *sx.Pair) {
+	if ev.stopped() {
+		return
+	}
+	ev.err = ev.v.VisitItAfter(node, alst)
+}
+
 // GetWalkPos returns the position of the current element in it parent list.
 // It will return -1, if there is no indication about the position.
 func GetWalkPos(alst *sx.Pair) int {
@@ -115,6 +250,42 @@ func GetWalkList(alst *sx.Pair) *sx.Pair {
 
 var symWalkList = sx.MakeSymbol("walk-list")
 
+// GetWalkParents returns the list of all ancestors of the current node,
+// starting with its parent. The list is empty for the root node. Splice
+// nodes are not included.
+//
+// The ancestors are the nodes as they were before their child nodes were
+// walked, i.e. they do not reflect changes made by a [Visitor].
+func GetWalkParents(alst *sx.Pair) *sx.Pair {
+	if pair := alst.Assoc(symWalkParents); pair != nil {
+		return pair.Tail()
+	}
+	return nil
+}
+
+var symWalkParents = sx.MakeSymbol("walk-parents")
+
+// GetWalkDepth returns the nesting depth of the current node, i.e. the
+// number of its ancestors. The root node has depth 0.
+func GetWalkDepth(alst *sx.Pair) int {
+	if pair := alst.Assoc(symWalkDepth); pair != nil {
+		if i, ok := pair.Cdr().(sx.Int64); ok {
+			return int(i)
+		}
+	}
+	return 0
+}
+
+var symWalkDepth = sx.MakeSymbol("walk-depth")
+
+// walkChildAlist returns the association list for walking the child nodes of
+// the given node.
+func walkChildAlist(node *sx.Pair, alst *sx.Pair) *sx.Pair {
+	return alst.
+		Cons(sx.Cons(symWalkParents, GetWalkParents(alst).Cons(node))).
+		Cons(sx.Cons(symWalkDepth, sx.Int64(GetWalkDepth(alst)+1)))
+}
+
 type walkChildrenMap map[*sx.Symbol]func(Visitor, *sx.Pair, *sx.Pair) *sx.Pair
 type walkChildrenItMap map[*sx.Symbol]func(VisitorIt, *sx.Pair, *sx.Pair)
 
@@ -307,3 +478,61 @@ func walkEmbedChildren(v Visitor, en *sx.Pair, alst *sx.Pair) *sx.Pair {
 	newInlines := walkChildrenList(v, inlines, alst)
 	return MakeEmbed(attrs, ref, syntax, newInlines)
 }
+
+// walkLayoutChildren walks the child nodes of a custom node, as specified by
+// its layout.
+func walkLayoutChildren(v Visitor, node *sx.Pair, alst *sx.Pair, layout Layout) *sx.Pair {
+	var lb sx.ListBuilder
+	lb.Add(node.Car())
+	next := node.Tail()
+	for _, e := range layout.Elements {
+		if next == nil {
+			break
+		}
+		switch e.Kind {
+		case ElementRest:
+			return walkChildren(v, next, alst, &lb)
+		case ElementList:
+			if lst, isPair := sx.GetPair(next.Car()); isPair {
+				lb.Add(walkChildrenList(v, lst, alst))
+			} else {
+				lb.Add(next.Car())
+			}
+		case ElementNode:
+			if child, isPair := sx.GetPair(next.Car()); isPair && child != nil {
+				lb.Add(Walk(v, child, alst))
+			} else {
+				lb.Add(next.Car())
+			}
+		default:
+			lb.Add(next.Car())
+		}
+		next = next.Tail()
+	}
+	for obj := range next.Values() {
+		lb.Add(obj)
+	}
+	return lb.List()
+}
+func walkLayoutChildrenIt(v VisitorIt, node *sx.Pair, alst *sx.Pair, layout Layout) {
+	next := node.Tail()
+	for _, e := range layout.Elements {
+		if next == nil {
+			return
+		}
+		switch e.Kind {
+		case ElementRest:
+			WalkItList(v, next, 0, alst)
+			return
+		case ElementList:
+			if lst, isPair := sx.GetPair(next.Car()); isPair {
+				WalkItList(v, lst, 0, alst)
+			}
+		case ElementNode:
+			if child, isPair := sx.GetPair(next.Car()); isPair {
+				WalkIt(v, child, alst)
+			}
+		}
+		next = next.Tail()
+	}
+}
@@ -14,8 +14,11 @@
 package zsx_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"t73f.de/r/sx"
@@ -102,3 +105,134 @@ type spliceTestVisitor struct{}
 
 func (spliceTestVisitor) VisitBefore(*sx.Pair, *sx.Pair) (sx.Object, bool) { return sx.Nil(), false }
 func (spliceTestVisitor) VisitAfter(node *sx.Pair, _ *sx.Pair) sx.Object   { return node }
+
+func TestWalkParentsDepth(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeBlock(
+		zsx.MakeTable(nil, nil, sx.MakeList(zsx.MakeRow(nil, sx.MakeList(zsx.MakeCell(nil, sx.MakeList(zsx.MakeText("a"))))))),
+		zsx.MakeRegion(zsx.SymRegionQuote, nil,
+			sx.MakeList(zsx.MakePara(zsx.MakeLink(nil, zsx.ParseReference("x"), sx.MakeList(zsx.MakeText("b"))))),
+			sx.MakeList(zsx.MakeText("c"))),
+	)
+	exp := "a:4:CELL/ROW/TABLE/BLOCK b:4:LINK/PARA/REGION-QUOTE/BLOCK c:2:REGION-QUOTE/BLOCK"
+	v := parentsTestVisitor{}
+	_ = zsx.Walk(&v, node, nil)
+	if got := strings.Join(v.result, " "); got != exp {
+		t.Errorf("Walk:\nexp: %s\ngot: %s", exp, got)
+	}
+	v.result = nil
+	zsx.WalkIt(&v, node, nil)
+	if got := strings.Join(v.result, " "); got != exp {
+		t.Errorf("WalkIt:\nexp: %s\ngot: %s", exp, got)
+	}
+	if depth := zsx.GetWalkDepth(nil); depth != 0 {
+		t.Errorf("depth of root: exp=0, got=%d", depth)
+	}
+}
+
+type parentsTestVisitor struct {
+	result []string
+}
+
+func (v *parentsTestVisitor) VisitBefore(node *sx.Pair, alst *sx.Pair) (sx.Object, bool) {
+	v.VisitItBefore(node, alst)
+	return sx.Nil(), false
+}
+func (*parentsTestVisitor) VisitAfter(node *sx.Pair, _ *sx.Pair) sx.Object { return node }
+func (v *parentsTestVisitor) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	if zsx.SymText.IsEqual(node.Car()) {
+		var parents []string
+		for p := range zsx.GetWalkParents(alst).Values() {
+			parents = append(parents, zsx.NodeSymbol(p.(*sx.Pair)).GetValue())
+		}
+		v.result = append(v.result, fmt.Sprintf("%s:%d:%s",
+			zsx.GetText(node), zsx.GetWalkDepth(alst), strings.Join(parents, "/")))
+	}
+	return false
+}
+func (*parentsTestVisitor) VisitItAfter(*sx.Pair, *sx.Pair) {}
+
+var errWalkTest = errors.New("walk test")
+
+// errTestVisitor counts the visited nodes and returns an error when the text
+// node with the given text is visited.
+type errTestVisitor struct {
+	errText string
+	cancel  func()
+	count   int
+}
+
+func (v *errTestVisitor) check(node *sx.Pair) error {
+	v.count++
+	if zsx.SymText.IsEqual(node.Car()) && zsx.GetText(node) == v.errText {
+		if v.cancel != nil {
+			v.cancel()
+			return nil
+		}
+		return errWalkTest
+	}
+	return nil
+}
+func (v *errTestVisitor) VisitBefore(node *sx.Pair, _ *sx.Pair) (sx.Object, bool, error) {
+	return sx.Nil(), false, v.check(node)
+}
+func (*errTestVisitor) VisitAfter(node *sx.Pair, _ *sx.Pair) (sx.Object, error) { return node, nil }
+func (v *errTestVisitor) VisitItBefore(node *sx.Pair, _ *sx.Pair) (bool, error) {
+	return false, v.check(node)
+}
+func (*errTestVisitor) VisitItAfter(*sx.Pair, *sx.Pair) error { return nil }
+
+func TestWalkErr(t *testing.T) {
+	t.Parallel()
+	node := zsx.MakeBlock(
+		zsx.MakePara(zsx.MakeText("a"), zsx.MakeText("b")),
+		zsx.MakePara(zsx.MakeText("c")),
+	)
+	testcases := []struct {
+		name    string
+		errText string
+		cancel  bool
+		count   int
+		err     error
+	}{
+		{"ok", "", false, 6, nil},
+		{"error", "a", false, 3, errWalkTest},
+		{"cancel", "b", true, 4, context.Canceled},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			v := errTestVisitor{errText: tc.errText}
+			if tc.cancel {
+				v.cancel = cancel
+			}
+			obj, err := zsx.WalkErr(ctx, &v, node, nil)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("WalkErr: exp error %v, got %v", tc.err, err)
+			}
+			if err == nil && obj.String() != node.String() {
+				t.Errorf("WalkErr: exp %v, got %v", node, obj)
+			}
+			if err != nil && obj != nil {
+				t.Errorf("WalkErr: no object expected, but got %v", obj)
+			}
+			if v.count != tc.count {
+				t.Errorf("WalkErr: exp %d visited nodes, got %d", tc.count, v.count)
+			}
+
+			ctx, cancel = context.WithCancel(context.Background())
+			defer cancel()
+			v = errTestVisitor{errText: tc.errText}
+			if tc.cancel {
+				v.cancel = cancel
+			}
+			if err = zsx.WalkItErr(ctx, &v, node, nil); !errors.Is(err, tc.err) {
+				t.Errorf("WalkItErr: exp error %v, got %v", tc.err, err)
+			}
+			if v.count != tc.count {
+				t.Errorf("WalkItErr: exp %d visited nodes, got %d", tc.count, v.count)
+			}
+		})
+	}
+}
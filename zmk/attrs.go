@@ -0,0 +1,197 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk
+
+import (
+	"strings"
+
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// parseBlockAttributes parses the attributes of a block element. Besides
+// the normal attribute syntax, a single word is allowed, which is the value
+// of the generic attribute (key "").
+func (cp *zmkP) parseBlockAttributes() zsx.Attributes {
+	inp := cp.inp
+	cp.skipSpace()
+	if inp.Ch == '{' {
+		pos := inp.Pos
+		if a, ok := cp.doParseAttributes(); ok {
+			return a
+		}
+		inp.SetPos(pos)
+		return nil
+	}
+	posD := inp.Pos
+	for !input.IsEOLEOS(inp.Ch) && !inp.IsSpace() && inp.Ch != '{' {
+		inp.Next()
+	}
+	if posD < inp.Pos {
+		return zsx.Attributes{"": string(inp.Src[posD:inp.Pos])}
+	}
+	return nil
+}
+
+// parseInlineAttributes parses the attributes that may follow an inline
+// element. If there are no valid attributes, the input is not changed.
+func (cp *zmkP) parseInlineAttributes() zsx.Attributes {
+	inp := cp.inp
+	if inp.Ch != '{' {
+		return nil
+	}
+	pos := inp.Pos
+	if a, ok := cp.doParseAttributes(); ok {
+		return a
+	}
+	inp.SetPos(pos)
+	return nil
+}
+
+func (cp *zmkP) doParseAttributes() (zsx.Attributes, bool) {
+	inp := cp.inp
+	if inp.Ch != '{' {
+		return nil, false
+	}
+	inp.Next()
+	a := zsx.Attributes{}
+	if !cp.parseAttributeValues(a) {
+		return nil, false
+	}
+	inp.Next()
+	return a, true
+}
+
+func (cp *zmkP) skipSpaceLine() {
+	for inp := cp.inp; ; {
+		switch inp.Ch {
+		case ' ', '\t':
+			inp.Next()
+		case '\n', '\r':
+			inp.EatEOL()
+		default:
+			return
+		}
+	}
+}
+
+func (cp *zmkP) parseAttributeValues(a zsx.Attributes) bool {
+	inp := cp.inp
+	for {
+		cp.skipSpaceLine()
+		switch inp.Ch {
+		case input.EOS:
+			return false
+		case '}':
+			return true
+		case '.':
+			inp.Next()
+			posC := inp.Pos
+			for isNameRune(inp.Ch) {
+				inp.Next()
+			}
+			if posC == inp.Pos {
+				return false
+			}
+			updateAttrs(a, "class", string(inp.Src[posC:inp.Pos]))
+		case '=':
+			delete(a, "")
+			if !cp.parseAttributeValue("", a) {
+				return false
+			}
+		default:
+			if !cp.parseNormalAttribute(a) {
+				return false
+			}
+		}
+
+		switch inp.Ch {
+		case '}':
+			return true
+		case '\n', '\r':
+		case ' ', '\t', ',':
+			inp.Next()
+		default:
+			return false
+		}
+	}
+}
+
+func (cp *zmkP) parseNormalAttribute(a zsx.Attributes) bool {
+	inp := cp.inp
+	posK := inp.Pos
+	for isNameRune(inp.Ch) {
+		inp.Next()
+	}
+	if posK == inp.Pos {
+		return false
+	}
+	key := string(inp.Src[posK:inp.Pos])
+	if inp.Ch != '=' {
+		a[key] = ""
+		return true
+	}
+	return cp.parseAttributeValue(key, a)
+}
+
+func (cp *zmkP) parseAttributeValue(key string, a zsx.Attributes) bool {
+	inp := cp.inp
+	if inp.Next() == '"' {
+		return cp.parseQuotedAttributeValue(key, a)
+	}
+	posV := inp.Pos
+	for {
+		switch inp.Ch {
+		case input.EOS:
+			return false
+		case '\n', '\r', ' ', '\t', ',', '}':
+			updateAttrs(a, key, string(inp.Src[posV:inp.Pos]))
+			return true
+		}
+		inp.Next()
+	}
+}
+
+func (cp *zmkP) parseQuotedAttributeValue(key string, a zsx.Attributes) bool {
+	inp := cp.inp
+	inp.Next()
+	var sb strings.Builder
+	for {
+		switch inp.Ch {
+		case input.EOS:
+			return false
+		case '"':
+			updateAttrs(a, key, sb.String())
+			inp.Next()
+			return true
+		case '\\':
+			switch inp.Next() {
+			case input.EOS, '\n', '\r':
+				return false
+			}
+			fallthrough
+		default:
+			sb.WriteRune(inp.Ch)
+			inp.Next()
+		}
+	}
+}
+
+func updateAttrs(a zsx.Attributes, key, val string) {
+	if prevVal := a[key]; len(prevVal) > 0 {
+		a[key] = prevVal + " " + val
+	} else {
+		a[key] = val
+	}
+}
@@ -0,0 +1,578 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk
+
+import (
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// parseBlock parses one block element. If a new block node was detected, it
+// is added to the list builder. Otherwise, a previous node was continued.
+func (cp *zmkP) parseBlock(lb *sx.ListBuilder) {
+	inp := cp.inp
+	pos := inp.Pos
+	if cp.nestingLevel <= maxNestingLevel {
+		cp.nestingLevel++
+		defer func() { cp.nestingLevel-- }()
+
+		var bn *sx.Pair
+		success := false
+
+		switch inp.Ch {
+		case input.EOS:
+			return
+		case '\n', '\r':
+			inp.EatEOL()
+			cp.cleanupAfterEOL()
+			return
+		case ':':
+			bn, success = cp.parseColon()
+		case '`', '%', '~', '$', '@':
+			cp.clearStacked()
+			bn, success = cp.parseVerbatim()
+		case '"', '<':
+			cp.clearStacked()
+			bn, success = cp.parseRegion()
+		case '=':
+			cp.clearStacked()
+			bn, success = cp.parseHeading()
+		case '-':
+			cp.clearStacked()
+			bn, success = cp.parseThematic()
+		case '*', '#', '>':
+			cp.descrl = nil
+			cp.closeTable()
+			bn, success = cp.parseNestedList()
+		case ';':
+			cp.lists = nil
+			cp.closeTable()
+			bn, success = cp.parseDefTerm()
+		case ' ':
+			bn, success = nil, cp.parseIndent()
+		case '|':
+			cp.lists = nil
+			cp.descrl = nil
+			bn, success = cp.parseRow()
+		case '{':
+			cp.clearStacked()
+			bn, success = cp.parseTransclusion()
+		}
+
+		if success {
+			cp.lastPara = nil
+			if bn != nil {
+				lb.Add(bn)
+			}
+			return
+		}
+	}
+	inp.SetPos(pos)
+	cp.clearStacked()
+	cp.skipSpace()
+	ins, brk := cp.parseLinePara()
+	if len(ins) == 0 {
+		cp.cleanupAfterEOL()
+		return
+	}
+	if para := cp.lastPara; para != nil {
+		if cp.lastBreak != nil {
+			appendNodes(para, cp.lastBreak)
+		}
+		appendNodes(para, ins...)
+	} else {
+		cp.lastPara = zsx.MakePara(ins...)
+		lb.Add(cp.lastPara)
+	}
+	cp.lastBreak = brk
+}
+
+// cleanupAfterEOL is called after an empty line. No paragraph may be
+// continued after this.
+func (cp *zmkP) cleanupAfterEOL() {
+	cp.lastPara = nil
+	cp.itemPara = nil
+	cp.closeTable()
+}
+
+// parseLinePara parses the inline elements of a paragraph line. If the line
+// continues with something that could be a block element, parsing stops.
+// The line break that ends the line is returned separately.
+func (cp *zmkP) parseLinePara() ([]*sx.Pair, *sx.Pair) {
+	var ins []*sx.Pair
+	for {
+		in := cp.parseInline()
+		if in == nil {
+			return cleanLine(ins), nil
+		}
+		if isBreak(in) {
+			switch cp.inp.Ch {
+			// Must contain all cases from above switch in parseBlock.
+			case input.EOS, '\n', '\r', ':', '`', '%', '~', '$', '@', '"', '<', '=', '-', '*', '#', '>', ';', ' ', '|', '{':
+				return cleanLine(ins), in
+			}
+		}
+		ins = append(ins, in)
+	}
+}
+
+// parseColon determines which element should be parsed.
+func (cp *zmkP) parseColon() (*sx.Pair, bool) {
+	if cp.inp.Peek() == ':' {
+		cp.clearStacked()
+		return cp.parseRegion()
+	}
+	cp.lists = nil
+	cp.closeTable()
+	return cp.parseDefDescr()
+}
+
+var mapRuneVerbatim = map[rune]*sx.Symbol{
+	'`': zsx.SymVerbatimCode,
+	'%': zsx.SymVerbatimComment,
+	'~': zsx.SymVerbatimEval,
+	'$': zsx.SymVerbatimMath,
+	'@': zsx.SymVerbatimZettel,
+}
+
+// syntaxHTML is the value of the generic attribute of a code block, which
+// makes it a block of HTML.
+const syntaxHTML = "html"
+
+// parseVerbatim parses a verbatim block.
+func (cp *zmkP) parseVerbatim() (*sx.Pair, bool) {
+	inp := cp.inp
+	fch := inp.Ch
+	cnt := cp.countDelim(fch)
+	if cnt < 3 {
+		return nil, false
+	}
+	attrs := cp.parseBlockAttributes()
+	cp.skipSpace()
+	if !input.IsEOLEOS(inp.Ch) {
+		return nil, false
+	}
+	inp.EatEOL()
+
+	var lines []string
+	for inp.Ch != input.EOS {
+		posL := inp.Pos
+		if cp.countDelim(fch) >= cnt {
+			cp.skipSpace()
+			if input.IsEOLEOS(inp.Ch) {
+				inp.EatEOL()
+				break
+			}
+		}
+		inp.SetPos(posL)
+		inp.SkipToEOL()
+		lines = append(lines, string(inp.Src[posL:inp.Pos]))
+		inp.EatEOL()
+	}
+
+	sym := mapRuneVerbatim[fch]
+	if sym == zsx.SymVerbatimCode {
+		if syntax, found := attrs.Get(""); found && syntax == syntaxHTML {
+			sym = zsx.SymVerbatimHTML
+			attrs = attrs.Remove("")
+		}
+	}
+	return zsx.MakeVerbatim(sym, attrs.AsAssoc(), strings.Join(lines, "\n")), true
+}
+
+var mapRuneRegion = map[rune]*sx.Symbol{
+	':': zsx.SymRegionBlock,
+	'<': zsx.SymRegionQuote,
+	'"': zsx.SymRegionVerse,
+}
+
+// parseRegion parses a block region.
+func (cp *zmkP) parseRegion() (*sx.Pair, bool) {
+	inp := cp.inp
+	fch := inp.Ch
+	cnt := cp.countDelim(fch)
+	if cnt < 3 {
+		return nil, false
+	}
+	attrs := cp.parseBlockAttributes()
+	cp.skipSpace()
+	if !input.IsEOLEOS(inp.Ch) {
+		return nil, false
+	}
+	inp.EatEOL()
+
+	cp.lastPara = nil
+	var lb sx.ListBuilder
+	var inlines *sx.Pair
+	for inp.Ch != input.EOS {
+		posL := inp.Pos
+		if cp.countDelim(fch) == cnt {
+			cp.clearStacked()
+			cp.skipSpace()
+			inlines = cp.parseRestOfLine()
+			break
+		}
+		inp.SetPos(posL)
+		cp.parseBlock(&lb)
+	}
+	cp.clearStacked()
+	cp.lastPara = nil
+	cp.itemPara = nil
+
+	sym := mapRuneRegion[fch]
+	blocks := lb.List()
+	if sym == zsx.SymRegionVerse {
+		blocks = hardenBreaks(blocks)
+	}
+	return zsx.MakeRegion(sym, attrs.AsAssoc(), blocks, inlines), true
+}
+
+// parseRestOfLine parses all inline elements until the end of the current line.
+func (cp *zmkP) parseRestOfLine() *sx.Pair {
+	inp := cp.inp
+	var ins []*sx.Pair
+	for !input.IsEOLEOS(inp.Ch) {
+		in := cp.parseInline()
+		if in == nil {
+			break
+		}
+		ins = append(ins, in)
+	}
+	inp.EatEOL()
+	return makeList(cleanLine(ins))
+}
+
+// hardenBreaks changes all soft line breaks in paragraphs into hard line breaks.
+func hardenBreaks(blocks *sx.Pair) *sx.Pair {
+	var lb sx.ListBuilder
+	for bn := range blocks.Pairs() {
+		block := bn.Head()
+		if zsx.SymPara.IsEqual(block.Car()) {
+			var ins sx.ListBuilder
+			for in := range zsx.GetPara(block).Pairs() {
+				if inl := in.Head(); zsx.SymSoft.IsEqual(inl.Car()) {
+					ins.Add(zsx.MakeHard())
+				} else {
+					ins.Add(inl)
+				}
+			}
+			block = zsx.MakeParaList(ins.List())
+		}
+		lb.Add(block)
+	}
+	return lb.List()
+}
+
+// maxHeadingLevel is the highest level a heading may have.
+const maxHeadingLevel = 5
+
+// parseHeading parses a heading.
+func (cp *zmkP) parseHeading() (*sx.Pair, bool) {
+	inp := cp.inp
+	delims := cp.countDelim(inp.Ch)
+	if delims < 3 {
+		return nil, false
+	}
+	if inp.Ch != ' ' {
+		return nil, false
+	}
+	cp.skipSpace()
+	level := min(delims-2, maxHeadingLevel)
+
+	var ins []*sx.Pair
+	var attrs zsx.Attributes
+	for !input.IsEOLEOS(inp.Ch) {
+		if inp.Ch == '{' && inp.Peek() != '{' {
+			pos := inp.Pos
+			if a, ok := cp.doParseAttributes(); ok {
+				cp.skipSpace()
+				if input.IsEOLEOS(inp.Ch) {
+					attrs = a
+					break
+				}
+			}
+			inp.SetPos(pos)
+		}
+		in := cp.parseInline()
+		if in == nil {
+			break
+		}
+		ins = append(ins, in)
+	}
+	inp.EatEOL()
+	return zsx.MakeHeading(attrs.AsAssoc(), level, makeList(cleanLine(ins))), true
+}
+
+// parseLineAttributes parses attributes at the end of a line.
+func (cp *zmkP) parseLineAttributes() (zsx.Attributes, bool) {
+	cp.skipSpace()
+	attrs := cp.parseInlineAttributes()
+	cp.skipSpace()
+	if !input.IsEOLEOS(cp.inp.Ch) {
+		return nil, false
+	}
+	cp.inp.EatEOL()
+	return attrs, true
+}
+
+// parseThematic parses a horizontal rule.
+func (cp *zmkP) parseThematic() (*sx.Pair, bool) {
+	if cp.countDelim('-') < 3 {
+		return nil, false
+	}
+	attrs, ok := cp.parseLineAttributes()
+	if !ok {
+		return nil, false
+	}
+	return zsx.MakeThematic(attrs.AsAssoc()), true
+}
+
+// parseTransclusion parses '{{{ ref }}}' as a block element.
+func (cp *zmkP) parseTransclusion() (*sx.Pair, bool) {
+	inp := cp.inp
+	if cp.countDelim('{') != 3 {
+		return nil, false
+	}
+	posR := inp.Pos
+loop:
+	for {
+		switch inp.Ch {
+		case input.EOS, '\n', '\r', ' ', '\t':
+			return nil, false
+		case '}':
+			if inp.Peek() == '}' && inp.PeekN(1) == '}' {
+				break loop
+			}
+		}
+		inp.Next()
+	}
+	if posR == inp.Pos {
+		return nil, false
+	}
+	ref := string(inp.Src[posR:inp.Pos])
+	inp.Next()
+	inp.Next()
+	inp.Next()
+	attrs, ok := cp.parseLineAttributes()
+	if !ok {
+		return nil, false
+	}
+	return zsx.MakeTransclusion(attrs.AsAssoc(), zsx.ParseReference(ref), nil), true
+}
+
+var mapRuneList = map[rune]*sx.Symbol{
+	'*': zsx.SymListUnordered,
+	'#': zsx.SymListOrdered,
+	'>': zsx.SymListQuote,
+}
+
+// parseNestedList parses a list.
+func (cp *zmkP) parseNestedList() (*sx.Pair, bool) {
+	syms := cp.parseNestedListSymbols()
+	if len(syms) == 0 {
+		return nil, false
+	}
+	cp.skipSpace()
+	if syms[len(syms)-1] != zsx.SymListQuote && input.IsEOLEOS(cp.inp.Ch) {
+		return nil, false
+	}
+
+	if len(syms) < len(cp.lists) {
+		cp.lists = cp.lists[:len(syms)]
+	}
+	ln := cp.buildNestedList(syms)
+	item := zsx.MakeListItem(nil, nil)
+	cp.itemPara = nil
+	if ins, brk := cp.parseLinePara(); len(ins) > 0 {
+		para := zsx.MakePara(ins...)
+		appendNodes(item, para)
+		cp.itemPara, cp.itemBreak = para, brk
+	}
+	appendNodes(cp.lists[len(cp.lists)-1], item)
+	return ln, true
+}
+
+func (cp *zmkP) parseNestedListSymbols() []*sx.Symbol {
+	inp := cp.inp
+	result := make([]*sx.Symbol, 0, 8)
+	for {
+		sym, found := mapRuneList[inp.Ch]
+		if !found {
+			return nil
+		}
+		inp.Next()
+		result = append(result, sym)
+		switch inp.Ch {
+		case '*', '#', '>':
+		case ' ', input.EOS, '\n', '\r':
+			return result
+		default:
+			return nil
+		}
+	}
+}
+
+// buildNestedList updates the stack of nested lists. If a new top-level list
+// must be created, it is returned.
+func (cp *zmkP) buildNestedList(syms []*sx.Symbol) *sx.Pair {
+	var result *sx.Pair
+	for i, sym := range syms {
+		if i < len(cp.lists) {
+			if zsx.NodeSymbol(cp.lists[i]) == sym {
+				continue
+			}
+			cp.lists = cp.lists[:i]
+		}
+		ln := zsx.MakeList(sym, nil, nil)
+		if i == 0 {
+			result = ln
+		} else {
+			parent := cp.lists[i-1]
+			item := lastItem(parent)
+			if item == nil {
+				item = zsx.MakeListItem(nil, nil)
+				appendNodes(parent, item)
+			}
+			appendNodes(item, ln)
+			cp.itemPara = nil
+		}
+		cp.lists = append(cp.lists, ln)
+	}
+	return result
+}
+
+// lastItem returns the last item of a list node.
+func lastItem(ln *sx.Pair) *sx.Pair {
+	_, _, items := zsx.GetList(ln)
+	return lastNode(items)
+}
+
+// parseIndent parses initial spaces to continue a list or a description.
+func (cp *zmkP) parseIndent() bool {
+	inp := cp.inp
+	cnt := 0
+	for inp.Ch == ' ' {
+		cnt++
+		inp.Next()
+	}
+	if input.IsEOLEOS(inp.Ch) {
+		return false
+	}
+	if cp.lists != nil {
+		return cp.parseIndentForList(cnt)
+	}
+	if cp.descrl != nil {
+		return cp.parseIndentForDescription()
+	}
+	return false
+}
+
+// parseIndentForList continues a list item. Two spaces of indentation
+// correspond to one level of list nesting.
+func (cp *zmkP) parseIndentForList(cnt int) bool {
+	level := min((cnt+1)/2, len(cp.lists))
+	cp.lists = cp.lists[:level]
+	item := lastItem(cp.lists[level-1])
+	if item == nil {
+		return false
+	}
+	ins, brk := cp.parseLinePara()
+	cp.continueItemPara(item, ins, brk)
+	return true
+}
+
+// parseIndentForDescription continues the last description of a description list.
+func (cp *zmkP) parseIndentForDescription() bool {
+	detail := lastNode(cp.descrl)
+	entry := lastNode(detail.Tail())
+	if entry == nil {
+		return false
+	}
+	ins, brk := cp.parseLinePara()
+	cp.continueItemPara(entry, ins, brk)
+	return true
+}
+
+// continueItemPara adds the inline elements to the last paragraph of the
+// given container, if it can be continued. Otherwise, a new paragraph is
+// added to the container.
+func (cp *zmkP) continueItemPara(container *sx.Pair, ins []*sx.Pair, brk *sx.Pair) {
+	if len(ins) == 0 {
+		return
+	}
+	if para := cp.itemPara; para != nil && lastNode(container.Tail()) == para {
+		if cp.itemBreak != nil {
+			appendNodes(para, cp.itemBreak)
+		}
+		appendNodes(para, ins...)
+	} else {
+		para = zsx.MakePara(ins...)
+		appendNodes(container, para)
+		cp.itemPara = para
+	}
+	cp.itemBreak = brk
+}
+
+// parseDefTerm parses a term of a description list.
+func (cp *zmkP) parseDefTerm() (*sx.Pair, bool) {
+	inp := cp.inp
+	if inp.Next() != ' ' {
+		return nil, false
+	}
+	cp.skipSpace()
+	var ins []*sx.Pair
+	for {
+		in := cp.parseInline()
+		if in == nil || isBreak(in) {
+			break
+		}
+		ins = append(ins, in)
+	}
+	ins = cleanLine(ins)
+	if len(ins) == 0 {
+		return nil, false
+	}
+
+	var result *sx.Pair
+	if cp.descrl == nil {
+		cp.descrl = zsx.MakeDescription(nil, nil)
+		result = cp.descrl
+	}
+	appendNodes(cp.descrl, zsx.MakeTerm(nil, makeList(ins)), zsx.MakeDetail(nil))
+	cp.itemPara = nil
+	return result, true
+}
+
+// parseDefDescr parses a description of a description list.
+func (cp *zmkP) parseDefDescr() (*sx.Pair, bool) {
+	inp := cp.inp
+	if inp.Next() != ' ' {
+		return nil, false
+	}
+	if cp.descrl == nil {
+		return nil, false
+	}
+	cp.skipSpace()
+	ins, brk := cp.parseLinePara()
+	if len(ins) == 0 {
+		return nil, false
+	}
+	para := zsx.MakePara(ins...)
+	appendNodes(lastNode(cp.descrl), zsx.MakeEntry(nil, sx.MakeList(para)))
+	cp.itemPara, cp.itemBreak = para, brk
+	return nil, true
+}
@@ -0,0 +1,764 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+)
+
+// Encode writes the given node as Zettelmarkup to the writer. Typically, the
+// node is a BLOCK or an INLINE node.
+//
+// The result is canonical Zettelmarkup: parsing it results in a node that is
+// equal to the given one, if the node is the result of parsing Zettelmarkup.
+// Some nodes cannot be expressed in Zettelmarkup, like BLOBs or the
+// attributes of lists and tables. They are encoded as close as possible.
+func Encode(w io.Writer, node *sx.Pair) error {
+	enc := encoder{w: w}
+	zsx.WalkIt(&enc, node, nil)
+	return enc.err
+}
+
+type encoder struct {
+	w         io.Writer
+	err       error
+	indent    string // Written after each line break
+	lineStart bool   // Next text is written at the start of a line
+	startSet  string // Characters to be escaped at the start of a line
+	closing   rune   // Character that is written after the current inlines
+}
+
+var encodeFuncs map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair)
+
+func init() {
+	encodeFuncs = map[*sx.Symbol]func(*encoder, *sx.Pair, *sx.Pair){
+		zsx.SymBlock:           (*encoder).encodeBlock,
+		zsx.SymPara:            (*encoder).encodePara,
+		zsx.SymHeading:         (*encoder).encodeHeading,
+		zsx.SymThematic:        (*encoder).encodeThematic,
+		zsx.SymListOrdered:     (*encoder).encodeList,
+		zsx.SymListUnordered:   (*encoder).encodeList,
+		zsx.SymListQuote:       (*encoder).encodeList,
+		zsx.SymDescription:     (*encoder).encodeDescription,
+		zsx.SymTable:           (*encoder).encodeTable,
+		zsx.SymRegionBlock:     (*encoder).encodeRegion,
+		zsx.SymRegionQuote:     (*encoder).encodeRegion,
+		zsx.SymRegionVerse:     (*encoder).encodeRegion,
+		zsx.SymVerbatimCode:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimComment: (*encoder).encodeVerbatim,
+		zsx.SymVerbatimEval:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimHTML:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimMath:    (*encoder).encodeVerbatim,
+		zsx.SymVerbatimZettel:  (*encoder).encodeVerbatim,
+		zsx.SymTransclude:      (*encoder).encodeTransclusion,
+		zsx.SymBLOB:            (*encoder).encodeBLOB,
+
+		zsx.SymInline:         (*encoder).encodeInline,
+		zsx.SymText:           (*encoder).encodeText,
+		zsx.SymSoft:           (*encoder).encodeSoft,
+		zsx.SymHard:           (*encoder).encodeHard,
+		zsx.SymLink:           (*encoder).encodeLink,
+		zsx.SymEmbed:          (*encoder).encodeEmbed,
+		zsx.SymEmbedBLOB:      (*encoder).encodeEmbedBLOB,
+		zsx.SymCite:           (*encoder).encodeCite,
+		zsx.SymEndnote:        (*encoder).encodeEndnote,
+		zsx.SymMark:           (*encoder).encodeMark,
+		zsx.SymFormatDelete:   (*encoder).encodeFormat,
+		zsx.SymFormatEmph:     (*encoder).encodeFormat,
+		zsx.SymFormatInsert:   (*encoder).encodeFormat,
+		zsx.SymFormatMark:     (*encoder).encodeFormat,
+		zsx.SymFormatQuote:    (*encoder).encodeFormat,
+		zsx.SymFormatSpan:     (*encoder).encodeFormat,
+		zsx.SymFormatStrong:   (*encoder).encodeFormat,
+		zsx.SymFormatSub:      (*encoder).encodeFormat,
+		zsx.SymFormatSuper:    (*encoder).encodeFormat,
+		zsx.SymLiteralCode:    (*encoder).encodeLiteral,
+		zsx.SymLiteralComment: (*encoder).encodeLiteral,
+		zsx.SymLiteralInput:   (*encoder).encodeLiteral,
+		zsx.SymLiteralMath:    (*encoder).encodeLiteral,
+		zsx.SymLiteralOutput:  (*encoder).encodeLiteral,
+		zsx.SymSpecialSplice:  (*encoder).encodeInline,
+	}
+}
+
+func (enc *encoder) VisitItBefore(node *sx.Pair, alst *sx.Pair) bool {
+	sym := zsx.NodeSymbol(node)
+	if sym != zsx.SymText {
+		enc.lineStart = false
+	}
+	if fn, found := encodeFuncs[sym]; found {
+		fn(enc, node, alst)
+	} else {
+		enc.encodeOther(node, alst)
+	}
+	return true
+}
+
+func (*encoder) VisitItAfter(*sx.Pair, *sx.Pair) {}
+
+func (enc *encoder) write(s string) {
+	if enc.err == nil {
+		_, enc.err = io.WriteString(enc.w, s)
+	}
+}
+
+// writeBlocks writes the block nodes, separated by an empty line.
+func (enc *encoder) writeBlocks(blocks *sx.Pair, alst *sx.Pair) {
+	for bn := range blocks.Pairs() {
+		if bn != blocks {
+			enc.write("\n\n")
+		}
+		zsx.WalkIt(enc, bn.Head(), alst)
+	}
+}
+
+// lineStartSet contains all characters that may start a block element.
+const lineStartSet = ":`%~$@\"<=-*#>;|{"
+
+// writeLine writes the inline nodes, which start at the beginning of a line.
+// All following lines are indented.
+func (enc *encoder) writeLine(ins *sx.Pair, indent string, alst *sx.Pair) {
+	prevIndent := enc.indent
+	enc.indent = indent
+	enc.lineStart, enc.startSet = true, lineStartSet
+	enc.writeInlines(ins, 0, alst)
+	enc.indent = prevIndent
+}
+
+// writeInlines writes the inline nodes, which are followed by the closing
+// character.
+func (enc *encoder) writeInlines(ins *sx.Pair, closing rune, alst *sx.Pair) {
+	prevClosing := enc.closing
+	enc.closing = closing
+	zsx.WalkItList(enc, ins, 0, alst)
+	enc.closing = prevClosing
+	enc.lineStart = false
+}
+
+// encodeOther writes the child nodes of a custom node, because there is no
+// Zettelmarkup syntax for it. Unknown nodes are ignored.
+func (enc *encoder) encodeOther(node *sx.Pair, alst *sx.Pair) {
+	layout, _ := zsx.GetLayout(zsx.NodeSymbol(node))
+	first := true
+	for ctx, lst := range zsx.LayoutChildren(node) {
+		if layout.Context == zsx.ContextInline {
+			zsx.WalkItList(enc, lst, 0, alst)
+			continue
+		}
+		if !first {
+			enc.write("\n\n")
+		}
+		if ctx == zsx.ContextInline {
+			enc.writeLine(lst, "", alst)
+		} else {
+			enc.writeBlocks(lst, alst)
+		}
+		first = false
+	}
+}
+
+func (enc *encoder) encodeBlock(node *sx.Pair, alst *sx.Pair) {
+	enc.writeBlocks(zsx.GetBlock(node), alst)
+}
+
+func (enc *encoder) encodePara(node *sx.Pair, alst *sx.Pair) {
+	enc.writeLine(zsx.GetPara(node), "", alst)
+}
+
+func (enc *encoder) encodeHeading(node *sx.Pair, alst *sx.Pair) {
+	attrs, level, ins := zsx.GetHeading(node)
+	enc.write(strings.Repeat("=", min(max(level, 1), maxHeadingLevel)+2))
+	enc.write(" ")
+	enc.writeInlines(ins, 0, alst)
+	if s := encodeAttributes(attrs); s != "" {
+		if ins != nil {
+			enc.write(" ")
+		}
+		enc.write(s)
+	}
+}
+
+func (enc *encoder) encodeThematic(node *sx.Pair, _ *sx.Pair) {
+	enc.write("---")
+	enc.writeLineAttributes(zsx.GetThematic(node))
+}
+
+// writeLineAttributes writes attributes at the end of a line.
+func (enc *encoder) writeLineAttributes(attrs *sx.Pair) {
+	if s := encodeAttributes(attrs); s != "" {
+		enc.write(" ")
+		enc.write(s)
+	}
+}
+
+var mapListRune = map[*sx.Symbol]string{
+	zsx.SymListUnordered: "*",
+	zsx.SymListOrdered:   "#",
+	zsx.SymListQuote:     ">",
+}
+
+func (enc *encoder) encodeList(node *sx.Pair, alst *sx.Pair) { enc.writeList(node, "", alst) }
+
+// writeList writes all items of a list. The prefix contains the characters
+// of all enclosing lists.
+func (enc *encoder) writeList(node *sx.Pair, prefix string, alst *sx.Pair) {
+	sym, _, items := zsx.GetList(node)
+	prefix += mapListRune[sym]
+	indent := strings.Repeat("  ", len(prefix))
+	for item := range items.Pairs() {
+		if item != items {
+			enc.write("\n")
+		}
+		_, elems := zsx.GetListItem(item.Head())
+		first := elems.Head()
+		switch sym := zsx.NodeSymbol(first); sym {
+		case zsx.SymPara:
+			enc.write(prefix)
+			enc.write(" ")
+			enc.writeLine(zsx.GetPara(first), indent, alst)
+			elems = elems.Tail()
+		case zsx.SymListOrdered, zsx.SymListUnordered, zsx.SymListQuote:
+			enc.writeList(first, prefix, alst)
+			elems = elems.Tail()
+		default:
+			enc.write(prefix)
+		}
+		enc.writeItemElements(elems, prefix, indent, alst)
+	}
+}
+
+// writeItemElements writes the remaining paragraphs and lists of an item.
+func (enc *encoder) writeItemElements(elems *sx.Pair, prefix, indent string, alst *sx.Pair) {
+	for elem := range elems.Values() {
+		node, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		switch sym := zsx.NodeSymbol(node); sym {
+		case zsx.SymPara:
+			enc.write("\n\n")
+			enc.write(indent)
+			enc.writeLine(zsx.GetPara(node), indent, alst)
+		case zsx.SymListOrdered, zsx.SymListUnordered, zsx.SymListQuote:
+			enc.write("\n")
+			enc.writeList(node, prefix, alst)
+		}
+	}
+}
+
+func (enc *encoder) encodeDescription(node *sx.Pair, alst *sx.Pair) {
+	_, elems := zsx.GetDescription(node)
+	for elem := range elems.Values() {
+		node, isPair := sx.GetPair(elem)
+		if !isPair {
+			continue
+		}
+		switch zsx.NodeSymbol(node) {
+		case zsx.SymTerm:
+			if elems.Head() != node {
+				enc.write("\n")
+			}
+			_, ins := zsx.GetTerm(node)
+			enc.write("; ")
+			enc.writeInlines(ins, 0, alst)
+		case zsx.SymDetail:
+			for entry := range zsx.GetDetail(node).Values() {
+				if entryNode, isEntry := sx.GetPair(entry); isEntry {
+					enc.writeEntry(entryNode, alst)
+				}
+			}
+		}
+	}
+}
+
+// writeEntry writes the paragraphs of a description entry.
+func (enc *encoder) writeEntry(entry *sx.Pair, alst *sx.Pair) {
+	_, elems := zsx.GetEntry(entry)
+	first := true
+	for elem := range elems.Values() {
+		para, isPair := sx.GetPair(elem)
+		if !isPair || zsx.NodeSymbol(para) != zsx.SymPara {
+			continue
+		}
+		if first {
+			enc.write("\n: ")
+			first = false
+		} else {
+			enc.write("\n\n  ")
+		}
+		enc.writeLine(zsx.GetPara(para), "  ", alst)
+	}
+}
+
+func (enc *encoder) encodeTable(node *sx.Pair, alst *sx.Pair) {
+	_, header, rows := zsx.GetTable(node)
+	var aligns []string
+	if header != nil {
+		_, cells := zsx.GetRow(header)
+		for cell := range cells.Values() {
+			cellNode, _ := sx.GetPair(cell)
+			attrs, _ := zsx.GetCell(cellNode)
+			aligns = append(aligns, getAlign(attrs))
+		}
+		enc.writeRow(header, aligns, true, alst)
+	}
+	for row := range rows.Pairs() {
+		if header != nil || row != rows {
+			enc.write("\n")
+		}
+		enc.writeRow(row.Head(), aligns, false, alst)
+	}
+}
+
+// getAlign returns the alignment value of a table cell.
+func getAlign(attrs *sx.Pair) string {
+	align, _ := zsx.GetAttributes(attrs).Get(zsx.SymAttrAlign.GetValue())
+	return align
+}
+
+var mapAlignRune = map[string]string{
+	zsx.AttrAlignLeft.GetValue():   "<",
+	zsx.AttrAlignCenter.GetValue(): ":",
+	zsx.AttrAlignRight.GetValue():  ">",
+}
+
+// cellStartSet contains all characters that have a special meaning at the
+// start of a table cell.
+const cellStartSet = "=<:>%"
+
+// writeRow writes the cells of a row. The alignment of a header cell is
+// written after its content, the alignment of other cells is written before
+// the content, if it differs from the alignment of the column. Empty cells
+// at the end of a row are omitted, because the parser adds them.
+func (enc *encoder) writeRow(row *sx.Pair, aligns []string, isHeader bool, alst *sx.Pair) {
+	_, cells := zsx.GetRow(row)
+	var contents []*sx.Pair
+	var markers []string
+	last := -1
+	for i := 0; cells != nil; i, cells = i+1, cells.Tail() {
+		attrs, ins := zsx.GetCell(cells.Head())
+		align := getAlign(attrs)
+		marker := "=" + mapAlignRune[align]
+		if !isHeader {
+			marker = ""
+			if i >= len(aligns) || align != aligns[i] {
+				marker = mapAlignRune[align]
+			}
+		}
+		contents, markers = append(contents, ins), append(markers, marker)
+		if isHeader || marker != "" || ins != nil {
+			last = i
+		}
+	}
+	for i, ins := range contents[:last+1] {
+		if i > 0 {
+			enc.write(" ")
+		}
+		enc.write("|")
+		marker := markers[i]
+		if isHeader {
+			enc.write(marker[:1])
+		} else {
+			enc.write(marker)
+			if marker == "" && ins != nil {
+				enc.write(" ")
+			}
+		}
+		enc.lineStart, enc.startSet = true, cellStartSet
+		enc.writeInlines(ins, '|', alst)
+		if isHeader {
+			enc.write(marker[1:])
+		}
+	}
+}
+
+var mapRegionRune = map[*sx.Symbol]string{
+	zsx.SymRegionBlock: ":",
+	zsx.SymRegionQuote: "<",
+	zsx.SymRegionVerse: `"`,
+}
+
+func (enc *encoder) encodeRegion(node *sx.Pair, alst *sx.Pair) {
+	sym, attrs, blocks, ins := zsx.GetRegion(node)
+	fence := strings.Repeat(mapRegionRune[sym], 3+regionDepth(sym, blocks))
+	enc.write(fence)
+	enc.write(encodeBlockAttributes(attrs))
+	enc.write("\n")
+	if blocks != nil {
+		enc.writeBlocks(blocks, alst)
+		enc.write("\n")
+	}
+	enc.write(fence)
+	if ins != nil {
+		enc.write(" ")
+		enc.writeInlines(ins, 0, alst)
+	}
+}
+
+// regionDepth returns the nesting depth of regions with the given symbol.
+// An enclosing region must use more delimiter characters than all regions
+// within.
+func regionDepth(sym *sx.Symbol, blocks *sx.Pair) int {
+	depth := 0
+	for elem := range blocks.Values() {
+		if node, isPair := sx.GetPair(elem); isPair && zsx.NodeSymbol(node) == sym {
+			_, _, inner, _ := zsx.GetRegion(node)
+			depth = max(depth, regionDepth(sym, inner)+1)
+		}
+	}
+	return depth
+}
+
+var mapVerbatimRune = map[*sx.Symbol]string{
+	zsx.SymVerbatimCode:    "`",
+	zsx.SymVerbatimComment: "%",
+	zsx.SymVerbatimEval:    "~",
+	zsx.SymVerbatimHTML:    "`",
+	zsx.SymVerbatimMath:    "$",
+	zsx.SymVerbatimZettel:  "@",
+}
+
+func (enc *encoder) encodeVerbatim(node *sx.Pair, _ *sx.Pair) {
+	sym, attrs, content := zsx.GetVerbatim(node)
+	if sym == zsx.SymVerbatimHTML {
+		attrs = zsx.GetAttributes(attrs).Set("", syntaxHTML).AsAssoc()
+	}
+	fch := mapVerbatimRune[sym]
+	fence := strings.Repeat(fch, verbatimFenceLength(fch, content))
+	enc.write(fence)
+	enc.write(encodeBlockAttributes(attrs))
+	enc.write("\n")
+	if content != "" {
+		enc.write(content)
+		enc.write("\n")
+	}
+	enc.write(fence)
+}
+
+// verbatimFenceLength returns the number of delimiter characters, so that
+// no line of the content is treated as the end of the verbatim block.
+func verbatimFenceLength(fch string, content string) int {
+	length := 3
+	for line := range strings.SplitSeq(content, "\n") {
+		cnt := 0
+		for strings.HasPrefix(line[cnt:], fch) {
+			cnt++
+		}
+		length = max(length, cnt+1)
+	}
+	return length
+}
+
+func (enc *encoder) encodeTransclusion(node *sx.Pair, _ *sx.Pair) {
+	attrs, ref, _ := zsx.GetTransclusion(node)
+	_, val := zsx.GetReference(ref)
+	enc.write("{{{")
+	enc.write(val)
+	enc.write("}}}")
+	enc.writeLineAttributes(attrs)
+}
+
+// encodeBLOB writes a block BLOB as a paragraph with an embedded data URL,
+// because there is no Zettelmarkup syntax for BLOBs.
+func (enc *encoder) encodeBLOB(node *sx.Pair, alst *sx.Pair) {
+	attrs, syntax, data, description := zsx.GetBLOBuncode(node)
+	enc.writeEmbedBLOB(attrs, syntax, data, description, alst)
+}
+
+func (enc *encoder) encodeInline(node *sx.Pair, alst *sx.Pair) {
+	zsx.WalkItList(enc, node, 1, alst)
+}
+
+// Characters that start an inline element, if they are doubled.
+const doubleSet = "_*>~^,\"#:`'=$%-"
+
+func (enc *encoder) encodeText(node *sx.Pair, alst *sx.Pair) {
+	s := zsx.GetText(node)
+	next := enc.nextRune(alst)
+	lineStart := enc.lineStart
+	enc.lineStart = false
+	var sb strings.Builder
+	for i, ch := range s {
+		nextCh := next
+		if j := i + utf8.RuneLen(ch); j < len(s) {
+			nextCh, _ = utf8.DecodeRuneInString(s[j:])
+		}
+		if needsEscape(ch, nextCh) || (i == 0 && lineStart && strings.ContainsRune(enc.startSet, ch)) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(ch)
+	}
+	enc.write(sb.String())
+}
+
+// needsEscape returns true, if the character must be escaped, because it
+// would start an element otherwise.
+func needsEscape(ch, next rune) bool {
+	switch ch {
+	case '\\', '{', ']', '|':
+		return true
+	case '[':
+		return strings.ContainsRune("[@^!", next)
+	case '&':
+		return next == '#' || ('a' <= next && next <= 'z') || ('A' <= next && next <= 'Z')
+	}
+	return ch == next && strings.ContainsRune(doubleSet, ch)
+}
+
+// nextRune returns the first character that will be written after the
+// current node.
+func (enc *encoder) nextRune(alst *sx.Pair) rune {
+	if lst := zsx.GetWalkList(alst); lst != nil {
+		if next := lst.Tail(); next != nil {
+			return firstRune(next.Head())
+		}
+	}
+	return enc.closing
+}
+
+// firstRune returns the first character of the encoding of the given inline
+// node, if it may be relevant for escaping text.
+func firstRune(node *sx.Pair) rune {
+	switch sym := zsx.NodeSymbol(node); sym {
+	case zsx.SymText:
+		ch, _ := utf8.DecodeRuneInString(zsx.GetText(node))
+		return ch
+	case zsx.SymLink, zsx.SymCite, zsx.SymEndnote, zsx.SymMark:
+		return '['
+	case zsx.SymEmbed, zsx.SymEmbedBLOB:
+		return '{'
+	default:
+		if s := mapFormatRune[sym]; s != "" {
+			return rune(s[0])
+		}
+		if s := mapLiteralRune[sym]; s != "" {
+			return rune(s[0])
+		}
+	}
+	return 0
+}
+
+func (enc *encoder) encodeSoft(*sx.Pair, *sx.Pair) {
+	enc.write("\n")
+	enc.write(enc.indent)
+	enc.lineStart = true
+}
+
+func (enc *encoder) encodeHard(*sx.Pair, *sx.Pair) {
+	enc.write("\\\n")
+	enc.write(enc.indent)
+	enc.lineStart = true
+}
+
+func (enc *encoder) encodeLink(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, ins := zsx.GetLink(node)
+	_, val := zsx.GetReference(ref)
+	enc.write("[[")
+	enc.writeReference(ins, val, alst)
+	enc.write("]]")
+	enc.write(encodeAttributes(attrs))
+}
+
+// writeReference writes the content of a link or an embedded element.
+func (enc *encoder) writeReference(ins *sx.Pair, ref string, alst *sx.Pair) {
+	if ins != nil {
+		enc.writeInlines(ins, '|', alst)
+		enc.write("|")
+	}
+	enc.write(ref)
+}
+
+func (enc *encoder) encodeEmbed(node *sx.Pair, alst *sx.Pair) {
+	attrs, ref, syntax, ins := zsx.GetEmbed(node)
+	_, val := zsx.GetReference(ref)
+	enc.writeEmbed(attrs, val, syntax, ins, alst)
+}
+
+func (enc *encoder) writeEmbed(attrs *sx.Pair, ref, syntax string, ins *sx.Pair, alst *sx.Pair) {
+	if syntax != "" {
+		attrs = zsx.GetAttributes(attrs).Set("", syntax).AsAssoc()
+	}
+	enc.write("{{")
+	enc.writeReference(ins, ref, alst)
+	enc.write("}}")
+	enc.write(encodeAttributes(attrs))
+}
+
+// encodeEmbedBLOB writes an inline BLOB as an embedded data URL, because
+// there is no Zettelmarkup syntax for BLOBs.
+func (enc *encoder) encodeEmbedBLOB(node *sx.Pair, alst *sx.Pair) {
+	attrs, syntax, data, ins := zsx.GetEmbedBLOBuncode(node)
+	enc.writeEmbedBLOB(attrs, syntax, data, ins, alst)
+}
+
+func (enc *encoder) writeEmbedBLOB(attrs *sx.Pair, syntax, data string, ins *sx.Pair, alst *sx.Pair) {
+	enc.writeEmbed(attrs, zsx.DataURL(syntax, data), syntax, ins, alst)
+}
+
+func (enc *encoder) encodeCite(node *sx.Pair, alst *sx.Pair) {
+	attrs, key, ins := zsx.GetCite(node)
+	enc.write("[@")
+	enc.write(key)
+	if ins != nil {
+		enc.write(" ")
+		enc.writeInlines(ins, ']', alst)
+	}
+	enc.write("]")
+	enc.write(encodeAttributes(attrs))
+}
+
+func (enc *encoder) encodeEndnote(node *sx.Pair, alst *sx.Pair) {
+	attrs, ins := zsx.GetEndnote(node)
+	enc.write("[^")
+	enc.writeInlines(ins, ']', alst)
+	enc.write("]")
+	enc.write(encodeAttributes(attrs))
+}
+
+func (enc *encoder) encodeMark(node *sx.Pair, alst *sx.Pair) {
+	attrs, mark, ins := zsx.GetMark(node)
+	enc.write("[!")
+	enc.write(mark)
+	if ins != nil {
+		enc.write("|")
+		enc.writeInlines(ins, ']', alst)
+	}
+	enc.write("]")
+	enc.write(encodeAttributes(attrs))
+}
+
+var mapFormatRune = map[*sx.Symbol]string{
+	zsx.SymFormatEmph:   "_",
+	zsx.SymFormatStrong: "*",
+	zsx.SymFormatInsert: ">",
+	zsx.SymFormatDelete: "~",
+	zsx.SymFormatSuper:  "^",
+	zsx.SymFormatSub:    ",",
+	zsx.SymFormatQuote:  `"`,
+	zsx.SymFormatMark:   "#",
+	zsx.SymFormatSpan:   ":",
+}
+
+func (enc *encoder) encodeFormat(node *sx.Pair, alst *sx.Pair) {
+	sym, attrs, ins := zsx.GetFormat(node)
+	delim := mapFormatRune[sym]
+	enc.write(delim + delim)
+	enc.writeInlines(ins, rune(delim[0]), alst)
+	enc.write(delim + delim)
+	enc.write(encodeAttributes(attrs))
+}
+
+var mapLiteralRune = map[*sx.Symbol]string{
+	zsx.SymLiteralCode:    "`",
+	zsx.SymLiteralComment: "%",
+	zsx.SymLiteralInput:   "'",
+	zsx.SymLiteralMath:    "$",
+	zsx.SymLiteralOutput:  "=",
+}
+
+func (enc *encoder) encodeLiteral(node *sx.Pair, _ *sx.Pair) {
+	sym, attrs, text := zsx.GetLiteral(node)
+	if sym == zsx.SymLiteralComment {
+		enc.write("%%")
+		enc.write(encodeAttributes(attrs))
+		if text != "" {
+			enc.write(" ")
+			enc.write(text)
+		}
+		return
+	}
+	delim := mapLiteralRune[sym]
+	enc.write(delim + delim)
+	if sym == zsx.SymLiteralMath {
+		enc.write(text)
+	} else {
+		enc.write(escapeLiteral(text, rune(delim[0])))
+	}
+	enc.write(delim + delim)
+	enc.write(encodeAttributes(attrs))
+}
+
+// escapeLiteral escapes all backslashes and all delimiter characters that
+// would end the literal text.
+func escapeLiteral(s string, delim rune) string {
+	var sb strings.Builder
+	for i, ch := range s {
+		next := delim
+		if j := i + utf8.RuneLen(ch); j < len(s) {
+			next, _ = utf8.DecodeRuneInString(s[j:])
+		}
+		if ch == '\\' || (ch == delim && next == delim) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
+
+// encodeBlockAttributes returns the attributes of a block element. If only
+// the generic attribute is set to a single word, just this word is returned.
+func encodeBlockAttributes(attrs *sx.Pair) string {
+	a := zsx.GetAttributes(attrs)
+	if len(a) == 1 {
+		if val, found := a.Get(""); found && val != "" && !strings.ContainsAny(val, " \t\n\r{") {
+			return val
+		}
+	}
+	return encodeAttributes(attrs)
+}
+
+// encodeAttributes returns the attributes in the form "{key=value ...}",
+// ordered by key. If there are no attributes, the empty string is returned.
+func encodeAttributes(attrs *sx.Pair) string {
+	a := zsx.GetAttributes(attrs)
+	a.CleanSpecial()
+	if a.IsEmpty() {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for _, key := range a.Keys() {
+		if key != "" && strings.IndexFunc(key, func(ch rune) bool { return !isNameRune(ch) }) >= 0 {
+			continue
+		}
+		if sb.Len() > 1 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(key)
+		val := a[key]
+		if val == "" && key != "" {
+			continue
+		}
+		sb.WriteByte('=')
+		if val != "" && !strings.ContainsAny(val, " \t\n\r,}\"\\") {
+			sb.WriteString(val)
+			continue
+		}
+		sb.WriteByte('"')
+		for _, ch := range val {
+			if ch == '"' || ch == '\\' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(ch)
+		}
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
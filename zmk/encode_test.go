@@ -0,0 +1,142 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/internal/layouttest"
+	"t73f.de/r/zsx/zmk"
+)
+
+func encode(t *testing.T, node *sx.Pair) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := zmk.Encode(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+// TestRoundTrip checks that parsing the encoded tree results in the same tree,
+// and that the encoding is canonical.
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+		exp  string
+	}{
+		{"para", "a\nb\\\nc\n\nd", "a\nb\\\nc\n\nd"},
+		{"escapes", `a\_\_b \*\* c\[\[d]] \{e} x\|y \\ \-\-`, `a\__b \** c\[[d\]\] \{e} x\|y \\ \--`},
+		{"line-start", "\\* a\n\\=== b\n\\; c", "\\* a\n\\=\\== b\n\\; c"},
+		{"entity", "&amp;amp; & b", "\\&amp; & b"},
+		{"heading", "=== A __b__ {id=x}\n==== C\n======== D", "=== A __b__ {id=x}\n\n==== C\n\n======= D"},
+		{"thematic", "--- {a=b}", "--- {a=b}"},
+		{"formats", `__a__ **b** >>c>> ~~d~~ ^^e^^ ,,f,, ""g"" ##h## ::i::{lang=de}`,
+			`__a__ **b** >>c>> ~~d~~ ^^e^^ ,,f,, ""g"" ##h## ::i::{lang=de}`},
+		{"format-end", "__a\\___", "__a\\___"},
+		{"format-next", "a\\___b__", "a\\___b__"},
+		{"literals", "``a\\``b`` ''c'' ==d== $$e\\f$$ ``g``{=go}", "``a\\``b`` ''c'' ==d== $$e\\f$$ ``g``{=go}"},
+		{"comment", "a %%{x} comment\nb", "a %%{x} comment\nb"},
+		{"link", "[[a|https://t73f.de]] [[b]]{title=\"x y\"} [[c\\|d|e]]", "[[a|https://t73f.de]] [[b]]{title=\"x y\"} [[c\\|d|e]]"},
+		{"embed", "{{a|img.png}}{=png} {{b}}", "{{a|img.png}}{=png} {{b}}"},
+		{"cite", "[@key] [@key p. 7]{a}", "[@key] [@key p. 7]{a}"},
+		{"endnote", "a[^b __c__]{.x}", "a[^b __c__]{class=x}"},
+		{"mark", "[!m] [!n|t]", "[!m] [!n|t]"},
+		{"ndash", "a -- b", "a – b"},
+		{"unordered", "* a\n* b\n** c\n*# d\n* e", "* a\n* b\n** c\n*# d\n* e"},
+		{"item-paras", "* a\n  b\n\n  c\n** d\n  e", "* a\n  b\n\n  c\n** d\n\n  e"},
+		{"quote-list", "> a\n>\n> b", "> a\n>\n> b"},
+		{"description", "; t\n: d1\n  d1b\n\n  d2\n: e\n; u", "; t\n: d1\n  d1b\n\n  d2\n: e\n; u"},
+		{"table", "|=a|=b>|=c\n|d|<e|f\n|\\=g|h", "|=a |=b> |=c\n| d |<e | f\n| \\=g | h"},
+		{"table-no-header", "|a|>b\n|c", "| a |>b\n| c"},
+		{"table-empty", "|=|=a\n|b|", "|= |=a\n| b"},
+		{"region", ":::note\na\n\n* b\n:::\nc", ":::note\na\n\n* b\n:::\n\nc"},
+		{"region-nested", "::::\n:::{a=\"b c\"}\nx\n:::\n::::", "::::\n:::{a=\"b c\"}\nx\n:::\n::::"},
+		{"region-quote", "<<<\na\n<<< b", "<<<\na\n<<< b"},
+		{"region-verse", "\"\"\"\na\nb\n\"\"\"", "\"\"\"\na\\\nb\n\"\"\""},
+		{"verbatim", "````go\ncode\n```\n````\n~~~\nx\n~~~", "````go\ncode\n```\n````\n\n~~~\nx\n~~~"},
+		{"verbatim-empty", "$$$\n$$$\n\n@@@{a=b}\n\n@@@", "$$$\n$$$\n\n@@@{a=b}\n@@@"},
+		{"verbatim-html", "```html\n<b>\n```\n\n%%%\nc\n%%%", "```html\n<b>\n```\n\n%%%\nc\n%%%"},
+		{"transclude", "{{{ref}}} {a=b}", "{{{ref}}} {a=b}"},
+		{"attrs", "::a::{x=\"a\\\"b\" y= z=\"\" .c .d}", "::a::{class=\"c d\" x=\"a\\\"b\" y z}"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := zmk.ParseBlocks(input.NewInput([]byte(tc.src)))
+			got := encode(t, tree)
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %q\ngot: %q", tc.src, tc.exp, got)
+			}
+			tree2 := zmk.ParseBlocks(input.NewInput([]byte(got)))
+			if s1, s2 := tree.String(), tree2.String(); s1 != s2 {
+				t.Errorf("\nenc:  %q\ntree: %s\ngot:  %s", got, s1, s2)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		node *sx.Pair
+		exp  string
+	}{
+		{"inline", zsx.MakeInline(zsx.MakeText("* a"), zsx.MakeSoft(), zsx.MakeText("b_")), "* a\nb_"},
+		{"next-node", zsx.MakeInline(zsx.MakeText("a_"), zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(zsx.MakeText("b")))),
+			"a\\___b__"},
+		{"blob", zsx.MakeBlock(zsx.MakeBLOB(nil, "png", []byte("abc"), sx.MakeList(zsx.MakeText("d")))),
+			"{{d|data:image/png;base64,YWJj}}{=png}"},
+		{"embed-svg", zsx.MakeInline(zsx.MakeEmbedBLOB(nil, zsx.SyntaxSVG, []byte("<svg/>"), nil)),
+			"{{data:image/svg+xml;base64,PHN2Zy8+}}{=svg}"},
+		{"special-attrs", zsx.MakeBlock(zsx.MakeThematic(sx.MakeList(sx.Cons(zsx.SymSpecialID, sx.MakeString("x"))))), "---"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := encode(t, tc.node); got != tc.exp {
+				t.Errorf("\nnode: %v\nexp:  %q\ngot:  %q", tc.node, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestEncodeCustom(t *testing.T) {
+	t.Parallel()
+	layouttest.Register(t)
+	text := zsx.MakeText
+	testcases := []struct {
+		name string
+		node *sx.Pair
+		exp  string
+	}{
+		{"block", zsx.MakeBlock(layouttest.MakeAside(sx.MakeList(text("t")), zsx.MakePara(text("s")),
+			zsx.MakePara(text("a")), zsx.MakePara(text("b")))), "t\n\ns\n\na\n\nb"},
+		{"inline", zsx.MakeInline(text("x "),
+			layouttest.MakeBadge(text("i"), zsx.MakeFormat(zsx.SymFormatEmph, nil, sx.MakeList(text("c"))))), "x i__c__"},
+		{"unknown", zsx.MakeInline(text("a"), sx.MakeList(sx.MakeSymbol("UNKNOWN"), text("b"))), "a"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := encode(t, tc.node); got != tc.exp {
+				t.Errorf("\nnode: %v\nexp:  %q\ngot:  %q", tc.node, tc.exp, got)
+			}
+		})
+	}
+}
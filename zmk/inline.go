@@ -0,0 +1,479 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk
+
+import (
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// parseInline parses one inline node. It returns nil at the end of the input.
+func (cp *zmkP) parseInline() *sx.Pair {
+	inp := cp.inp
+	switch inp.Ch {
+	case input.EOS:
+		return nil
+	case '\n', '\r':
+		return cp.parseSoftBreak()
+	}
+	cp.escapedText = false
+	pos := inp.Pos
+	if cp.nestingLevel <= maxNestingLevel {
+		cp.nestingLevel++
+		defer func() { cp.nestingLevel-- }()
+
+		var in *sx.Pair
+		success := false
+
+		switch inp.Ch {
+		case '[':
+			switch inp.Next() {
+			case '[':
+				in, success = cp.parseLinkEmbed('[', ']', true)
+			case '@':
+				in, success = cp.parseCite()
+			case '^':
+				in, success = cp.parseEndnote()
+			case '!':
+				in, success = cp.parseMark()
+			}
+		case '{':
+			if inp.Next() == '{' {
+				in, success = cp.parseLinkEmbed('{', '}', false)
+			}
+		case '%':
+			in, success = cp.parseComment()
+		case '_', '*', '>', '~', '^', ',', '"', '#', ':':
+			in, success = cp.parseFormat()
+		case '`', '\'', '=', '$':
+			in, success = cp.parseLiteral()
+		case '\\':
+			return cp.parseBackslash()
+		case '-':
+			in, success = cp.parseNdash()
+		case '&':
+			in, success = cp.parseEntity()
+		}
+
+		if success {
+			return in
+		}
+	}
+	inp.SetPos(pos)
+	return cp.parseText()
+}
+
+// parseText parses plain text. At least one character is consumed.
+func (cp *zmkP) parseText() *sx.Pair {
+	inp := cp.inp
+	pos := inp.Pos
+	for {
+		inp.Next()
+		switch inp.Ch {
+		// The following case must contain all runes that occur in parseInline!
+		// Plus the closing brackets ] and } and the middle |
+		case input.EOS, '\n', '\r', '[', ']', '{', '}', '|', '%', '_', '*', '>', '~', '^', ',', '"', '#', ':', '`', '\'', '=', '$', '\\', '-', '&':
+			return zsx.MakeText(string(inp.Src[pos:inp.Pos]))
+		}
+	}
+}
+
+func (cp *zmkP) parseSoftBreak() *sx.Pair {
+	cp.inp.EatEOL()
+	return zsx.MakeSoft()
+}
+
+// parseBackslash parses an escaped character or a hard line break.
+func (cp *zmkP) parseBackslash() *sx.Pair {
+	inp := cp.inp
+	switch inp.Next() {
+	case '\n', '\r':
+		inp.EatEOL()
+		return zsx.MakeHard()
+	case input.EOS:
+		return zsx.MakeText("\\")
+	}
+	cp.escapedText = true
+	if inp.Ch == ' ' {
+		inp.Next()
+		return zsx.MakeText("\u00a0")
+	}
+	pos := inp.Pos
+	inp.Next()
+	return zsx.MakeText(string(inp.Src[pos:inp.Pos]))
+}
+
+func (cp *zmkP) parseNdash() (*sx.Pair, bool) {
+	inp := cp.inp
+	if inp.Peek() != '-' {
+		return nil, false
+	}
+	inp.Next()
+	inp.Next()
+	return zsx.MakeText("\u2013"), true
+}
+
+func (cp *zmkP) parseEntity() (*sx.Pair, bool) {
+	if text, ok := zsx.ScanEntity(cp.inp); ok {
+		cp.escapedText = true
+		return zsx.MakeText(text), true
+	}
+	return nil, false
+}
+
+// parseLinkEmbed parses a link or an embedded element, depending on isLink.
+func (cp *zmkP) parseLinkEmbed(openCh, closeCh rune, isLink bool) (*sx.Pair, bool) {
+	refString, text, ok := cp.parseReference(openCh, closeCh)
+	if !ok {
+		return nil, false
+	}
+	attrs := cp.parseInlineAttributes()
+	ref := zsx.ParseReference(refString)
+	if isLink {
+		return zsx.MakeLink(attrs.AsAssoc(), ref, text), true
+	}
+	syntax, _ := attrs.Get("")
+	attrs = attrs.Remove("")
+	return zsx.MakeEmbed(attrs.AsAssoc(), ref, syntax, text), true
+}
+
+// parseReference parses the content of a link or an embedded element. The
+// content consists of an optional inline text, followed by the "|" character,
+// followed by the reference value. The content must not contain an empty line.
+func (cp *zmkP) parseReference(openCh, closeCh rune) (string, *sx.Pair, bool) {
+	inp := cp.inp
+	if inp.Next() == openCh {
+		return "", nil, false
+	}
+	pos, sepPos := inp.Pos, -1
+loop:
+	for {
+		switch inp.Ch {
+		case input.EOS:
+			return "", nil, false
+		case '\n', '\r':
+			inp.EatEOL()
+			if input.IsEOLEOS(inp.Ch) {
+				return "", nil, false
+			}
+			continue
+		case '\\':
+			if input.IsEOLEOS(inp.Next()) {
+				continue
+			}
+		case '|':
+			sepPos = inp.Pos
+		case closeCh:
+			if inp.Peek() == closeCh {
+				break loop
+			}
+		}
+		inp.Next()
+	}
+	endPos := inp.Pos
+	inp.Next()
+	inp.Next()
+
+	var text *sx.Pair
+	refPos := pos
+	if sepPos >= 0 {
+		if sepPos == pos {
+			return "", nil, false
+		}
+		text = cp.parseSubInlines(inp.Src[pos:sepPos])
+		refPos = sepPos + 1
+	}
+	ref := strings.TrimSpace(string(inp.Src[refPos:endPos]))
+	if ref == "" || strings.ContainsAny(ref, " \t\n\r") {
+		return "", nil, false
+	}
+	return ref, text, true
+}
+
+// parseSubInlines parses the given source as a list of inline nodes.
+func (cp *zmkP) parseSubInlines(src []byte) *sx.Pair {
+	inp := cp.inp
+	cp.inp = input.NewInput(src)
+	var ins []*sx.Pair
+	for {
+		in := cp.parseInline()
+		if in == nil {
+			break
+		}
+		ins = append(ins, in)
+	}
+	cp.inp = inp
+	return makeList(cleanInlines(ins))
+}
+
+// parseCite parses a citation: "[@key text]".
+func (cp *zmkP) parseCite() (*sx.Pair, bool) {
+	inp := cp.inp
+	switch inp.Next() {
+	case ' ', ',', '|', ']', '\n', '\r', input.EOS:
+		return nil, false
+	}
+	pos := inp.Pos
+loop:
+	for {
+		switch inp.Ch {
+		case input.EOS:
+			return nil, false
+		case ' ', ',', '|', ']', '\n', '\r':
+			break loop
+		}
+		inp.Next()
+	}
+	key := string(inp.Src[pos:inp.Pos])
+	switch inp.Ch {
+	case ' ', ',', '|':
+		inp.Next()
+	}
+	ins, ok := cp.parseLinkLikeRest()
+	if !ok {
+		return nil, false
+	}
+	attrs := cp.parseInlineAttributes()
+	return zsx.MakeCite(attrs.AsAssoc(), key, ins), true
+}
+
+// parseLinkLikeRest parses inline elements until the closing "]".
+func (cp *zmkP) parseLinkLikeRest() (*sx.Pair, bool) {
+	cp.skipSpace()
+	var ins []*sx.Pair
+	inp := cp.inp
+	for inp.Ch != ']' {
+		in := cp.parseInline()
+		if in == nil {
+			return nil, false
+		}
+		ins = append(ins, in)
+		if isBreak(in) && input.IsEOLEOS(inp.Ch) {
+			return nil, false
+		}
+	}
+	inp.Next()
+	return makeList(cleanInlines(ins)), true
+}
+
+// parseEndnote parses an endnote: "[^text]".
+func (cp *zmkP) parseEndnote() (*sx.Pair, bool) {
+	cp.inp.Next()
+	ins, ok := cp.parseLinkLikeRest()
+	if !ok {
+		return nil, false
+	}
+	attrs := cp.parseInlineAttributes()
+	return zsx.MakeEndnote(attrs.AsAssoc(), ins), true
+}
+
+// parseMark parses a mark: "[!mark|text]".
+func (cp *zmkP) parseMark() (*sx.Pair, bool) {
+	inp := cp.inp
+	inp.Next()
+	pos := inp.Pos
+	for inp.Ch != '|' && inp.Ch != ']' {
+		if !isNameRune(inp.Ch) {
+			return nil, false
+		}
+		inp.Next()
+	}
+	mark := string(inp.Src[pos:inp.Pos])
+	var ins *sx.Pair
+	if inp.Ch == '|' {
+		inp.Next()
+		var ok bool
+		if ins, ok = cp.parseLinkLikeRest(); !ok {
+			return nil, false
+		}
+	} else {
+		inp.Next()
+	}
+	attrs := cp.parseInlineAttributes()
+	return zsx.MakeMark(attrs.AsAssoc(), mark, ins), true
+}
+
+// parseComment parses an inline comment, which extends to the end of the line.
+func (cp *zmkP) parseComment() (*sx.Pair, bool) {
+	inp := cp.inp
+	if inp.Next() != '%' {
+		return nil, false
+	}
+	for inp.Ch == '%' {
+		inp.Next()
+	}
+	attrs := cp.parseInlineAttributes()
+	cp.skipSpace()
+	pos := inp.Pos
+	inp.SkipToEOL()
+	return zsx.MakeLiteral(zsx.SymLiteralComment, attrs.AsAssoc(), string(inp.Src[pos:inp.Pos])), true
+}
+
+var mapRuneFormat = map[rune]*sx.Symbol{
+	'_': zsx.SymFormatEmph,
+	'*': zsx.SymFormatStrong,
+	'>': zsx.SymFormatInsert,
+	'~': zsx.SymFormatDelete,
+	'^': zsx.SymFormatSuper,
+	',': zsx.SymFormatSub,
+	'"': zsx.SymFormatQuote,
+	'#': zsx.SymFormatMark,
+	':': zsx.SymFormatSpan,
+}
+
+// parseFormat parses formatted text, which is enclosed by doubled characters.
+func (cp *zmkP) parseFormat() (*sx.Pair, bool) {
+	inp := cp.inp
+	fch := inp.Ch
+	sym := mapRuneFormat[fch]
+	if inp.Next() != fch {
+		return nil, false
+	}
+	inp.Next()
+	var ins []*sx.Pair
+	for {
+		if inp.Ch == input.EOS {
+			return nil, false
+		}
+		if inp.Ch == fch {
+			if inp.Next() == fch {
+				inp.Next()
+				attrs := cp.parseInlineAttributes()
+				return zsx.MakeFormat(sym, attrs.AsAssoc(), makeList(cleanInlines(ins))), true
+			}
+			ins = append(ins, zsx.MakeText(string(fch)))
+		} else if in := cp.parseInline(); in != nil {
+			if isBreak(in) && input.IsEOLEOS(inp.Ch) {
+				return nil, false
+			}
+			ins = append(ins, in)
+		}
+	}
+}
+
+var mapRuneLiteral = map[rune]*sx.Symbol{
+	'`':  zsx.SymLiteralCode,
+	'\'': zsx.SymLiteralInput,
+	'=':  zsx.SymLiteralOutput,
+	'$':  zsx.SymLiteralMath,
+}
+
+// parseLiteral parses literal text, which is enclosed by doubled characters.
+// Within literal text, a backslash escapes the next character, except for
+// literal math.
+func (cp *zmkP) parseLiteral() (*sx.Pair, bool) {
+	inp := cp.inp
+	fch := inp.Ch
+	sym := mapRuneLiteral[fch]
+	if inp.Next() != fch {
+		return nil, false
+	}
+	inp.Next()
+	var sb strings.Builder
+	for {
+		switch inp.Ch {
+		case input.EOS, '\n', '\r':
+			return nil, false
+		case fch:
+			if inp.Peek() == fch {
+				inp.Next()
+				inp.Next()
+				attrs := cp.parseInlineAttributes()
+				return zsx.MakeLiteral(sym, attrs.AsAssoc(), sb.String()), true
+			}
+		case '\\':
+			if fch != '$' {
+				if input.IsEOLEOS(inp.Next()) {
+					return nil, false
+				}
+			}
+		}
+		sb.WriteRune(inp.Ch)
+		inp.Next()
+	}
+}
+
+// isBreak returns true, if the given node is a soft or a hard line break.
+func isBreak(in *sx.Pair) bool {
+	sym := zsx.NodeSymbol(in)
+	return sym == zsx.SymSoft || sym == zsx.SymHard
+}
+
+// isText returns true, if the given node is a text node.
+func isText(in *sx.Pair) bool { return zsx.NodeSymbol(in) == zsx.SymText }
+
+// cleanInlines merges adjacent text nodes and removes empty text nodes.
+// Spaces before and after a line break are removed.
+func cleanInlines(ins []*sx.Pair) []*sx.Pair {
+	result := make([]*sx.Pair, 0, len(ins))
+	var sb strings.Builder
+	flushText := func(trimRight bool) {
+		s := sb.String()
+		sb.Reset()
+		if trimRight {
+			s = strings.TrimRight(s, " \t")
+		}
+		if s != "" {
+			result = append(result, zsx.MakeText(s))
+		}
+	}
+	for _, in := range ins {
+		switch {
+		case isText(in):
+			s := zsx.GetText(in)
+			if sb.Len() == 0 && len(result) > 0 && isBreak(result[len(result)-1]) {
+				s = strings.TrimLeft(s, " \t")
+			}
+			sb.WriteString(s)
+		case isBreak(in):
+			flushText(true)
+			result = append(result, in)
+		default:
+			flushText(false)
+			result = append(result, in)
+		}
+	}
+	flushText(false)
+	return result
+}
+
+// cleanLine cleans the inline nodes of a line and removes all spaces and line
+// breaks at the beginning and at the end.
+func cleanLine(ins []*sx.Pair) []*sx.Pair {
+	ins = cleanInlines(ins)
+	for len(ins) > 0 && isBreak(ins[0]) {
+		ins = ins[1:]
+	}
+	for len(ins) > 0 && isBreak(ins[len(ins)-1]) {
+		ins = ins[:len(ins)-1]
+	}
+	if len(ins) > 0 && isText(ins[0]) {
+		if s := strings.TrimLeft(zsx.GetText(ins[0]), " \t"); s == "" {
+			ins = ins[1:]
+		} else {
+			ins[0] = zsx.MakeText(s)
+		}
+	}
+	if l := len(ins); l > 0 && isText(ins[l-1]) {
+		if s := strings.TrimRight(zsx.GetText(ins[l-1]), " \t"); s == "" {
+			ins = ins[:l-1]
+		} else {
+			ins[l-1] = zsx.MakeText(s)
+		}
+	}
+	return ins
+}
@@ -0,0 +1,107 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk_test
+
+import (
+	"testing"
+
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/zmk"
+)
+
+func checkInlines(t *testing.T, testcases []testCase) {
+	t.Helper()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := zmk.ParseInlines(input.NewInput([]byte(tc.src))).String()
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %s\ngot: %s", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	t.Parallel()
+	checkInlines(t, []testCase{
+		{"empty", "", "(INLINE)"},
+		{"spaces", "  a  b  ", `(INLINE (TEXT "a  b"))`},
+		{"no-block", "=== a\n* b", `(INLINE (TEXT "=== a") (SOFT) (TEXT "* b"))`},
+		{"hard", "a\\\nb", `(INLINE (TEXT "a") (HARD) (TEXT "b"))`},
+		{"escape", `\_\_a\_\_`, `(INLINE (TEXT "__a__"))`},
+		{"entity", "&lt;&amp;&#33;&x", `(INLINE (TEXT "<&!&x"))`},
+		{"ndash", "a--b-c", `(INLINE (TEXT "a–b-c"))`},
+	})
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	checkInlines(t, []testCase{
+		{"emph", "__a__", `(INLINE (FORMAT-EMPH () (TEXT "a")))`},
+		{"strong", "**a**", `(INLINE (FORMAT-STRONG () (TEXT "a")))`},
+		{"insert", ">>a>>", `(INLINE (FORMAT-INSERT () (TEXT "a")))`},
+		{"delete", "~~a~~", `(INLINE (FORMAT-DELETE () (TEXT "a")))`},
+		{"super", "^^a^^", `(INLINE (FORMAT-SUPER () (TEXT "a")))`},
+		{"sub", ",,a,,", `(INLINE (FORMAT-SUB () (TEXT "a")))`},
+		{"quote", `""a""`, `(INLINE (FORMAT-QUOTE () (TEXT "a")))`},
+		{"mark", "##a##", `(INLINE (FORMAT-MARK () (TEXT "a")))`},
+		{"span", "::a::{.b}", `(INLINE (FORMAT-SPAN (("class" . "b")) (TEXT "a")))`},
+		{"nested", "__a **b**_c__", `(INLINE (FORMAT-EMPH () (TEXT "a ") (FORMAT-STRONG () (TEXT "b")) (TEXT "_c")))`},
+		{"multi-line", "__a\nb__", `(INLINE (FORMAT-EMPH () (TEXT "a") (SOFT) (TEXT "b")))`},
+		{"empty-line", "__a\n\nb__", `(INLINE (TEXT "__a") (SOFT) (SOFT) (TEXT "b__"))`},
+		{"unclosed", "__a", `(INLINE (TEXT "__a"))`},
+		{"attrs", `**a**{key="v w" .x}`, `(INLINE (FORMAT-STRONG (("class" . "x") ("key" . "v w")) (TEXT "a")))`},
+		{"no-attrs", "**a**{", `(INLINE (FORMAT-STRONG () (TEXT "a")) (TEXT "{"))`},
+	})
+}
+
+func TestLiteral(t *testing.T) {
+	t.Parallel()
+	checkInlines(t, []testCase{
+		{"code", "``a`b``", "(INLINE (LITERAL-CODE () \"a`b\"))"},
+		{"code-escape", "``a\\`\\`b``", "(INLINE (LITERAL-CODE () \"a``b\"))"},
+		{"input", "''a''{=go}", `(INLINE (LITERAL-INPUT (("" . "go")) "a"))`},
+		{"output", "==a==", `(INLINE (LITERAL-OUTPUT () "a"))`},
+		{"math", `$$\alpha$$`, `(INLINE (LITERAL-MATH () "\\alpha"))`},
+		{"comment", "a %% comment", `(INLINE (TEXT "a ") (LITERAL-COMMENT () "comment"))`},
+		{"unclosed", "``a\nb``", "(INLINE (TEXT \"``a\") (SOFT) (TEXT \"b``\"))"},
+	})
+}
+
+func TestLinkEmbed(t *testing.T) {
+	t.Parallel()
+	checkInlines(t, []testCase{
+		{"ref", "[[https://t73f.de]]", `(INLINE (LINK () (EXTERNAL "https://t73f.de")))`},
+		{"text", "[[a __b__|./c]]", `(INLINE (LINK () (HOSTED "./c") (TEXT "a ") (FORMAT-EMPH () (TEXT "b"))))`},
+		{"pipe-in-text", "[[a|b|#c]]", `(INLINE (LINK () (SELF "#c") (TEXT "a|b")))`},
+		{"attrs", "[[a]]{b=c}", `(INLINE (LINK (("b" . "c")) (HOSTED "a")))`},
+		{"space-in-ref", "[[a b]]", `(INLINE (TEXT "[[a b]]"))`},
+		{"empty-text", "[[|a]]", `(INLINE (TEXT "[[|a]]"))`},
+		{"embed", "{{img.png}}", `(INLINE (EMBED () (HOSTED "img.png") ""))`},
+		{"embed-syntax", "{{alt|img}}{=png}", `(INLINE (EMBED () (HOSTED "img") "png" (TEXT "alt")))`},
+	})
+}
+
+func TestLinkLike(t *testing.T) {
+	t.Parallel()
+	checkInlines(t, []testCase{
+		{"cite", "[@key]", `(INLINE (CITE () "key"))`},
+		{"cite-text", "[@key, p. 7]", `(INLINE (CITE () "key" (TEXT "p. 7")))`},
+		{"no-cite", "[@ key]", `(INLINE (TEXT "[@ key]"))`},
+		{"endnote", "[^a **b**]{.n}", `(INLINE (ENDNOTE (("class" . "n")) (TEXT "a ") (FORMAT-STRONG () (TEXT "b"))))`},
+		{"mark", "[!m]", `(INLINE (MARK () "m"))`},
+		{"mark-text", "[!m|t]", `(INLINE (MARK () "m" (TEXT "t")))`},
+		{"no-mark", "[!m n]", `(INLINE (TEXT "[!m n]"))`},
+	})
+}
@@ -0,0 +1,197 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk
+
+import (
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// tableBuilder collects the rows of a table. The table node is created when
+// the first row is parsed, its content is set when the table is finished.
+type tableBuilder struct {
+	node *sx.Pair
+	rows [][]tableCell
+}
+
+// tableCell stores the inline nodes of a cell. For each inline node, plain
+// states whether it is an unescaped text node. Align is the alignment value
+// of the cell, if any.
+type tableCell struct {
+	inlines []*sx.Pair
+	plain   []bool
+	align   sx.Object
+}
+
+// parseRow parses a table row.
+func (cp *zmkP) parseRow() (*sx.Pair, bool) {
+	inp := cp.inp
+	if inp.Peek() == '%' {
+		// A comment row
+		inp.SkipToEOL()
+		inp.EatEOL()
+		return nil, true
+	}
+
+	var result *sx.Pair
+	tb := cp.table
+	if tb == nil {
+		tb = &tableBuilder{node: sx.MakeList(zsx.SymTable)}
+		cp.table = tb
+		result = tb.node
+	}
+
+	var row []tableCell
+	for inp.Ch == '|' {
+		inp.Next()
+		cp.skipSpace()
+		if input.IsEOLEOS(inp.Ch) {
+			break
+		}
+		row = append(row, cp.parseCell())
+	}
+	inp.EatEOL()
+	if len(row) > 0 {
+		tb.rows = append(tb.rows, row)
+	}
+	return result, true
+}
+
+// parseCell parses the content of a table cell, which ends with "|" or with
+// the end of the line.
+func (cp *zmkP) parseCell() tableCell {
+	inp := cp.inp
+	var cell tableCell
+	for inp.Ch != '|' && !input.IsEOLEOS(inp.Ch) {
+		in := cp.parseInline()
+		if in == nil {
+			break
+		}
+		cell.inlines = append(cell.inlines, in)
+		cell.plain = append(cell.plain, isText(in) && !cp.escapedText)
+	}
+	if l := len(cell.inlines); l > 0 && cell.plain[l-1] {
+		cell.inlines[l-1] = zsx.MakeText(strings.TrimRight(zsx.GetText(cell.inlines[l-1]), " \t"))
+	}
+	return cell
+}
+
+// startText returns the text of the first inline node, if it is an unescaped
+// text node.
+func (cell *tableCell) startText() (string, bool) {
+	if len(cell.inlines) > 0 && cell.plain[0] {
+		return zsx.GetText(cell.inlines[0]), true
+	}
+	return "", false
+}
+
+// endText returns the text of the last inline node, if it is an unescaped
+// text node.
+func (cell *tableCell) endText() (string, bool) {
+	if l := len(cell.inlines); l > 0 && cell.plain[l-1] {
+		return zsx.GetText(cell.inlines[l-1]), true
+	}
+	return "", false
+}
+
+// finish builds the content of the table node.
+func (tb *tableBuilder) finish() {
+	width := 0
+	for _, row := range tb.rows {
+		width = max(width, len(row))
+	}
+	aligns := make([]sx.Object, width)
+
+	rows := tb.rows
+	var header *sx.Pair
+	if len(rows) > 0 && isHeaderRow(rows[0]) {
+		for i := range rows[0] {
+			cell := &rows[0][i]
+			if s, ok := cell.startText(); ok && strings.HasPrefix(s, "=") {
+				cell.inlines[0] = zsx.MakeText(s[1:])
+			}
+			if s, ok := cell.endText(); ok && s != "" {
+				if align := getAlignment(s[len(s)-1]); align != nil {
+					aligns[i] = align
+					cell.inlines[len(cell.inlines)-1] = zsx.MakeText(s[:len(s)-1])
+				}
+			}
+		}
+		header = buildRow(rows[0], width, aligns)
+		rows = rows[1:]
+	}
+
+	var lb sx.ListBuilder
+	for _, row := range rows {
+		for i := range row {
+			cell := &row[i]
+			if s, ok := cell.startText(); ok && s != "" {
+				if align := getAlignment(s[0]); align != nil {
+					cell.inlines[0] = zsx.MakeText(s[1:])
+					cell.align = align
+				}
+			}
+		}
+		lb.Add(buildRow(row, width, aligns))
+	}
+	tb.node.SetCdr(lb.List().Cons(header).Cons(sx.Nil()))
+}
+
+// isHeaderRow returns true, if at least one cell starts with "=".
+func isHeaderRow(row []tableCell) bool {
+	for i := range row {
+		if s, ok := row[i].startText(); ok && strings.HasPrefix(s, "=") {
+			return true
+		}
+	}
+	return false
+}
+
+// getAlignment returns the alignment value for the given character, or nil.
+func getAlignment(ch byte) sx.Object {
+	switch ch {
+	case '<':
+		return zsx.AttrAlignLeft
+	case ':':
+		return zsx.AttrAlignCenter
+	case '>':
+		return zsx.AttrAlignRight
+	}
+	return nil
+}
+
+// buildRow builds a row node with exactly width cells. Cells without an own
+// alignment use the alignment of their column.
+func buildRow(row []tableCell, width int, aligns []sx.Object) *sx.Pair {
+	var lb sx.ListBuilder
+	for i := range width {
+		var cell tableCell
+		if i < len(row) {
+			cell = row[i]
+		}
+		align := cell.align
+		if align == nil {
+			align = aligns[i]
+		}
+		var attrs *sx.Pair
+		if align != nil {
+			attrs = sx.MakeList(sx.Cons(zsx.SymAttrAlign, align))
+		}
+		lb.Add(zsx.MakeCell(attrs, makeList(cleanLine(cell.inlines))))
+	}
+	return zsx.MakeRow(nil, lb.List())
+}
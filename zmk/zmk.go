@@ -0,0 +1,142 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package zmk provides a parser and an encoder for Zettelmarkup.
+//
+// The parser produces a BLOCK node or an INLINE node, as specified by the
+// builder functions of package zsx. The encoder writes such nodes back as
+// Zettelmarkup.
+package zmk
+
+import (
+	"t73f.de/r/sx"
+	"t73f.de/r/zsx"
+	"t73f.de/r/zsx/input"
+)
+
+// ParseBlocks parses the input as Zettelmarkup and returns a BLOCK node.
+func ParseBlocks(inp *input.Input) *sx.Pair {
+	cp := zmkP{inp: inp}
+	var lb sx.ListBuilder
+	for inp.Ch != input.EOS {
+		cp.parseBlock(&lb)
+	}
+	cp.clearStacked()
+	return zsx.MakeBlockList(lb.List())
+}
+
+// ParseInlines parses the input as a sequence of Zettelmarkup inline
+// elements and returns an INLINE node. No block elements are recognized,
+// which allows to parse fragments, like the title of a zettel.
+func ParseInlines(inp *input.Input) *sx.Pair {
+	cp := zmkP{inp: inp}
+	var ins []*sx.Pair
+	for {
+		in := cp.parseInline()
+		if in == nil {
+			break
+		}
+		ins = append(ins, in)
+	}
+	return zsx.MakeInline(cleanLine(ins)...)
+}
+
+type zmkP struct {
+	inp          *input.Input  // Input stream
+	nestingLevel int           // Count nesting of block and inline elements
+	lists        []*sx.Pair    // Stack of nested lists
+	descrl       *sx.Pair      // Current description list
+	table        *tableBuilder // Current table
+	lastPara     *sx.Pair      // Last paragraph, which may be continued
+	lastBreak    *sx.Pair      // Line break node at the end of lastPara
+	itemPara     *sx.Pair      // Last paragraph of a list item or description
+	itemBreak    *sx.Pair      // Line break node at the end of itemPara
+	escapedText  bool          // Last text node was escaped, e.g. an entity
+}
+
+// maxNestingLevel is the maximum level of nesting of block and inline nodes.
+const maxNestingLevel = 50
+
+// clearStacked removes all multi-line nodes from the parser.
+func (cp *zmkP) clearStacked() {
+	cp.lists = nil
+	cp.descrl = nil
+	cp.closeTable()
+}
+
+// closeTable finishes the current table, if there is one.
+func (cp *zmkP) closeTable() {
+	if tb := cp.table; tb != nil {
+		cp.table = nil
+		tb.finish()
+	}
+}
+
+func (cp *zmkP) skipSpace() { cp.inp.SkipSpace() }
+
+// countDelim read from input until a non-delimiter is found and returns
+// the number of delimiter runes.
+func (cp *zmkP) countDelim(delim rune) int {
+	cnt := 0
+	for cp.inp.Ch == delim {
+		cnt++
+		cp.inp.Next()
+	}
+	return cnt
+}
+
+// isNameRune returns true, if the given rune may be part of a name, like the
+// key of an attribute or the name of a mark.
+func isNameRune(ch rune) bool {
+	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+		ch == '-' || ch == '_' || (ch >= 0x80 && !input.IsSpace(ch))
+}
+
+// lastPair returns the last pair of a non-empty list.
+func lastPair(lst *sx.Pair) *sx.Pair {
+	for {
+		next := lst.Tail()
+		if next == nil {
+			return lst
+		}
+		lst = next
+	}
+}
+
+// appendNodes destructively adds the given nodes at the end of the non-empty
+// list.
+func appendNodes(lst *sx.Pair, nodes ...*sx.Pair) {
+	last := lastPair(lst)
+	for _, node := range nodes {
+		p := sx.Cons(node, sx.Nil())
+		last.SetCdr(p)
+		last = p
+	}
+}
+
+// lastNode returns the last element of a list of nodes.
+func lastNode(lst *sx.Pair) *sx.Pair {
+	if lst == nil {
+		return nil
+	}
+	return lastPair(lst).Head()
+}
+
+// makeList builds a sx list of the given nodes.
+func makeList(nodes []*sx.Pair) *sx.Pair {
+	var lb sx.ListBuilder
+	for _, node := range nodes {
+		lb.Add(node)
+	}
+	return lb.List()
+}
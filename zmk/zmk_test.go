@@ -0,0 +1,159 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of zsx.
+//
+// zsx is licensed under the latest version of the EUPL (European Union Public
+// License). Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package zmk_test
+
+import (
+	"testing"
+
+	"t73f.de/r/zsx/input"
+	"t73f.de/r/zsx/zmk"
+)
+
+type testCase struct {
+	name string
+	src  string
+	exp  string
+}
+
+func checkBlocks(t *testing.T, testcases []testCase) {
+	t.Helper()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := zmk.ParseBlocks(input.NewInput([]byte(tc.src))).String()
+			if got != tc.exp {
+				t.Errorf("\nsrc: %q\nexp: %s\ngot: %s", tc.src, tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestParagraph(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"empty", "", "(BLOCK)"},
+		{"blank", "\n  \n", "(BLOCK)"},
+		{"simple", "Hello world", `(BLOCK (PARA (TEXT "Hello world")))`},
+		{"lines", "a \n  b\\\nc\n\nd",
+			`(BLOCK (PARA (TEXT "a") (SOFT) (TEXT "b") (HARD) (TEXT "c")) (PARA (TEXT "d")))`},
+		{"trailing-break", "a\\\n\nb", `(BLOCK (PARA (TEXT "a")) (PARA (TEXT "b")))`},
+	})
+}
+
+func TestHeading(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"level1", "=== A", `(BLOCK (HEADING () 1 (TEXT "A")))`},
+		{"level5", "========= A", `(BLOCK (HEADING () 5 (TEXT "A")))`},
+		{"no-space", "===A", `(BLOCK (PARA (TEXT "===A")))`},
+		{"too-short", "== A", `(BLOCK (PARA (TEXT "== A")))`},
+		{"attrs", "=== A __b__ {.c}",
+			`(BLOCK (HEADING (("class" . "c")) 1 (TEXT "A ") (FORMAT-EMPH () (TEXT "b"))))`},
+		{"no-attrs", "=== A {b}c", `(BLOCK (HEADING () 1 (TEXT "A {b}c")))`},
+	})
+}
+
+func TestThematic(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"simple", "---", `(BLOCK (THEMATIC ()))`},
+		{"attrs", "----- {.x}", `(BLOCK (THEMATIC (("class" . "x"))))`},
+		{"text", "--- a", `(BLOCK (PARA (TEXT "–- a")))`},
+	})
+}
+
+func TestNestedList(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"unordered", "* a\n* b",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a"))) (ITEM () (PARA (TEXT "b")))))`},
+		{"blank-between", "# a\n\n# b",
+			`(BLOCK (ORDERED () (ITEM () (PARA (TEXT "a"))) (ITEM () (PARA (TEXT "b")))))`},
+		{"nested", "* a\n*# b\n  c\n* d",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a")) (ORDERED () (ITEM () (PARA (TEXT "b")))) (PARA (TEXT "c"))) (ITEM () (PARA (TEXT "d")))))`},
+		{"continuation", "* a\n** b\n    c\n\n    d",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a")) (UNORDERED () (ITEM () (PARA (TEXT "b") (SOFT) (TEXT "c")) (PARA (TEXT "d")))))))`},
+		{"change-kind", "* a\n# b",
+			`(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a")))) (ORDERED () (ITEM () (PARA (TEXT "b")))))`},
+		{"quote", ">\n> b", `(BLOCK (QUOTATION () (ITEM ()) (ITEM () (PARA (TEXT "b")))))`},
+		{"no-list", "*\n**a**", `(BLOCK (PARA (TEXT "*") (SOFT) (FORMAT-STRONG () (TEXT "a"))))`},
+		{"para-after", "* a\n\nb", `(BLOCK (UNORDERED () (ITEM () (PARA (TEXT "a")))) (PARA (TEXT "b")))`},
+	})
+}
+
+func TestDescription(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"simple", "; T\n: D",
+			`(BLOCK (DESCRIPTION () (TERM () (TEXT "T")) (DETAIL (ENTRY () (PARA (TEXT "D"))))))`},
+		{"multiple", "; T1\n: D1\n  more\n: D2\n; T2",
+			`(BLOCK (DESCRIPTION () (TERM () (TEXT "T1")) (DETAIL (ENTRY () (PARA (TEXT "D1") (SOFT) (TEXT "more"))) (ENTRY () (PARA (TEXT "D2")))) (TERM () (TEXT "T2")) (DETAIL)))`},
+		{"no-term", ": D", `(BLOCK (PARA (TEXT ": D")))`},
+	})
+}
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"simple", "|a|b\n|c|",
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a")) (CELL () (TEXT "b"))) (ROW () (CELL () (TEXT "c")) (CELL ()))))`},
+		{"header", "|=A|=B>\n| a |<b",
+			`(BLOCK (TABLE () (ROW () (CELL () (TEXT "A")) (CELL ((align . "right")) (TEXT "B"))) (ROW () (CELL () (TEXT "a")) (CELL ((align . "left")) (TEXT "b")))))`},
+		{"escaped", "|\\=A|\\<b",
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "=A")) (CELL () (TEXT "<b")))))`},
+		{"comment", "|a\n|% comment\n|b",
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a"))) (ROW () (CELL () (TEXT "b")))))`},
+		{"two-tables", "|a\n\n|b",
+			`(BLOCK (TABLE () () (ROW () (CELL () (TEXT "a")))) (TABLE () () (ROW () (CELL () (TEXT "b")))))`},
+		{"link", "|[[a|b]]|c",
+			`(BLOCK (TABLE () () (ROW () (CELL () (LINK () (HOSTED "b") (TEXT "a"))) (CELL () (TEXT "c")))))`},
+	})
+}
+
+func TestVerbatim(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"code", "```go\ncode\n  more\n```", `(BLOCK (VERBATIM-CODE (("" . "go")) "code\n  more"))`},
+		{"longer-fence", "````\n```\n`````", "(BLOCK (VERBATIM-CODE () \"```\"))"},
+		{"unterminated", "```\ncode", `(BLOCK (VERBATIM-CODE () "code"))`},
+		{"comment", "%%%\nc\n%%%", `(BLOCK (VERBATIM-COMMENT () "c"))`},
+		{"eval", "~~~{a=b}\ne\n~~~", `(BLOCK (VERBATIM-EVAL (("a" . "b")) "e"))`},
+		{"math", "$$$\nm\n$$$", `(BLOCK (VERBATIM-MATH () "m"))`},
+		{"zettel", "@@@\nz\n@@@", `(BLOCK (VERBATIM-ZETTEL () "z"))`},
+		{"html", "```html\n<b>\n```", `(BLOCK (VERBATIM-HTML () "<b>"))`},
+		{"no-verbatim", "``` a b", "(BLOCK (PARA (TEXT \"``` a b\")))"},
+	})
+}
+
+func TestRegion(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"block", ":::note\npara\n\n* x\n:::",
+			`(BLOCK (REGION-BLOCK (("" . "note")) ((PARA (TEXT "para")) (UNORDERED () (ITEM () (PARA (TEXT "x")))))))`},
+		{"quote", "<<<\nquote\n<<< Citation",
+			`(BLOCK (REGION-QUOTE () ((PARA (TEXT "quote"))) (TEXT "Citation")))`},
+		{"verse", "\"\"\"\na\nb\n\"\"\"",
+			`(BLOCK (REGION-VERSE () ((PARA (TEXT "a") (HARD) (TEXT "b")))))`},
+		{"nested", "::::\n:::\ninner\n:::\nouter\n::::",
+			`(BLOCK (REGION-BLOCK () ((REGION-BLOCK () ((PARA (TEXT "inner")))) (PARA (TEXT "outer")))))`},
+	})
+}
+
+func TestTransclusion(t *testing.T) {
+	t.Parallel()
+	checkBlocks(t, []testCase{
+		{"simple", "{{{ref}}}", `(BLOCK (TRANSCLUDE () (HOSTED "ref")))`},
+		{"attrs", "{{{https://t73f.de}}} {a=b}", `(BLOCK (TRANSCLUDE (("a" . "b")) (EXTERNAL "https://t73f.de")))`},
+		{"no-transclusion", "{{{ref}}} text", `(BLOCK (PARA (TEXT "{") (EMBED () (HOSTED "ref") "") (TEXT "} text")))`},
+	})
+}